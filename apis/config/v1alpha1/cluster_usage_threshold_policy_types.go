@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+// ClusterUsageThresholdPolicySpec is a description of a ClusterUsageThresholdPolicy.
+type ClusterUsageThresholdPolicySpec struct {
+	// NodeSelector decides whether the thresholds apply to the node if the node matches the selector.
+	// Default to the empty LabelSelector, which matches everything.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// UsageThresholds indicates the resource utilization threshold of the whole machine.
+	// +optional
+	UsageThresholds map[corev1.ResourceName]int64 `json:"usageThresholds,omitempty"`
+
+	// ProdUsageThresholds indicates the resource utilization threshold of Prod Pods compared to the whole machine.
+	// +optional
+	ProdUsageThresholds map[corev1.ResourceName]int64 `json:"prodUsageThresholds,omitempty"`
+
+	// AggregatedUsage indicates the resource utilization threshold of the machine based on percentile statistics.
+	// +optional
+	AggregatedUsage *AggregatedUsageThresholds `json:"aggregatedUsage,omitempty"`
+}
+
+// AggregatedUsageThresholds indicates the resource utilization thresholds based on percentile statistics.
+type AggregatedUsageThresholds struct {
+	// UsageThresholds indicates the resource utilization threshold of the machine based on percentile statistics
+	UsageThresholds map[corev1.ResourceName]int64 `json:"usageThresholds,omitempty"`
+	// UsageAggregationType indicates the percentile type of the machine's utilization when filtering
+	UsageAggregationType slov1alpha1.AggregationType `json:"usageAggregationType,omitempty"`
+	// UsageAggregatedDuration indicates the statistical period of the percentile of the machine's utilization when filtering
+	UsageAggregatedDuration *metav1.Duration `json:"usageAggregatedDuration,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+
+// ClusterUsageThresholdPolicy is the Schema for the ClusterUsageThresholdPolicy API.
+// The load-aware scheduling resolves the usage thresholds of a node in the order of
+// the node annotation, the ClusterUsageThresholdPolicy matching the node and the plugin args.
+// If a node matches multiple policies, the policy with the smallest name applies.
+type ClusterUsageThresholdPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ClusterUsageThresholdPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterUsageThresholdPolicyList contains a list of ClusterUsageThresholdPolicy
+type ClusterUsageThresholdPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterUsageThresholdPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterUsageThresholdPolicy{}, &ClusterUsageThresholdPolicyList{})
+}
@@ -22,10 +22,38 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AggregatedUsageThresholds) DeepCopyInto(out *AggregatedUsageThresholds) {
+	*out = *in
+	if in.UsageThresholds != nil {
+		in, out := &in.UsageThresholds, &out.UsageThresholds
+		*out = make(map[corev1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UsageAggregatedDuration != nil {
+		in, out := &in.UsageAggregatedDuration, &out.UsageAggregatedDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AggregatedUsageThresholds.
+func (in *AggregatedUsageThresholds) DeepCopy() *AggregatedUsageThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(AggregatedUsageThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterColocationProfile) DeepCopyInto(out *ClusterColocationProfile) {
 	*out = *in
@@ -144,3 +172,100 @@ func (in *ClusterColocationProfileStatus) DeepCopy() *ClusterColocationProfileSt
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterUsageThresholdPolicy) DeepCopyInto(out *ClusterUsageThresholdPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUsageThresholdPolicy.
+func (in *ClusterUsageThresholdPolicy) DeepCopy() *ClusterUsageThresholdPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterUsageThresholdPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterUsageThresholdPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterUsageThresholdPolicyList) DeepCopyInto(out *ClusterUsageThresholdPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterUsageThresholdPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUsageThresholdPolicyList.
+func (in *ClusterUsageThresholdPolicyList) DeepCopy() *ClusterUsageThresholdPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterUsageThresholdPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterUsageThresholdPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterUsageThresholdPolicySpec) DeepCopyInto(out *ClusterUsageThresholdPolicySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UsageThresholds != nil {
+		in, out := &in.UsageThresholds, &out.UsageThresholds
+		*out = make(map[corev1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ProdUsageThresholds != nil {
+		in, out := &in.ProdUsageThresholds, &out.ProdUsageThresholds
+		*out = make(map[corev1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AggregatedUsage != nil {
+		in, out := &in.AggregatedUsage, &out.AggregatedUsage
+		*out = new(AggregatedUsageThresholds)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUsageThresholdPolicySpec.
+func (in *ClusterUsageThresholdPolicySpec) DeepCopy() *ClusterUsageThresholdPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterUsageThresholdPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -18,7 +18,10 @@ package extension
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -30,6 +33,14 @@ const (
 	// AnnotationNodeCPUSharedPools describes the CPU Shared Pool defined by Koordinator.
 	// The shared pool is mainly used by Koordinator LS Pods or K8s Burstable Pods.
 	AnnotationNodeCPUSharedPools = NodeDomainPrefix + "/cpu-shared-pools"
+	// AnnotationNodeBatchEvictionCount describes the number of batch pods recently evicted from the node,
+	// e.g. evicted due to the bursts of prod pods. It is reported by the koordlet with the BE pods evicted
+	// by the BECPUEvict and BEMemoryEvict in the last 10 minutes.
+	AnnotationNodeBatchEvictionCount = NodeDomainPrefix + "/batch-eviction-count"
+	// AnnotationNodeBatchAllocatable describes the batch allocatable on the NodeMetric or the node, e.g.
+	// {"kubernetes.io/batch-cpu":"4000","kubernetes.io/batch-memory":"8Gi"}, which is read by the scheduler
+	// on the clusters not reporting the batch allocatable as the extended resources of the node.
+	AnnotationNodeBatchAllocatable = NodeDomainPrefix + "/batch-allocatable"
 
 	// LabelNodeCPUBindPolicy constrains how to bind CPU logical CPUs when scheduling.
 	LabelNodeCPUBindPolicy = NodeDomainPrefix + "/cpu-bind-policy"
@@ -153,3 +164,31 @@ func GetNodeCPUBindPolicy(nodeLabels map[string]string, kubeletCPUPolicy *Kubele
 	}
 	return NodeCPUBindPolicyNone
 }
+
+func GetNodeBatchEvictionCount(annotations map[string]string) (int64, error) {
+	data, ok := annotations[AnnotationNodeBatchEvictionCount]
+	if !ok {
+		return 0, nil
+	}
+	count, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if count < 0 {
+		return 0, fmt.Errorf("invalid batch eviction count %d", count)
+	}
+	return count, nil
+}
+
+// GetNodeBatchAllocatable returns the batch allocatable in the AnnotationNodeBatchAllocatable, nil if not annotated.
+func GetNodeBatchAllocatable(annotations map[string]string) (corev1.ResourceList, error) {
+	data, ok := annotations[AnnotationNodeBatchAllocatable]
+	if !ok {
+		return nil, nil
+	}
+	allocatable := corev1.ResourceList{}
+	if err := json.Unmarshal([]byte(data), &allocatable); err != nil {
+		return nil, err
+	}
+	return allocatable, nil
+}
@@ -34,6 +34,12 @@ const (
 	ResourceGPUCore        corev1.ResourceName = DomainPrefix + "gpu-core"
 	ResourceGPUMemory      corev1.ResourceName = DomainPrefix + "gpu-memory"
 	ResourceGPUMemoryRatio corev1.ResourceName = DomainPrefix + "gpu-memory-ratio"
+
+	// ResourceNetworkRX and ResourceNetworkTX are the received and transmitted bytes per second of the node.
+	// The usages are reported in NodeMetric and the bandwidths are declared in the node allocatable,
+	// so that the load-aware scheduling can filter the nodes by the network utilization.
+	ResourceNetworkRX corev1.ResourceName = DomainPrefix + "network-rx"
+	ResourceNetworkTX corev1.ResourceName = DomainPrefix + "network-tx"
 )
 
 const (
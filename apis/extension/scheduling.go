@@ -18,10 +18,13 @@ package extension
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
@@ -34,6 +37,20 @@ const (
 
 	// AnnotationDeviceAllocated represents the device allocated by the pod
 	AnnotationDeviceAllocated = SchedulingDomainPrefix + "/device-allocated"
+
+	// AnnotationBatchIgnoreInitContainers opts the pod out of the init containers when computing the batch requests,
+	// which suits the pods whose init containers request much more than the long-running containers.
+	// The value is a boolean parsed by strconv.ParseBool, e.g. "true".
+	AnnotationBatchIgnoreInitContainers = SchedulingDomainPrefix + "/batch-ignore-init-containers"
+
+	// AnnotationLoadAwareScoreBreakdown represents the breakdown of the LoadAwareScheduling score of the node
+	// the pod is scheduled to, which is for the offline analysis. For specific value definitions, see LoadAwareScoreBreakdown
+	AnnotationLoadAwareScoreBreakdown = SchedulingDomainPrefix + "/load-aware-score-breakdown"
+
+	// AnnotationSkipLoadAwareScheduling opts the pod out of the load-aware scheduling, so that the nodes are neither
+	// filtered nor scored by the utilization, which suits the latency-critical system components.
+	// The value is a boolean parsed by strconv.ParseBool, e.g. "true".
+	AnnotationSkipLoadAwareScheduling = SchedulingDomainPrefix + "/skip-load-aware-scheduling"
 )
 
 const (
@@ -166,6 +183,76 @@ func SetDeviceAllocations(obj metav1.Object, allocations DeviceAllocations) erro
 	return nil
 }
 
+// LoadAwareScoreBreakdown is the LoadAwareScheduling score of a node and the scores of the weighted resources behind it.
+type LoadAwareScoreBreakdown struct {
+	Score     int64                                          `json:"score"`
+	Resources map[corev1.ResourceName]LoadAwareResourceScore `json:"resources,omitempty"`
+}
+
+// LoadAwareResourceScore is the score of a resource by the estimated used after placing the pod and the allocatable,
+// the CPU is in milli cores and the others are in the units of the resource.
+type LoadAwareResourceScore struct {
+	Used        int64 `json:"used"`
+	Allocatable int64 `json:"allocatable"`
+	Score       int64 `json:"score"`
+}
+
+func GetLoadAwareScoreBreakdown(annotations map[string]string) (*LoadAwareScoreBreakdown, error) {
+	data, ok := annotations[AnnotationLoadAwareScoreBreakdown]
+	if !ok {
+		return nil, nil
+	}
+	breakdown := &LoadAwareScoreBreakdown{}
+	if err := json.Unmarshal([]byte(data), breakdown); err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+func SetLoadAwareScoreBreakdown(obj metav1.Object, breakdown *LoadAwareScoreBreakdown) error {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	data, err := json.Marshal(breakdown)
+	if err != nil {
+		return err
+	}
+
+	annotations[AnnotationLoadAwareScoreBreakdown] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// IsBatchInitContainersIgnored returns whether the pod opts out of the init containers when computing the batch requests,
+// and returns an error if the value of AnnotationBatchIgnoreInitContainers is invalid.
+func IsBatchInitContainersIgnored(pod *corev1.Pod) (bool, error) {
+	value, ok := pod.Annotations[AnnotationBatchIgnoreInitContainers]
+	if !ok {
+		return false, nil
+	}
+	ignored, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q of annotation %s, err: %w", value, AnnotationBatchIgnoreInitContainers, err)
+	}
+	return ignored, nil
+}
+
+// IsLoadAwareSchedulingSkipped returns whether the pod opts out of the load-aware scheduling,
+// and returns an error if the value of AnnotationSkipLoadAwareScheduling is invalid.
+func IsLoadAwareSchedulingSkipped(pod *corev1.Pod) (bool, error) {
+	value, ok := pod.Annotations[AnnotationSkipLoadAwareScheduling]
+	if !ok {
+		return false, nil
+	}
+	skipped, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q of annotation %s, err: %w", value, AnnotationSkipLoadAwareScheduling, err)
+	}
+	return skipped, nil
+}
+
 var GetMinNum = func(pod *corev1.Pod) (int, error) {
 	minRequiredNum, err := strconv.ParseInt(pod.Annotations[AnnotationGangMinNum], 10, 32)
 	if err != nil {
@@ -177,3 +264,19 @@ var GetMinNum = func(pod *corev1.Pod) (int, error) {
 var GetGangName = func(pod *corev1.Pod) string {
 	return pod.Annotations[AnnotationGangName]
 }
+
+// AnnotationSidecarInitContainers declares the names of the init containers of the pod running as the sidecars,
+// i.e. the init containers with the restartPolicy Always since kube 1.28, which is needed because the vendored
+// API drops the restartPolicy of the containers. The value is the comma-separated names, e.g. "istio-proxy,log-agent".
+const AnnotationSidecarInitContainers = SchedulingDomainPrefix + "/sidecar-init-containers"
+
+// GetSidecarInitContainers returns the names of the init containers declared as the sidecars by AnnotationSidecarInitContainers.
+func GetSidecarInitContainers(pod *corev1.Pod) sets.String {
+	sidecars := sets.NewString()
+	for _, name := range strings.Split(pod.Annotations[AnnotationSidecarInitContainers], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sidecars.Insert(name)
+		}
+	}
+	return sidecars
+}
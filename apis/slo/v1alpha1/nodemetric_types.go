@@ -38,11 +38,16 @@ type NodeMetricInfo struct {
 	NodeUsage ResourceMap `json:"nodeUsage,omitempty"`
 	// AggregatedNodeUsages will report only if there are enough samples
 	AggregatedNodeUsages []AggregatedUsage `json:"aggregatedNodeUsages,omitempty"`
+	// SystemUsage is the node usage not accounted to the reported pods, e.g. the kubelet and the container runtime,
+	// which is aggregated in the same duration as NodeUsage.
+	SystemUsage ResourceMap `json:"systemUsage,omitempty"`
 }
 
 type AggregatedUsage struct {
 	Usage    map[AggregationType]ResourceMap `json:"usage,omitempty"`
 	Duration metav1.Duration                 `json:"duration,omitempty"`
+	// SampleCount is the number of metric samples aggregated in the Duration
+	SampleCount int64 `json:"sampleCount,omitempty"`
 }
 
 type PodMetricInfo struct {
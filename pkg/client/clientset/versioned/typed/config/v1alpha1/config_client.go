@@ -27,6 +27,7 @@ import (
 type ConfigV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ClusterColocationProfilesGetter
+	ClusterUsageThresholdPoliciesGetter
 }
 
 // ConfigV1alpha1Client is used to interact with features provided by the config group.
@@ -38,6 +39,10 @@ func (c *ConfigV1alpha1Client) ClusterColocationProfiles() ClusterColocationProf
 	return newClusterColocationProfiles(c)
 }
 
+func (c *ConfigV1alpha1Client) ClusterUsageThresholdPolicies() ClusterUsageThresholdPolicyInterface {
+	return newClusterUsageThresholdPolicies(c)
+}
+
 // NewForConfig creates a new ConfigV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*ConfigV1alpha1Client, error) {
 	config := *c
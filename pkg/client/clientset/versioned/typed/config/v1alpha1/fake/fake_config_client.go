@@ -32,6 +32,10 @@ func (c *FakeConfigV1alpha1) ClusterColocationProfiles() v1alpha1.ClusterColocat
 	return &FakeClusterColocationProfiles{c}
 }
 
+func (c *FakeConfigV1alpha1) ClusterUsageThresholdPolicies() v1alpha1.ClusterUsageThresholdPolicyInterface {
+	return &FakeClusterUsageThresholdPolicies{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeConfigV1alpha1) RESTClient() rest.Interface {
@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// ClusterColocationProfiles returns a ClusterColocationProfileInformer.
 	ClusterColocationProfiles() ClusterColocationProfileInformer
+	// ClusterUsageThresholdPolicies returns a ClusterUsageThresholdPolicyInformer.
+	ClusterUsageThresholdPolicies() ClusterUsageThresholdPolicyInformer
 }
 
 type version struct {
@@ -43,3 +45,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) ClusterColocationProfiles() ClusterColocationProfileInformer {
 	return &clusterColocationProfileInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// ClusterUsageThresholdPolicies returns a ClusterUsageThresholdPolicyInformer.
+func (v *version) ClusterUsageThresholdPolicies() ClusterUsageThresholdPolicyInformer {
+	return &clusterUsageThresholdPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// batchEvictionCountWindow is how long an evicted BE pod is counted in the batch eviction count of the node.
+const batchEvictionCountWindow = 10 * time.Minute
+
+// batchEvictionCounter counts the BE pods evicted from the node in the last batchEvictionCountWindow.
+// The zero value is ready to use.
+type batchEvictionCounter struct {
+	lock       sync.Mutex
+	evictTimes []time.Time
+}
+
+func (c *batchEvictionCounter) add(evictTime time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.evictTimes = append(c.evictTimes, evictTime)
+}
+
+// count drops the evictions out of the window and returns the number of the rest.
+func (c *batchEvictionCounter) count(now time.Time) int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	expired := 0
+	for expired < len(c.evictTimes) && now.Sub(c.evictTimes[expired]) > batchEvictionCountWindow {
+		expired++
+	}
+	c.evictTimes = c.evictTimes[expired:]
+	return int64(len(c.evictTimes))
+}
+
+// reportBatchEvictionCount patches the number of the BE pods recently evicted by the CPU and memory evictions
+// to the node annotation, which is read by the BatchResourceFit plugin of koord-scheduler to score the node lower.
+func (r *resmanager) reportBatchEvictionCount() {
+	node := r.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("reportBatchEvictionCount failed, got nil node")
+		return
+	}
+	count := strconv.FormatInt(r.batchEvictions.count(time.Now()), 10)
+	current, ok := node.Annotations[apiext.AnnotationNodeBatchEvictionCount]
+	if current == count || (!ok && count == "0") {
+		return
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				apiext.AnnotationNodeBatchEvictionCount: count,
+			},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		klog.Errorf("reportBatchEvictionCount failed to marshal patch, err: %v", err)
+		return
+	}
+	if _, err := r.kubeClient.CoreV1().Nodes().Patch(context.TODO(), node.Name, types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("reportBatchEvictionCount failed to patch node %s, err: %v", node.Name, err)
+		return
+	}
+	klog.V(4).Infof("report batch eviction count %s of node %s", count, node.Name)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+func Test_batchEvictionCounter(t *testing.T) {
+	now := time.Now()
+	c := &batchEvictionCounter{}
+	assert.Equal(t, int64(0), c.count(now))
+
+	c.add(now.Add(-batchEvictionCountWindow - time.Second))
+	c.add(now.Add(-time.Minute))
+	c.add(now)
+	assert.Equal(t, int64(2), c.count(now))
+	assert.Equal(t, int64(1), c.count(now.Add(batchEvictionCountWindow-30*time.Second)))
+	assert.Equal(t, int64(0), c.count(now.Add(2*batchEvictionCountWindow)))
+}
+
+func Test_reportBatchEvictionCount(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+	}
+	client := clientsetfake.NewSimpleClientset(node)
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	r := &resmanager{statesInformer: statesInformer, kubeClient: client}
+
+	// no annotation is reported before any eviction.
+	statesInformer.EXPECT().GetNode().Return(node).Times(1)
+	r.reportBatchEvictionCount()
+	got, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotContains(t, got.Annotations, apiext.AnnotationNodeBatchEvictionCount)
+
+	r.batchEvictions.add(time.Now())
+	r.batchEvictions.add(time.Now())
+	statesInformer.EXPECT().GetNode().Return(node).Times(1)
+	r.reportBatchEvictionCount()
+	got, err = client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", got.Annotations[apiext.AnnotationNodeBatchEvictionCount])
+	count, err := apiext.GetNodeBatchEvictionCount(got.Annotations)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// the count drops to 0 after the evictions are out of the window.
+	r.batchEvictions.evictTimes = []time.Time{time.Now().Add(-2 * batchEvictionCountWindow)}
+	statesInformer.EXPECT().GetNode().Return(got).Times(1)
+	r.reportBatchEvictionCount()
+	got, err = client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "0", got.Annotations[apiext.AnnotationNodeBatchEvictionCount])
+}
@@ -19,6 +19,7 @@ package resmanager
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -67,6 +68,8 @@ type resmanager struct {
 	kubeClient                    clientset.Interface
 	eventRecorder                 record.EventRecorder
 	evictVersion                  string
+	// batchEvictions counts the BE pods recently evicted, which is reported in the node annotation.
+	batchEvictions batchEvictionCounter
 }
 
 func (r *resmanager) getNodeSLOCopy() *slov1alpha1.NodeSLO {
@@ -155,6 +158,8 @@ func (r *resmanager) Run(stopCh <-chan struct{}) error {
 	memoryEvictor := NewMemoryEvictor(r)
 	util.RunFeature(memoryEvictor.memoryEvict, []featuregate.Feature{features.BEMemoryEvict}, r.config.MemoryEvictIntervalSeconds, stopCh)
 
+	util.RunFeature(r.reportBatchEvictionCount, []featuregate.Feature{features.BECPUEvict, features.BEMemoryEvict}, r.config.ReconcileIntervalSeconds, stopCh)
+
 	rdtResCtrl := NewResctrlReconcile(r)
 	util.RunFeatureWithInit(func() error { return rdtResCtrl.RunInit(stopCh) }, rdtResCtrl.reconcile,
 		[]featuregate.Feature{features.RdtResctrl}, r.config.ReconcileIntervalSeconds, stopCh)
@@ -187,6 +192,7 @@ func (r *resmanager) evictPodIfNotEvicted(evictPod *corev1.Pod, node *corev1.Nod
 	success := r.evictPod(evictPod, node, reason, message)
 	if success {
 		_ = r.podsEvicted.SetDefault(string(evictPod.UID), evictPod.UID)
+		r.batchEvictions.add(time.Now())
 	}
 }
 
@@ -19,6 +19,7 @@ package resmanager
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -174,6 +175,7 @@ func Test_EvictPodsIfNotEvicted(t *testing.T) {
 
 	_, found := r.podsEvicted.Get(string(pod.UID))
 	assert.True(t, found, "check PodEvicted cached")
+	assert.Equal(t, int64(1), r.batchEvictions.count(time.Now()), "check batch eviction counted")
 
 	// evict duplication
 	fakeRecorder.eventReason = ""
@@ -317,8 +317,9 @@ func (r *nodeMetricInformer) collectMetric() (*slov1alpha1.NodeMetricInfo, []*sl
 	endTime := time.Now()
 	startTime := endTime.Add(-time.Duration(*spec.CollectPolicy.AggregateDurationSeconds) * time.Second)
 
+	nodeUsage, _ := r.queryNodeMetric(startTime, endTime, metriccache.AggregationTypeAVG, false)
 	nodeMetricInfo := &slov1alpha1.NodeMetricInfo{
-		NodeUsage:            r.queryNodeMetric(startTime, endTime, metriccache.AggregationTypeAVG, false),
+		NodeUsage:            nodeUsage,
 		AggregatedNodeUsages: r.collectNodeAggregateMetric(endTime, spec.CollectPolicy.NodeAggregatePolicy),
 	}
 
@@ -336,12 +337,37 @@ func (r *nodeMetricInformer) collectMetric() (*slov1alpha1.NodeMetricInfo, []*sl
 			podsMetricInfo = append(podsMetricInfo, podMetric)
 		}
 	}
+	nodeMetricInfo.SystemUsage = computeSystemUsage(nodeUsage, podsMetricInfo)
 
 	return nodeMetricInfo, podsMetricInfo
 }
 
+// computeSystemUsage returns the node usage not accounted to the pods, which are aggregated in the same duration,
+// so that the usage summed from the pods can be completed by it. The usage below zero is reported as zero.
+func computeSystemUsage(nodeUsage slov1alpha1.ResourceMap, podsMetric []*slov1alpha1.PodMetricInfo) slov1alpha1.ResourceMap {
+	systemUsage := corev1.ResourceList{}
+	for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		nodeUsed, ok := nodeUsage.ResourceList[resourceName]
+		if !ok {
+			continue
+		}
+		used := nodeUsed.DeepCopy()
+		for _, podMetric := range podsMetric {
+			if podUsed, ok := podMetric.PodUsage.ResourceList[resourceName]; ok {
+				used.Sub(podUsed)
+			}
+		}
+		if used.Sign() < 0 {
+			used.Set(0)
+		}
+		systemUsage[resourceName] = used
+	}
+	return slov1alpha1.ResourceMap{ResourceList: systemUsage}
+}
+
+// queryNodeMetric returns the node usage aggregated in the time range and the number of samples aggregated.
 func (r *nodeMetricInformer) queryNodeMetric(start time.Time, end time.Time, aggregateType metriccache.AggregationType,
-	coldStartFilter bool) slov1alpha1.ResourceMap {
+	coldStartFilter bool) (slov1alpha1.ResourceMap, int64) {
 	queryParam := &metriccache.QueryParam{
 		Aggregate: aggregateType,
 		Start:     &start,
@@ -350,20 +376,24 @@ func (r *nodeMetricInformer) queryNodeMetric(start time.Time, end time.Time, agg
 	queryResult := r.metricCache.GetNodeResourceMetric(queryParam)
 	if queryResult.Error != nil {
 		klog.Warningf("get node resource metric failed, error %v", queryResult.Error)
-		return slov1alpha1.ResourceMap{}
+		return slov1alpha1.ResourceMap{}, 0
 	}
 	if queryResult.Metric == nil {
 		klog.Warningf("node metric not exist")
-		return slov1alpha1.ResourceMap{}
+		return slov1alpha1.ResourceMap{}, 0
 	}
 
+	var sampleCount int64
+	if queryResult.AggregateInfo != nil {
+		sampleCount = queryResult.AggregateInfo.MetricsCount
+	}
 	if coldStartFilter && metricsInColdStart(start, end, &queryResult.QueryResult) {
 		klog.V(4).Infof("metrics is in cold start, no need to report, current result sample duration %v",
 			queryResult.AggregateInfo.TimeRangeDuration().String())
-		return slov1alpha1.ResourceMap{}
+		return slov1alpha1.ResourceMap{}, sampleCount
 	}
 
-	return convertNodeMetricToResourceMap(queryResult.Metric)
+	return convertNodeMetricToResourceMap(queryResult.Metric), sampleCount
 }
 
 func metricsInColdStart(queryStart, queryEnd time.Time, queryResult *metriccache.QueryResult) bool {
@@ -382,14 +412,20 @@ func (r *nodeMetricInformer) collectNodeAggregateMetric(endTime time.Time, aggre
 	}
 	for _, d := range aggregatePolicy.Durations {
 		start := endTime.Add(-d.Duration)
+		// all percentiles are aggregated from the same samples in the time range
+		p50, sampleCount := r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP50, true)
+		p90, _ := r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP90, true)
+		p95, _ := r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP95, true)
+		p99, _ := r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP99, true)
 		aggregateUsage := slov1alpha1.AggregatedUsage{
 			Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
-				slov1alpha1.P50: r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP50, true),
-				slov1alpha1.P90: r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP90, true),
-				slov1alpha1.P95: r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP95, true),
-				slov1alpha1.P99: r.queryNodeMetric(start, endTime, metriccache.AggregationTypeP99, true),
+				slov1alpha1.P50: p50,
+				slov1alpha1.P90: p90,
+				slov1alpha1.P95: p95,
+				slov1alpha1.P99: p99,
 			},
-			Duration: d,
+			Duration:    d,
+			SampleCount: sampleCount,
 		}
 		aggregateUsages = append(aggregateUsages, aggregateUsage)
 	}
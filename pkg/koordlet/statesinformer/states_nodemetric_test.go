@@ -487,9 +487,13 @@ func Test_nodeMetricInformer_queryNodeMetric(t *testing.T) {
 				metricCache: c,
 			}
 			want := convertNodeMetricToResourceMap(tt.fields.nodeResult.Metric)
-			if got := r.queryNodeMetric(start, end, tt.args.aggregateType, false); !reflect.DeepEqual(got, want) {
+			got, gotSampleCount := r.queryNodeMetric(start, end, tt.args.aggregateType, false)
+			if !reflect.DeepEqual(got, want) {
 				t.Errorf("queryNodeMetric() = %v, want %v", got, want)
 			}
+			if wantSampleCount := tt.fields.nodeResult.AggregateInfo.MetricsCount; gotSampleCount != wantSampleCount {
+				t.Errorf("queryNodeMetric() sample count = %v, want %v", gotSampleCount, wantSampleCount)
+			}
 		})
 	}
 }
@@ -658,6 +662,7 @@ func Test_nodeMetricInformer_collectNodeMetric(t *testing.T) {
 						Duration: metav1.Duration{
 							Duration: end.Sub(start),
 						},
+						SampleCount: tt.fields.nodeResultP50.AggregateInfo.MetricsCount,
 					},
 				},
 			}
@@ -821,3 +826,52 @@ func Test_metricsInColdStart(t *testing.T) {
 		})
 	}
 }
+
+func Test_computeSystemUsage(t *testing.T) {
+	newResourceMap := func(cpu, memory string) slov1alpha1.ResourceMap {
+		return slov1alpha1.ResourceMap{
+			ResourceList: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		}
+	}
+	tests := []struct {
+		name       string
+		nodeUsage  slov1alpha1.ResourceMap
+		podsMetric []*slov1alpha1.PodMetricInfo
+		want       slov1alpha1.ResourceMap
+	}{
+		{
+			name:      "node usage not accounted to the pods",
+			nodeUsage: newResourceMap("10", "20Gi"),
+			podsMetric: []*slov1alpha1.PodMetricInfo{
+				{Name: "pod-1", PodUsage: newResourceMap("4", "8Gi")},
+				{Name: "pod-2", PodUsage: newResourceMap("3", "6Gi")},
+			},
+			want: newResourceMap("3", "6Gi"),
+		},
+		{
+			name:      "all the node usage without pods",
+			nodeUsage: newResourceMap("10", "20Gi"),
+			want:      newResourceMap("10", "20Gi"),
+		},
+		{
+			name:      "pods using more than the node are reported as zero",
+			nodeUsage: newResourceMap("2", "4Gi"),
+			podsMetric: []*slov1alpha1.PodMetricInfo{
+				{Name: "pod-1", PodUsage: newResourceMap("3", "6Gi")},
+			},
+			want: newResourceMap("0", "0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeSystemUsage(tt.nodeUsage, tt.podsMetric)
+			for resourceName, want := range tt.want.ResourceList {
+				used := got.ResourceList[resourceName]
+				assert.Equal(t, 0, want.Cmp(used), "resource %s, want %s, got %s", resourceName, want.String(), used.String())
+			}
+		})
+	}
+}
@@ -37,24 +37,354 @@ type LoadAwareSchedulingArgs struct {
 	// When NodeMetrics expired, the node is considered abnormal.
 	// Default is 180 seconds.
 	NodeMetricExpirationSeconds *int64 `json:"nodeMetricExpirationSeconds,omitempty"`
+	// DefaultNodeMetricReportIntervalSeconds indicates the report interval in seconds of the NodeMetric
+	// whose CollectPolicy or ReportIntervalSeconds is not set, e.g. reported by the custom collectors.
+	// Default is 60 seconds.
+	DefaultNodeMetricReportIntervalSeconds *int64 `json:"defaultNodeMetricReportIntervalSeconds,omitempty"`
+	// NodeMetricTimeout indicates the timeout of getting the NodeMetric of a node in Score, and the node scores 0
+	// if exceeded, e.g. the cache of the metric source stalls under lock contention. The NodeMetric is read from
+	// the cache in place, so the node is skipped after the reading rather than interrupted. Not enabled by default.
+	NodeMetricTimeout metav1.Duration `json:"nodeMetricTimeout,omitempty"`
 	// ResourceWeights indicates the weights of resources.
 	// The weights of CPU and Memory are both 1 by default.
 	ResourceWeights map[corev1.ResourceName]int64 `json:"resourceWeights,omitempty"`
+	// DefaultResourceWeight indicates the weight of the resources requested by the Pod but missing in ResourceWeights,
+	// which are scored by the requests of the Pod and the Pods assigned recently, so that the Pod requesting
+	// an unweighted resource does not land on the node saturated in the resource. Not enabled by default.
+	DefaultResourceWeight int64 `json:"defaultResourceWeight,omitempty"`
+	// ScoreNonPoolableResources indicates whether to score the non-poolable resources in ResourceWeights, e.g. the hugepages,
+	// whose usage is not comparable to the allocatable like the poolable ones. They are ignored in scoring by default.
+	ScoreNonPoolableResources bool `json:"scoreNonPoolableResources,omitempty"`
+	// PriorityClassResourceWeights indicates the weights of resources for the Pods of each priority class,
+	// which take precedence over ResourceWeights. If set, the Pods of the priority classes missing in it
+	// are not scored, e.g. the Mid Pods in the cluster only configured for the Prod Pods. Not enabled by default.
+	PriorityClassResourceWeights map[extension.PriorityClass]map[corev1.ResourceName]int64 `json:"priorityClassResourceWeights,omitempty"`
+	// DynamicResourceWeightMaxBoostPercent indicates the maximum percentage the weights of the resources are boosted by
+	// in scoring according to their cluster-wide utilization, which is derived from the NodeMetrics every minute. The weight
+	// of a resource is boosted in proportion to how far its utilization is below the most utilized resource, e.g. memory
+	// is weighted more in the CPU-bound cluster to pack the memory-heavy Pods onto the memory-rich nodes.
+	// Not enabled by default.
+	DynamicResourceWeightMaxBoostPercent int64 `json:"dynamicResourceWeightMaxBoostPercent,omitempty"`
 	// UsageThresholds indicates the resource utilization threshold of the whole machine.
 	// The default for CPU is 65%, and the default for memory is 95%.
 	UsageThresholds map[corev1.ResourceName]int64 `json:"usageThresholds,omitempty"`
 	// ProdUsageThresholds indicates the resource utilization threshold of Prod Pods compared to the whole machine.
 	// Not enabled by default
 	ProdUsageThresholds map[corev1.ResourceName]int64 `json:"prodUsageThresholds,omitempty"`
+	// MandatoryThresholds indicates the resource utilization thresholds of the whole machine that are always
+	// enforced, even if UsageThresholds is empty. The stricter one is used if both thresholds are set.
+	// Not enabled by default
+	MandatoryThresholds map[corev1.ResourceName]int64 `json:"mandatoryThresholds,omitempty"`
+	// CombinedThreshold indicates the threshold of the weighted average utilization of the resources in ResourceWeights.
+	// It rejects the stressed nodes whose resources are all under their own thresholds. Not enabled by default
+	CombinedThreshold int64 `json:"combinedThreshold,omitempty"`
+	// GPUUsageThreshold indicates the threshold of the average utilization of the GPUs reported in NodeMetric,
+	// which rejects the nodes with saturated GPUs for the Pods requesting GPUs even if the CPU and memory are free.
+	// The nodes without the GPU utilization reported are not filtered. Not enabled by default
+	GPUUsageThreshold int64 `json:"gpuUsageThreshold,omitempty"`
 	// ScoreAccordingProdUsage controls whether to score according to the utilization of Prod Pod
 	ScoreAccordingProdUsage bool `json:"scoreAccordingProdUsage,omitempty"`
+	// ScoreAccordingNodeReservation controls whether to score with the node allocatable that subtracts
+	// the resources reserved by the node reservation annotation. Not enabled by default.
+	ScoreAccordingNodeReservation bool `json:"scoreAccordingNodeReservation,omitempty"`
+	// ScoreAccordingAvailable controls whether to score with the available resources of the node, which is
+	// the allocatable minus the resources requested by the Pods and the Reservations committed to the node,
+	// rather than the allocatable. Not enabled by default.
+	ScoreAccordingAvailable bool `json:"scoreAccordingAvailable,omitempty"`
+	// ScoreBatchPodByBatchResources controls whether to score the Batch and Free Pods with the allocatable and
+	// the node usage of the batch resources translated from the weighted resources, e.g. kubernetes.io/batch-cpu
+	// for cpu, falling back to the node usage of the weighted resources if the batch ones are not reported.
+	// Not enabled by default.
+	ScoreBatchPodByBatchResources bool `json:"scoreBatchPodByBatchResources,omitempty"`
+	// ScoreAccordingPriorityClassAllocatable controls whether to score the Pods with the allocatable pool of their
+	// priority class advertised by the node, e.g. kubernetes.io/batch-cpu for the Batch Pods, rather than the allocatable
+	// of the weighted resources. The weighted resources whose pools are not advertised keep their allocatable.
+	// Not enabled by default.
+	ScoreAccordingPriorityClassAllocatable bool `json:"scoreAccordingPriorityClassAllocatable,omitempty"`
 	// Estimator indicates the expected Estimator to use
 	Estimator string `json:"estimator,omitempty"`
 	// EstimatedScalingFactors indicates the factor when estimating resource usage.
 	// The default value of CPU is 85%, and the default value of Memory is 70%.
 	EstimatedScalingFactors map[corev1.ResourceName]int64 `json:"estimatedScalingFactors,omitempty"`
+	// QoSEstimatedScalingFactors indicates the factors when estimating resource usage of the Pods by their QoS class,
+	// e.g. the Burstable Pods usually use far less than the requests while the Guaranteed Pods use close to the requests.
+	// The factors of a QoS class override the EstimatedScalingFactors of the same resources. Not enabled by default.
+	QoSEstimatedScalingFactors map[corev1.PodQOSClass]map[corev1.ResourceName]int64 `json:"qosEstimatedScalingFactors,omitempty"`
 	// Aggregated supports resource utilization filtering and scoring based on percentile statistics
 	Aggregated *LoadAwareSchedulingAggregatedArgs `json:"aggregated,omitempty"`
+	// FilterUsageSource indicates where the node usage is read from when filtering.
+	// NodeUsage reads NodeMetric.Status.NodeMetric.NodeUsage, PodsUsage sums the reported usages of the pods still
+	// existing and the reported SystemUsage, so that the pods deleted since the report are not counted, and falls back
+	// to NodeUsage if NodeMetric has no pods metric or no system usage. Default is NodeUsage.
+	FilterUsageSource NodeUsageSource `json:"filterUsageSource,omitempty"`
+	// FilterUsageType indicates the usage that the Prod Pods are filtered by. Prod filters them by the usage of
+	// the Prod Pods against ProdUsageThresholds if set, and Total filters them by the node usage against UsageThresholds.
+	// Default is Prod.
+	FilterUsageType LoadAwareUsageType `json:"filterUsageType,omitempty"`
+	// ScoreUsageType indicates the usage that the Prod Pods are scored by, e.g. Total balances the overall load
+	// while FilterUsageType Prod protects the SLO of the Prod Pods. It overrides ScoreAccordingProdUsage if set.
+	// Not enabled by default.
+	ScoreUsageType LoadAwareUsageType `json:"scoreUsageType,omitempty"`
+	// ScoreResourceBase indicates the resources of the node that the usage is scored against. Allocatable scores
+	// the usage with the system usage excluded against the allocatable, and Capacity scores the node usage with
+	// the system usage counted against the capacity. Default scores the node usage against the allocatable.
+	ScoreResourceBase LoadAwareResourceBase `json:"scoreResourceBase,omitempty"`
+	// PreferredNodeAffinityWeight indicates the maximum bonus added to the score of nodes
+	// that match the preferred node affinity terms of the Pod. Not enabled by default.
+	PreferredNodeAffinityWeight int64 `json:"preferredNodeAffinityWeight,omitempty"`
+	// SiblingPodPenaltyWeight indicates the score deducted for each Pod of the same controller as the Pod
+	// that is assigned to the node but not reported in NodeMetric yet, which spreads the replicas scheduled
+	// in a short time. Not enabled by default.
+	SiblingPodPenaltyWeight int64 `json:"siblingPodPenaltyWeight,omitempty"`
+	// SameWorkloadAffinityWeight indicates the maximum bonus added to the score of nodes already hosting Pods
+	// of the same controller as the Pod, which packs the replicas together. The bonus decreases as the estimated
+	// utilization approaches the usage thresholds, and it is mutually exclusive with SiblingPodPenaltyWeight.
+	// Not enabled by default.
+	SameWorkloadAffinityWeight int64 `json:"sameWorkloadAffinityWeight,omitempty"`
+	// RequestsUsageGapWeight indicates the maximum bonus added to the score of nodes for the Batch and Free Pods
+	// according to the gap between the requested and the used of the node, the nodes hosting over-requesting
+	// but idle Pods have more headroom to be reclaimed. Not enabled by default.
+	RequestsUsageGapWeight int64 `json:"requestsUsageGapWeight,omitempty"`
+	// CompressibilityWeight indicates the maximum bonus added to the score of nodes according to the CPU
+	// compressibility of the Pods on the node, which is the average of 1 - requests/limits of the Pods. The nodes
+	// hosting the Burstable Pods with high limits-to-requests ratios have elastic headroom because the Pods can be
+	// throttled down to their requests, while the Guaranteed Pods are not compressible. Not enabled by default.
+	CompressibilityWeight int64 `json:"compressibilityWeight,omitempty"`
+	// UsageTrendWeight indicates the maximum score added to or deducted from the nodes according to the trend
+	// of the usage, which is the change from the average usage of the longest aggregated duration in NodeMetric
+	// to the latest usage. The nodes whose usage is decreasing are rewarded and the increasing ones are penalized.
+	// Not enabled by default.
+	UsageTrendWeight int64 `json:"usageTrendWeight,omitempty"`
+	// ThresholdProximityPenalty indicates the maximum score deducted from the nodes in proportion to
+	// how close the estimated utilization is to the usage thresholds, so that the nodes passing Filter
+	// by a narrow margin are deprioritized. Not enabled by default.
+	ThresholdProximityPenalty int64 `json:"thresholdProximityPenalty,omitempty"`
+	// ScoreScalingPercentage scales the final score to the percentage, which bounds the score contribution
+	// to a fixed fraction of MaxNodeScore relative to the other score plugins. Not enabled by default.
+	ScoreScalingPercentage int64 `json:"scoreScalingPercentage,omitempty"`
+	// ScoreSafetyBufferPercent indicates the percentage added to the estimated usage of the Pod in Score,
+	// so that the nodes where the Pod fits tightly are less preferred to leave headroom. Not enabled by default.
+	ScoreSafetyBufferPercent int64 `json:"scoreSafetyBufferPercent,omitempty"`
+	// NodePoolLabelKey indicates the label key of the node pools. If set, the scores of the nodes in the pool
+	// targeted by the Pod's nodeSelector or label with the key are normalized within the pool and the other
+	// nodes score 0, so that a busy pool does not push the Pod into an unrelated pool. Not enabled by default.
+	NodePoolLabelKey string `json:"nodePoolLabelKey,omitempty"`
+	// NodeMetricShardLabelKey indicates the label key whose value is the node name on the NodeMetrics sharded
+	// per node, e.g. one NodeMetric per collector. If set, the NodeMetrics labeled with the node name are merged
+	// into one view of the node instead of reading the NodeMetric named after the node. Not enabled by default.
+	NodeMetricShardLabelKey string `json:"nodeMetricShardLabelKey,omitempty"`
+	// ExemptNodeLabelKey indicates the label key of the nodes exempt from the load-aware scheduling, e.g. the dedicated
+	// build nodes. The nodes labeled with the key and the value "true" always pass Filter and score MaxNodeScore / 2
+	// without reading NodeMetric, which is neutral among the nodes. Not enabled by default.
+	ExemptNodeLabelKey string `json:"exemptNodeLabelKey,omitempty"`
+	// FallbackToMetricsServer indicates whether to take the node usage reported by metrics-server as the NodeMetric
+	// of the nodes without NodeMetric, e.g. the nodes without koordlet, instead of skipping them. Not enabled by default.
+	FallbackToMetricsServer bool `json:"fallbackToMetricsServer,omitempty"`
+	// FreeCoresScoreWeight indicates the percentage of the CPU score given by the absolute free cores after placing
+	// the Pod rather than the utilization, because the same utilization means more headroom on the larger nodes,
+	// which matters to the NUMA-sensitive workloads. Not enabled by default.
+	FreeCoresScoreWeight int64 `json:"freeCoresScoreWeight,omitempty"`
+	// MaxScoreFreeCores indicates the number of free cores scoring MaxNodeScore in the free cores score,
+	// and the nodes with less free cores score in proportion. It is required if FreeCoresScoreWeight is set.
+	MaxScoreFreeCores int64 `json:"maxScoreFreeCores,omitempty"`
+	// PodDensityWeight indicates the percentage of the score given by the pod density, which is the number of
+	// the Pods on the node after placing the Pod relative to the allowed number of the Pods, rather than the
+	// utilization, because many small Pods add the kubelet and cgroup overhead and the noisy-neighbor risk.
+	// Not enabled by default.
+	PodDensityWeight int64 `json:"podDensityWeight,omitempty"`
+	// ScoringStrategy indicates how the nodes are scored by the estimated utilization after placing the Pod.
+	// LeastUsage prefers the least utilized nodes, BestFit prefers the nodes that the Pod fits most tightly,
+	// and ProportionalHeadroom prefers the nodes where the Pod takes the least proportion of the headroom.
+	// Default is LeastUsage.
+	ScoringStrategy LoadAwareScoringStrategy `json:"scoringStrategy,omitempty"`
+	// EstimatedOverflowPolicy indicates how a resource is scored if the estimated used after placing the Pod
+	// exceeds the allocatable, e.g. during a burst. ScoreZero scores the resource 0 as least preferred, and
+	// ClampToAllocatable clamps the estimated used to the allocatable and scores the resource as fully used,
+	// which is the lowest nonzero score. Default is ScoreZero.
+	EstimatedOverflowPolicy LoadAwareEstimatedOverflowPolicy `json:"estimatedOverflowPolicy,omitempty"`
+	// CriticalResources indicates the resources in ResourceWeights that force the score of the node to 0
+	// if any of them scores 0, e.g. the resource is used up. Not enabled by default.
+	CriticalResources []corev1.ResourceName `json:"criticalResources,omitempty"`
+	// MemoryCacheDiscountRatio indicates the percentage of the reported node memory usage that is discounted
+	// as reclaimable page cache before comparing with the thresholds and scoring, because NodeMetric
+	// does not report the page cache separately. Default is 0.
+	MemoryCacheDiscountRatio int64 `json:"memoryCacheDiscountRatio,omitempty"`
+	// MemoryPressureKnee indicates the percentage of the reported node memory utilization above which the weight of
+	// memory increases linearly up to 10 times at full utilization, because the memory pressure risks the OOM kills
+	// rather than the throttling, so that the nodes near OOM are strongly avoided. Not enabled by default.
+	MemoryPressureKnee int64 `json:"memoryPressureKnee,omitempty"`
+	// ScoreTopKNodes indicates the number of feasible nodes with the least requested utilization that are fully scored.
+	// The other nodes score MaxNodeScore / 2 without reading NodeMetric, which reduces the scoring latency in large clusters.
+	// Default is 0, which scores all feasible nodes.
+	ScoreTopKNodes int64 `json:"scoreTopKNodes,omitempty"`
+	// ScoreSampleNodes indicates the number of feasible nodes sampled at random that are fully scored. The other nodes
+	// score MaxNodeScore / 2 without reading NodeMetric, which bounds the scoring latency without ranking the nodes like
+	// ScoreTopKNodes. The sample is seeded by the UID of the Pod to be reproducible, and it is mutually exclusive with
+	// ScoreTopKNodes. Default is 0, which scores all feasible nodes.
+	ScoreSampleNodes int64 `json:"scoreSampleNodes,omitempty"`
+	// EstimateWithoutInitContainers indicates whether to estimate the steady-state usage of the Pod by the containers only,
+	// ignoring the requests of the init containers that only run transiently before the containers start.
+	// Not enabled by default.
+	EstimateWithoutInitContainers bool `json:"estimateWithoutInitContainers,omitempty"`
+	// EstimateMode indicates how the Pod usage is estimated from the requests and limits. Default estimates
+	// by the limit if it is larger than the request, otherwise by the request scaled by the EstimatedScalingFactors,
+	// and UseLimits estimates by the limit, falling back to the request if no limit is declared, scaled by the
+	// EstimatedScalingFactors. Default is Default.
+	EstimateMode LoadAwareEstimateMode `json:"estimateMode,omitempty"`
+	// RespectZeroRequests makes the Pods requesting zero of a resource explicitly be estimated to use none of it,
+	// and the default requests, e.g. DefaultMilliCPURequest, only apply to the resources the Pods do not request.
+	// Not enabled by default.
+	RespectZeroRequests bool `json:"respectZeroRequests,omitempty"`
+	// SidecarOverheads indicates the usages of the sidecars injected into the Pods after the admission, e.g. by
+	// the service mesh webhooks, which are added to the estimate of the Pods in the namespaces selected by them.
+	// The Pods are estimated by their specs otherwise, which don't include the sidecars at scheduling time.
+	// Not enabled by default.
+	SidecarOverheads []LoadAwareSidecarOverhead `json:"sidecarOverheads,omitempty"`
+	// AdvisoryOnly makes the plugin only score the nodes by utilization and never reject them in Filter,
+	// which is safer for the initial rollout. Not enabled by default.
+	AdvisoryOnly bool `json:"advisoryOnly,omitempty"`
+	// SoftThreshold makes Filter admit the nodes exceeding the usage thresholds and Score them 0 instead,
+	// so that the overloaded nodes are the last resort rather than unschedulable. Unlike AdvisoryOnly,
+	// the thresholds are still checked. Not enabled by default.
+	SoftThreshold bool `json:"softThreshold,omitempty"`
+	// PreferNominatedNode makes the Pod nominated by the preemption score the nominated node MaxNodeScore
+	// and the other nodes 0 without reading NodeMetric, so that the load-aware scoring does not send
+	// the Pod elsewhere and waste the preemption. Not enabled by default.
+	PreferNominatedNode bool `json:"preferNominatedNode,omitempty"`
+	// RequireNodeMetric makes Filter reject the nodes without NodeMetric instead of admitting them,
+	// which suits the clusters running koordlet on every node. Not enabled by default.
+	RequireNodeMetric bool `json:"requireNodeMetric,omitempty"`
+	// ReserveCheckThresholds makes Reserve reject the node if the reported usage plus the estimated usage of
+	// the Pods assigned but not reported yet, including the Pod, exceeds the usage thresholds, so that the Pod
+	// is rescheduled when the node is filled by a race after Filter. Not enabled by default.
+	ReserveCheckThresholds bool `json:"reserveCheckThresholds,omitempty"`
+	// ScaleEstimateByReportOverlap counts the estimate of a Pod assigned within the report interval before
+	// the NodeMetric update in proportion to the part of the interval not covered by the reported usage of
+	// the Pod, rather than fully, which reduces the overshoot. Not enabled by default.
+	ScaleEstimateByReportOverlap bool `json:"scaleEstimateByReportOverlap,omitempty"`
+	// EstimateReservations makes Score count the active Reservations on the node as the virtual Pods assigned to the node
+	// in the estimated usage, so that the capacity held by the Reservations is not regarded as free before the owner Pods
+	// occupy it. The resources allocated to the owner Pods are excluded, which are counted as the Pods. Not enabled by default.
+	EstimateReservations bool `json:"estimateReservations,omitempty"`
+	// ReclaimableUsageWeight indicates the percentage of the reported usage of the Batch and Free Pods counted
+	// in the node usage when scoring the Prod Pods, because the usage can be reclaimed for the Prod Pods,
+	// so that the nodes with reclaimable load are preferred to the nodes with equivalent Prod load.
+	// Not enabled by default, which counts the usage fully.
+	ReclaimableUsageWeight *int64 `json:"reclaimableUsageWeight,omitempty"`
+	// EstimatedPodMaxPriority indicates the priority above which the assigned Pods are excluded from the estimated
+	// usage of the assigned Pods, because the reported usage of such Pods already reflects the guaranteed baseline.
+	// Not enabled by default.
+	EstimatedPodMaxPriority *int32 `json:"estimatedPodMaxPriority,omitempty"`
+	// AllNodeMetricsExpiredRequeueSeconds makes PreFilter reject the Pod to requeue it while the NodeMetrics of
+	// all nodes are expired, e.g. during the rollout of koordlet, rather than placing it by the stale usage.
+	// The Pod is scheduled as usual once any NodeMetric recovers or the seconds elapse since all expired.
+	// Not enabled by default.
+	AllNodeMetricsExpiredRequeueSeconds int64 `json:"allNodeMetricsExpiredRequeueSeconds,omitempty"`
+	// ThresholdRelaxationPercentPerAttempt relaxes the usage thresholds for the Pod by the percentage points each
+	// time it is unschedulable because the nodes exceed the usage thresholds, so that the Pod pending across many
+	// scheduling cycles is scheduled eventually rather than starving. The MandatoryThresholds are never relaxed.
+	// Not enabled by default.
+	ThresholdRelaxationPercentPerAttempt int64 `json:"thresholdRelaxationPercentPerAttempt,omitempty"`
+	// MaxThresholdRelaxationPercent bounds the percentage points the usage thresholds are relaxed for the Pod,
+	// which is required if ThresholdRelaxationPercentPerAttempt is set.
+	MaxThresholdRelaxationPercent int64 `json:"maxThresholdRelaxationPercent,omitempty"`
+	// RecordScoreBreakdown makes PreBind record the score of the node the Pod is scheduled to and the scores
+	// of the weighted resources behind it in the annotation of the Pod for the offline analysis.
+	// Not enabled by default.
+	RecordScoreBreakdown bool `json:"recordScoreBreakdown,omitempty"`
+	// LogBindLoadProfile makes PreBind log the load profile of the node the Pod is bound to at V(2), including
+	// the usage reported in NodeMetric, the estimated usage of the Pod and the headroom of the weighted resources,
+	// which keeps the context of the decision for the postmortems. Not enabled by default.
+	LogBindLoadProfile bool `json:"logBindLoadProfile,omitempty"`
+	// ScoringDecisionRecordDir makes PreBind record the Pod, the scored nodes, their NodeMetrics and the scores
+	// to a file in the directory, which are replayed by ReplayScoringDecision against the changes of the scoring.
+	// Only the files of the latest 1000 decisions are kept. Not enabled by default.
+	ScoringDecisionRecordDir string `json:"scoringDecisionRecordDir,omitempty"`
+	// DynamicArgsConfigMapNamespace and DynamicArgsConfigMapName indicate the ConfigMap watched by the plugin.
+	// The thresholds, weights and scaling factors in the ConfigMap override the args at runtime without restarting.
+	// The scheduler RBAC only grants the ConfigMap load-aware-scheduling-args in the scheduler namespace.
+	// Not enabled by default.
+	DynamicArgsConfigMapNamespace string `json:"dynamicArgsConfigMapNamespace,omitempty"`
+	DynamicArgsConfigMapName      string `json:"dynamicArgsConfigMapName,omitempty"`
+}
+
+// NodeUsageSource indicates the source of the node usage
+type NodeUsageSource string
+
+const (
+	// NodeUsageSourceNodeUsage reads the usage from NodeMetric.Status.NodeMetric.NodeUsage
+	NodeUsageSourceNodeUsage NodeUsageSource = "NodeUsage"
+	// NodeUsageSourcePodsUsage sums the usages in NodeMetric.Status.PodsMetric of the Pods still existing
+	// and NodeMetric.Status.NodeMetric.SystemUsage
+	NodeUsageSourcePodsUsage NodeUsageSource = "PodsUsage"
+)
+
+// LoadAwareUsageType indicates the usage of the node that the Prod Pods are filtered or scored by
+type LoadAwareUsageType string
+
+const (
+	// LoadAwareUsageTypeTotal uses the usage of all Pods on the node
+	LoadAwareUsageTypeTotal LoadAwareUsageType = "Total"
+	// LoadAwareUsageTypeProd uses the usage of the Prod Pods on the node
+	LoadAwareUsageTypeProd LoadAwareUsageType = "Prod"
+)
+
+// LoadAwareResourceBase indicates the resources of the node that the usage is scored against
+type LoadAwareResourceBase string
+
+const (
+	// LoadAwareResourceBaseAllocatable scores the usage with the system usage excluded against the node allocatable,
+	// which already excludes the kube-reserved and system-reserved resources
+	LoadAwareResourceBaseAllocatable LoadAwareResourceBase = "Allocatable"
+	// LoadAwareResourceBaseCapacity scores the node usage with the system usage counted against the node capacity
+	LoadAwareResourceBaseCapacity LoadAwareResourceBase = "Capacity"
+)
+
+// LoadAwareScoringStrategy indicates the strategy of scoring nodes
+type LoadAwareScoringStrategy string
+
+const (
+	// LoadAwareScoringStrategyLeastUsage scores the nodes by the headroom remaining after placing the Pod
+	LoadAwareScoringStrategyLeastUsage LoadAwareScoringStrategy = "LeastUsage"
+	// LoadAwareScoringStrategyBestFit scores the nodes by the utilization after placing the Pod,
+	// and the nodes without enough headroom score 0
+	LoadAwareScoringStrategyBestFit LoadAwareScoringStrategy = "BestFit"
+	// LoadAwareScoringStrategyProportionalHeadroom scores the nodes by the ratio of the headroom
+	// remaining after placing the Pod to the headroom before placing the Pod
+	LoadAwareScoringStrategyProportionalHeadroom LoadAwareScoringStrategy = "ProportionalHeadroom"
+)
+
+// LoadAwareEstimatedOverflowPolicy indicates how a resource is scored if the estimated used exceeds the allocatable
+type LoadAwareEstimatedOverflowPolicy string
+
+const (
+	// LoadAwareEstimatedOverflowScoreZero scores the resource 0
+	LoadAwareEstimatedOverflowScoreZero LoadAwareEstimatedOverflowPolicy = "ScoreZero"
+	// LoadAwareEstimatedOverflowClampToAllocatable clamps the estimated used to the allocatable
+	// and scores the resource as fully used
+	LoadAwareEstimatedOverflowClampToAllocatable LoadAwareEstimatedOverflowPolicy = "ClampToAllocatable"
+)
+
+// LoadAwareEstimateMode indicates how the Pod usage is estimated from the requests and limits
+type LoadAwareEstimateMode string
+
+const (
+	// LoadAwareEstimateModeDefault estimates by the limit if it is larger than the request,
+	// otherwise by the scaled request
+	LoadAwareEstimateModeDefault LoadAwareEstimateMode = "Default"
+	// LoadAwareEstimateModeUseLimits estimates by the scaled limit, falling back to the scaled request
+	LoadAwareEstimateModeUseLimits LoadAwareEstimateMode = "UseLimits"
+)
+
+// LoadAwareSidecarOverhead is the usage of the sidecar injected into the Pods of the selected namespaces
+type LoadAwareSidecarOverhead struct {
+	// NamespaceSelector selects the namespaces whose Pods get the sidecar injected, e.g. istio-injection=enabled
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// ContainerName is the name of the sidecar container. The overhead is not added to the Pods having it,
+	// which are injected already. The overhead is always added if it is empty.
+	ContainerName string `json:"containerName,omitempty"`
+	// Overhead is the estimated usage of the sidecar added to the estimate of the Pods as is.
+	Overhead corev1.ResourceList `json:"overhead,omitempty"`
 }
 
 type LoadAwareSchedulingAggregatedArgs struct {
@@ -73,6 +403,17 @@ type LoadAwareSchedulingAggregatedArgs struct {
 	// ScoreAggregatedDuration indicates the statistical period of the percentile of Prod Pod's utilization when scoring
 	// If no specific period is set, the maximum period recorded by NodeMetrics will be used by default.
 	ScoreAggregatedDuration metav1.Duration `json:"scoreAggregatedDuration,omitempty"`
+
+	// ResourceAggregationTypes indicates the percentile types of the resources overriding UsageAggregationType
+	// and ScoreAggregationType, e.g. p95 for CPU to smooth the bursts and p99 for memory which does not recover
+	// safely from the bursts. The resources missing in it use the percentile types above, and it only takes effect
+	// if they are set. Not enabled by default.
+	ResourceAggregationTypes map[corev1.ResourceName]slov1alpha1.AggregationType `json:"resourceAggregationTypes,omitempty"`
+
+	// MinSampleCount indicates the minimum number of samples of the aggregated usage to be trusted.
+	// The aggregated usage with fewer samples is regarded as not reported, so that the node is not filtered
+	// by the aggregated usage and is scored by the estimated usage of the assigned Pods. Default is 0.
+	MinSampleCount int64 `json:"minSampleCount,omitempty"`
 }
 
 // ScoringStrategyType is a "string" type.
@@ -205,3 +546,50 @@ type DeviceShareArgs struct {
 	// Allocator indicates the expected allocator to use
 	Allocator string `json:"allocator,omitempty"`
 }
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BatchResourceFitArgs defines the parameters for BatchResourceFit plugin.
+type BatchResourceFitArgs struct {
+	metav1.TypeMeta
+
+	// OvercommitRatios indicates the percentage of the batch allocatable that can be requested on the node,
+	// e.g. 120 allows the batch requests to reach 1.2 times the batch allocatable. Default is 100 for batch-cpu and batch-memory.
+	OvercommitRatios map[corev1.ResourceName]int64 `json:"overcommitRatios,omitempty"`
+	// ScoringStrategy indicates the strategy of scoring nodes by the batch requests after placing the Pod.
+	// LeastAllocated prefers the nodes with more free batch resources, and MostAllocated prefers the nodes
+	// with less free batch resources. Default is LeastAllocated.
+	ScoringStrategy BatchResourceScoringStrategy `json:"scoringStrategy,omitempty"`
+	// ResourceWeights indicates the weights of batch-cpu and batch-memory in scoring.
+	ResourceWeights map[corev1.ResourceName]int64 `json:"resourceWeights,omitempty"`
+	// EvictionCountWeight is the score deducted for each batch pod recently evicted from the node,
+	// which is read from the node annotation reported by the koordlet. The default 0 disables the penalty.
+	EvictionCountWeight int64 `json:"evictionCountWeight,omitempty"`
+	// InitContainerMode indicates how the init containers are taken into the batch requests of the Pod.
+	// LegacyMax takes the max of the sum of the containers and each init container. SidecarAware keeps the
+	// sidecars running along with the containers and the init containers after them, which is the same as
+	// LegacyMax for the Pods without sidecars. Default is SidecarAware.
+	InitContainerMode BatchInitContainerMode `json:"initContainerMode,omitempty"`
+}
+
+// BatchResourceScoringStrategy indicates the strategy of scoring nodes by the batch resources
+type BatchResourceScoringStrategy string
+
+const (
+	// BatchResourceLeastAllocated scores the nodes by the free batch resources after placing the Pod
+	BatchResourceLeastAllocated BatchResourceScoringStrategy = "LeastAllocated"
+	// BatchResourceMostAllocated scores the nodes by the batch requests after placing the Pod
+	BatchResourceMostAllocated BatchResourceScoringStrategy = "MostAllocated"
+)
+
+// BatchInitContainerMode indicates how the init containers are taken into the batch requests of the Pod
+type BatchInitContainerMode string
+
+const (
+	// BatchInitContainerLegacyMax takes max(sum(containers), max(initContainers)) as the batch requests
+	BatchInitContainerLegacyMax BatchInitContainerMode = "LegacyMax"
+	// BatchInitContainerSidecarAware follows the sidecar containers since kube 1.28, which keep running along with
+	// the containers and the init containers after them. The sidecars are the init containers named by the
+	// AnnotationSidecarInitContainers of the Pod, since the vendored API drops the restartPolicy of the containers.
+	BatchInitContainerSidecarAware BatchInitContainerMode = "SidecarAware"
+)
@@ -25,10 +25,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
 )
 
 var (
-	defaultNodeMetricExpirationSeconds int64 = 180
+	defaultNodeMetricExpirationSeconds     int64 = 180
+	defaultNodeMetricReportIntervalSeconds int64 = 60
 
 	defaultResourceWeights = map[corev1.ResourceName]int64{
 		corev1.ResourceCPU:    1,
@@ -77,6 +80,17 @@ var (
 
 	defaultTimeout           = 600 * time.Second
 	defaultControllerWorkers = 1
+
+	defaultBatchResourceOvercommitRatios = map[corev1.ResourceName]int64{
+		extension.BatchCPU:    100, // 100%
+		extension.BatchMemory: 100, // 100%
+	}
+	defaultBatchResourceWeights = map[corev1.ResourceName]int64{
+		extension.BatchCPU:    1,
+		extension.BatchMemory: 1,
+	}
+	defaultBatchResourceScoringStrategy = BatchResourceLeastAllocated
+	defaultBatchInitContainerMode       = BatchInitContainerSidecarAware
 )
 
 // SetDefaults_LoadAwareSchedulingArgs sets the default parameters for LoadAwareScheduling plugin.
@@ -87,6 +101,9 @@ func SetDefaults_LoadAwareSchedulingArgs(obj *LoadAwareSchedulingArgs) {
 	if obj.NodeMetricExpirationSeconds == nil {
 		obj.NodeMetricExpirationSeconds = pointer.Int64Ptr(defaultNodeMetricExpirationSeconds)
 	}
+	if obj.DefaultNodeMetricReportIntervalSeconds == nil {
+		obj.DefaultNodeMetricReportIntervalSeconds = pointer.Int64Ptr(defaultNodeMetricReportIntervalSeconds)
+	}
 	if len(obj.ResourceWeights) == 0 {
 		obj.ResourceWeights = defaultResourceWeights
 	}
@@ -102,6 +119,18 @@ func SetDefaults_LoadAwareSchedulingArgs(obj *LoadAwareSchedulingArgs) {
 			}
 		}
 	}
+	if obj.FilterUsageSource == "" {
+		obj.FilterUsageSource = NodeUsageSourceNodeUsage
+	}
+	if obj.ScoringStrategy == "" {
+		obj.ScoringStrategy = LoadAwareScoringStrategyLeastUsage
+	}
+	if obj.EstimatedOverflowPolicy == "" {
+		obj.EstimatedOverflowPolicy = LoadAwareEstimatedOverflowScoreZero
+	}
+	if obj.EstimateMode == "" {
+		obj.EstimateMode = LoadAwareEstimateModeDefault
+	}
 }
 
 // SetDefaults_NodeNUMAResourceArgs sets the default parameters for NodeNUMANodeResource plugin.
@@ -158,3 +187,19 @@ func SetDefaults_CoschedulingArgs(obj *CoschedulingArgs) {
 		obj.ControllerWorkers = pointer.Int64Ptr(int64(defaultControllerWorkers))
 	}
 }
+
+// SetDefaults_BatchResourceFitArgs sets the default parameters for BatchResourceFit plugin.
+func SetDefaults_BatchResourceFitArgs(obj *BatchResourceFitArgs) {
+	if len(obj.OvercommitRatios) == 0 {
+		obj.OvercommitRatios = defaultBatchResourceOvercommitRatios
+	}
+	if len(obj.ResourceWeights) == 0 {
+		obj.ResourceWeights = defaultBatchResourceWeights
+	}
+	if obj.ScoringStrategy == "" {
+		obj.ScoringStrategy = defaultBatchResourceScoringStrategy
+	}
+	if obj.InitContainerMode == "" {
+		obj.InitContainerMode = defaultBatchInitContainerMode
+	}
+}
@@ -27,8 +27,8 @@ import (
 	extension "github.com/koordinator-sh/koordinator/apis/extension"
 	v1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	config "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
-	corev1 "k8s.io/api/core/v1"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	apisconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -41,6 +41,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*BatchResourceFitArgs)(nil), (*config.BatchResourceFitArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_BatchResourceFitArgs_To_config_BatchResourceFitArgs(a.(*BatchResourceFitArgs), b.(*config.BatchResourceFitArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.BatchResourceFitArgs)(nil), (*BatchResourceFitArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_BatchResourceFitArgs_To_v1beta2_BatchResourceFitArgs(a.(*config.BatchResourceFitArgs), b.(*BatchResourceFitArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CoschedulingArgs)(nil), (*config.CoschedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_CoschedulingArgs_To_config_CoschedulingArgs(a.(*CoschedulingArgs), b.(*config.CoschedulingArgs), scope)
 	}); err != nil {
@@ -124,8 +134,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1beta2_BatchResourceFitArgs_To_config_BatchResourceFitArgs(in *BatchResourceFitArgs, out *config.BatchResourceFitArgs, s conversion.Scope) error {
+	out.OvercommitRatios = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.OvercommitRatios))
+	out.ScoringStrategy = config.BatchResourceScoringStrategy(in.ScoringStrategy)
+	out.ResourceWeights = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.ResourceWeights))
+	out.EvictionCountWeight = in.EvictionCountWeight
+	out.InitContainerMode = config.BatchInitContainerMode(in.InitContainerMode)
+	return nil
+}
+
+// Convert_v1beta2_BatchResourceFitArgs_To_config_BatchResourceFitArgs is an autogenerated conversion function.
+func Convert_v1beta2_BatchResourceFitArgs_To_config_BatchResourceFitArgs(in *BatchResourceFitArgs, out *config.BatchResourceFitArgs, s conversion.Scope) error {
+	return autoConvert_v1beta2_BatchResourceFitArgs_To_config_BatchResourceFitArgs(in, out, s)
+}
+
+func autoConvert_config_BatchResourceFitArgs_To_v1beta2_BatchResourceFitArgs(in *config.BatchResourceFitArgs, out *BatchResourceFitArgs, s conversion.Scope) error {
+	out.OvercommitRatios = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.OvercommitRatios))
+	out.ScoringStrategy = BatchResourceScoringStrategy(in.ScoringStrategy)
+	out.ResourceWeights = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.ResourceWeights))
+	out.EvictionCountWeight = in.EvictionCountWeight
+	out.InitContainerMode = BatchInitContainerMode(in.InitContainerMode)
+	return nil
+}
+
+// Convert_config_BatchResourceFitArgs_To_v1beta2_BatchResourceFitArgs is an autogenerated conversion function.
+func Convert_config_BatchResourceFitArgs_To_v1beta2_BatchResourceFitArgs(in *config.BatchResourceFitArgs, out *BatchResourceFitArgs, s conversion.Scope) error {
+	return autoConvert_config_BatchResourceFitArgs_To_v1beta2_BatchResourceFitArgs(in, out, s)
+}
+
 func autoConvert_v1beta2_CoschedulingArgs_To_config_CoschedulingArgs(in *CoschedulingArgs, out *config.CoschedulingArgs, s conversion.Scope) error {
-	out.DefaultTimeout = (*v1.Duration)(unsafe.Pointer(in.DefaultTimeout))
+	out.DefaultTimeout = (*metav1.Duration)(unsafe.Pointer(in.DefaultTimeout))
 	out.ControllerWorkers = (*int64)(unsafe.Pointer(in.ControllerWorkers))
 	return nil
 }
@@ -136,7 +174,7 @@ func Convert_v1beta2_CoschedulingArgs_To_config_CoschedulingArgs(in *Coschedulin
 }
 
 func autoConvert_config_CoschedulingArgs_To_v1beta2_CoschedulingArgs(in *config.CoschedulingArgs, out *CoschedulingArgs, s conversion.Scope) error {
-	out.DefaultTimeout = (*v1.Duration)(unsafe.Pointer(in.DefaultTimeout))
+	out.DefaultTimeout = (*metav1.Duration)(unsafe.Pointer(in.DefaultTimeout))
 	out.ControllerWorkers = (*int64)(unsafe.Pointer(in.ControllerWorkers))
 	return nil
 }
@@ -167,10 +205,10 @@ func Convert_config_DeviceShareArgs_To_v1beta2_DeviceShareArgs(in *config.Device
 }
 
 func autoConvert_v1beta2_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in *ElasticQuotaArgs, out *config.ElasticQuotaArgs, s conversion.Scope) error {
-	out.DelayEvictTime = (*v1.Duration)(unsafe.Pointer(in.DelayEvictTime))
-	out.RevokePodInterval = (*v1.Duration)(unsafe.Pointer(in.RevokePodInterval))
-	out.DefaultQuotaGroupMax = *(*corev1.ResourceList)(unsafe.Pointer(&in.DefaultQuotaGroupMax))
-	out.SystemQuotaGroupMax = *(*corev1.ResourceList)(unsafe.Pointer(&in.SystemQuotaGroupMax))
+	out.DelayEvictTime = (*metav1.Duration)(unsafe.Pointer(in.DelayEvictTime))
+	out.RevokePodInterval = (*metav1.Duration)(unsafe.Pointer(in.RevokePodInterval))
+	out.DefaultQuotaGroupMax = *(*v1.ResourceList)(unsafe.Pointer(&in.DefaultQuotaGroupMax))
+	out.SystemQuotaGroupMax = *(*v1.ResourceList)(unsafe.Pointer(&in.SystemQuotaGroupMax))
 	out.QuotaGroupNamespace = in.QuotaGroupNamespace
 	out.MonitorAllQuotas = (*bool)(unsafe.Pointer(in.MonitorAllQuotas))
 	out.EnableCheckParentQuota = (*bool)(unsafe.Pointer(in.EnableCheckParentQuota))
@@ -183,10 +221,10 @@ func Convert_v1beta2_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in *ElasticQuot
 }
 
 func autoConvert_config_ElasticQuotaArgs_To_v1beta2_ElasticQuotaArgs(in *config.ElasticQuotaArgs, out *ElasticQuotaArgs, s conversion.Scope) error {
-	out.DelayEvictTime = (*v1.Duration)(unsafe.Pointer(in.DelayEvictTime))
-	out.RevokePodInterval = (*v1.Duration)(unsafe.Pointer(in.RevokePodInterval))
-	out.DefaultQuotaGroupMax = *(*corev1.ResourceList)(unsafe.Pointer(&in.DefaultQuotaGroupMax))
-	out.SystemQuotaGroupMax = *(*corev1.ResourceList)(unsafe.Pointer(&in.SystemQuotaGroupMax))
+	out.DelayEvictTime = (*metav1.Duration)(unsafe.Pointer(in.DelayEvictTime))
+	out.RevokePodInterval = (*metav1.Duration)(unsafe.Pointer(in.RevokePodInterval))
+	out.DefaultQuotaGroupMax = *(*v1.ResourceList)(unsafe.Pointer(&in.DefaultQuotaGroupMax))
+	out.SystemQuotaGroupMax = *(*v1.ResourceList)(unsafe.Pointer(&in.SystemQuotaGroupMax))
 	out.QuotaGroupNamespace = in.QuotaGroupNamespace
 	out.MonitorAllQuotas = (*bool)(unsafe.Pointer(in.MonitorAllQuotas))
 	out.EnableCheckParentQuota = (*bool)(unsafe.Pointer(in.EnableCheckParentQuota))
@@ -199,15 +237,17 @@ func Convert_config_ElasticQuotaArgs_To_v1beta2_ElasticQuotaArgs(in *config.Elas
 }
 
 func autoConvert_v1beta2_LoadAwareSchedulingAggregatedArgs_To_config_LoadAwareSchedulingAggregatedArgs(in *LoadAwareSchedulingAggregatedArgs, out *config.LoadAwareSchedulingAggregatedArgs, s conversion.Scope) error {
-	out.UsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
+	out.UsageThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
 	out.UsageAggregationType = v1alpha1.AggregationType(in.UsageAggregationType)
-	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.UsageAggregatedDuration, &out.UsageAggregatedDuration, s); err != nil {
+	if err := metav1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.UsageAggregatedDuration, &out.UsageAggregatedDuration, s); err != nil {
 		return err
 	}
 	out.ScoreAggregationType = v1alpha1.AggregationType(in.ScoreAggregationType)
-	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.ScoreAggregatedDuration, &out.ScoreAggregatedDuration, s); err != nil {
+	if err := metav1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.ScoreAggregatedDuration, &out.ScoreAggregatedDuration, s); err != nil {
 		return err
 	}
+	out.ResourceAggregationTypes = *(*map[v1.ResourceName]v1alpha1.AggregationType)(unsafe.Pointer(&in.ResourceAggregationTypes))
+	out.MinSampleCount = in.MinSampleCount
 	return nil
 }
 
@@ -217,15 +257,17 @@ func Convert_v1beta2_LoadAwareSchedulingAggregatedArgs_To_config_LoadAwareSchedu
 }
 
 func autoConvert_config_LoadAwareSchedulingAggregatedArgs_To_v1beta2_LoadAwareSchedulingAggregatedArgs(in *config.LoadAwareSchedulingAggregatedArgs, out *LoadAwareSchedulingAggregatedArgs, s conversion.Scope) error {
-	out.UsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
+	out.UsageThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
 	out.UsageAggregationType = v1alpha1.AggregationType(in.UsageAggregationType)
-	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.UsageAggregatedDuration, &out.UsageAggregatedDuration, s); err != nil {
+	if err := metav1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.UsageAggregatedDuration, &out.UsageAggregatedDuration, s); err != nil {
 		return err
 	}
 	out.ScoreAggregationType = v1alpha1.AggregationType(in.ScoreAggregationType)
-	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.ScoreAggregatedDuration, &out.ScoreAggregatedDuration, s); err != nil {
+	if err := metav1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.ScoreAggregatedDuration, &out.ScoreAggregatedDuration, s); err != nil {
 		return err
 	}
+	out.ResourceAggregationTypes = *(*map[v1.ResourceName]v1alpha1.AggregationType)(unsafe.Pointer(&in.ResourceAggregationTypes))
+	out.MinSampleCount = in.MinSampleCount
 	return nil
 }
 
@@ -237,14 +279,40 @@ func Convert_config_LoadAwareSchedulingAggregatedArgs_To_v1beta2_LoadAwareSchedu
 func autoConvert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(in *LoadAwareSchedulingArgs, out *config.LoadAwareSchedulingArgs, s conversion.Scope) error {
 	out.FilterExpiredNodeMetrics = (*bool)(unsafe.Pointer(in.FilterExpiredNodeMetrics))
 	out.NodeMetricExpirationSeconds = (*int64)(unsafe.Pointer(in.NodeMetricExpirationSeconds))
-	out.ResourceWeights = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.ResourceWeights))
-	out.UsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
-	out.ProdUsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.ProdUsageThresholds))
-	if err := v1.Convert_Pointer_bool_To_bool(&in.ScoreAccordingProdUsage, &out.ScoreAccordingProdUsage, s); err != nil {
+	out.DefaultNodeMetricReportIntervalSeconds = (*int64)(unsafe.Pointer(in.DefaultNodeMetricReportIntervalSeconds))
+	if err := metav1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.NodeMetricTimeout, &out.NodeMetricTimeout, s); err != nil {
+		return err
+	}
+	out.ResourceWeights = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.ResourceWeights))
+	out.DefaultResourceWeight = in.DefaultResourceWeight
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ScoreNonPoolableResources, &out.ScoreNonPoolableResources, s); err != nil {
+		return err
+	}
+	out.PriorityClassResourceWeights = *(*map[extension.PriorityClass]map[v1.ResourceName]int64)(unsafe.Pointer(&in.PriorityClassResourceWeights))
+	out.DynamicResourceWeightMaxBoostPercent = in.DynamicResourceWeightMaxBoostPercent
+	out.UsageThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
+	out.ProdUsageThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.ProdUsageThresholds))
+	out.MandatoryThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.MandatoryThresholds))
+	out.CombinedThreshold = in.CombinedThreshold
+	out.GPUUsageThreshold = in.GPUUsageThreshold
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ScoreAccordingProdUsage, &out.ScoreAccordingProdUsage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ScoreAccordingNodeReservation, &out.ScoreAccordingNodeReservation, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ScoreAccordingAvailable, &out.ScoreAccordingAvailable, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ScoreBatchPodByBatchResources, &out.ScoreBatchPodByBatchResources, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ScoreAccordingPriorityClassAllocatable, &out.ScoreAccordingPriorityClassAllocatable, s); err != nil {
 		return err
 	}
 	out.Estimator = in.Estimator
-	out.EstimatedScalingFactors = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.EstimatedScalingFactors))
+	out.EstimatedScalingFactors = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.EstimatedScalingFactors))
+	out.QoSEstimatedScalingFactors = *(*map[v1.PodQOSClass]map[v1.ResourceName]int64)(unsafe.Pointer(&in.QoSEstimatedScalingFactors))
 	if in.Aggregated != nil {
 		in, out := &in.Aggregated, &out.Aggregated
 		*out = new(config.LoadAwareSchedulingAggregatedArgs)
@@ -254,6 +322,78 @@ func autoConvert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingAr
 	} else {
 		out.Aggregated = nil
 	}
+	out.FilterUsageSource = config.NodeUsageSource(in.FilterUsageSource)
+	out.FilterUsageType = config.LoadAwareUsageType(in.FilterUsageType)
+	out.ScoreUsageType = config.LoadAwareUsageType(in.ScoreUsageType)
+	out.ScoreResourceBase = config.LoadAwareResourceBase(in.ScoreResourceBase)
+	out.PreferredNodeAffinityWeight = in.PreferredNodeAffinityWeight
+	out.SiblingPodPenaltyWeight = in.SiblingPodPenaltyWeight
+	out.SameWorkloadAffinityWeight = in.SameWorkloadAffinityWeight
+	out.RequestsUsageGapWeight = in.RequestsUsageGapWeight
+	out.CompressibilityWeight = in.CompressibilityWeight
+	out.UsageTrendWeight = in.UsageTrendWeight
+	out.ThresholdProximityPenalty = in.ThresholdProximityPenalty
+	out.ScoreScalingPercentage = in.ScoreScalingPercentage
+	out.ScoreSafetyBufferPercent = in.ScoreSafetyBufferPercent
+	out.NodePoolLabelKey = in.NodePoolLabelKey
+	out.NodeMetricShardLabelKey = in.NodeMetricShardLabelKey
+	out.ExemptNodeLabelKey = in.ExemptNodeLabelKey
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.FallbackToMetricsServer, &out.FallbackToMetricsServer, s); err != nil {
+		return err
+	}
+	out.FreeCoresScoreWeight = in.FreeCoresScoreWeight
+	out.MaxScoreFreeCores = in.MaxScoreFreeCores
+	out.PodDensityWeight = in.PodDensityWeight
+	out.ScoringStrategy = config.LoadAwareScoringStrategy(in.ScoringStrategy)
+	out.EstimatedOverflowPolicy = config.LoadAwareEstimatedOverflowPolicy(in.EstimatedOverflowPolicy)
+	out.CriticalResources = *(*[]v1.ResourceName)(unsafe.Pointer(&in.CriticalResources))
+	out.MemoryCacheDiscountRatio = in.MemoryCacheDiscountRatio
+	out.MemoryPressureKnee = in.MemoryPressureKnee
+	out.ScoreTopKNodes = in.ScoreTopKNodes
+	out.ScoreSampleNodes = in.ScoreSampleNodes
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.EstimateWithoutInitContainers, &out.EstimateWithoutInitContainers, s); err != nil {
+		return err
+	}
+	out.EstimateMode = config.LoadAwareEstimateMode(in.EstimateMode)
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.RespectZeroRequests, &out.RespectZeroRequests, s); err != nil {
+		return err
+	}
+	out.SidecarOverheads = *(*[]config.LoadAwareSidecarOverhead)(unsafe.Pointer(&in.SidecarOverheads))
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.AdvisoryOnly, &out.AdvisoryOnly, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.SoftThreshold, &out.SoftThreshold, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.PreferNominatedNode, &out.PreferNominatedNode, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.RequireNodeMetric, &out.RequireNodeMetric, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ReserveCheckThresholds, &out.ReserveCheckThresholds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.ScaleEstimateByReportOverlap, &out.ScaleEstimateByReportOverlap, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.EstimateReservations, &out.EstimateReservations, s); err != nil {
+		return err
+	}
+	out.ReclaimableUsageWeight = (*int64)(unsafe.Pointer(in.ReclaimableUsageWeight))
+	out.EstimatedPodMaxPriority = (*int32)(unsafe.Pointer(in.EstimatedPodMaxPriority))
+	out.AllNodeMetricsExpiredRequeueSeconds = in.AllNodeMetricsExpiredRequeueSeconds
+	out.ThresholdRelaxationPercentPerAttempt = in.ThresholdRelaxationPercentPerAttempt
+	out.MaxThresholdRelaxationPercent = in.MaxThresholdRelaxationPercent
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.RecordScoreBreakdown, &out.RecordScoreBreakdown, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.LogBindLoadProfile, &out.LogBindLoadProfile, s); err != nil {
+		return err
+	}
+	out.ScoringDecisionRecordDir = in.ScoringDecisionRecordDir
+	out.DynamicArgsConfigMapNamespace = in.DynamicArgsConfigMapNamespace
+	out.DynamicArgsConfigMapName = in.DynamicArgsConfigMapName
 	return nil
 }
 
@@ -265,14 +405,40 @@ func Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(i
 func autoConvert_config_LoadAwareSchedulingArgs_To_v1beta2_LoadAwareSchedulingArgs(in *config.LoadAwareSchedulingArgs, out *LoadAwareSchedulingArgs, s conversion.Scope) error {
 	out.FilterExpiredNodeMetrics = (*bool)(unsafe.Pointer(in.FilterExpiredNodeMetrics))
 	out.NodeMetricExpirationSeconds = (*int64)(unsafe.Pointer(in.NodeMetricExpirationSeconds))
-	out.ResourceWeights = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.ResourceWeights))
-	out.UsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
-	out.ProdUsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.ProdUsageThresholds))
-	if err := v1.Convert_bool_To_Pointer_bool(&in.ScoreAccordingProdUsage, &out.ScoreAccordingProdUsage, s); err != nil {
+	out.DefaultNodeMetricReportIntervalSeconds = (*int64)(unsafe.Pointer(in.DefaultNodeMetricReportIntervalSeconds))
+	if err := metav1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.NodeMetricTimeout, &out.NodeMetricTimeout, s); err != nil {
+		return err
+	}
+	out.ResourceWeights = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.ResourceWeights))
+	out.DefaultResourceWeight = in.DefaultResourceWeight
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ScoreNonPoolableResources, &out.ScoreNonPoolableResources, s); err != nil {
+		return err
+	}
+	out.PriorityClassResourceWeights = *(*map[extension.PriorityClass]map[v1.ResourceName]int64)(unsafe.Pointer(&in.PriorityClassResourceWeights))
+	out.DynamicResourceWeightMaxBoostPercent = in.DynamicResourceWeightMaxBoostPercent
+	out.UsageThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
+	out.ProdUsageThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.ProdUsageThresholds))
+	out.MandatoryThresholds = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.MandatoryThresholds))
+	out.CombinedThreshold = in.CombinedThreshold
+	out.GPUUsageThreshold = in.GPUUsageThreshold
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ScoreAccordingProdUsage, &out.ScoreAccordingProdUsage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ScoreAccordingNodeReservation, &out.ScoreAccordingNodeReservation, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ScoreAccordingAvailable, &out.ScoreAccordingAvailable, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ScoreBatchPodByBatchResources, &out.ScoreBatchPodByBatchResources, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ScoreAccordingPriorityClassAllocatable, &out.ScoreAccordingPriorityClassAllocatable, s); err != nil {
 		return err
 	}
 	out.Estimator = in.Estimator
-	out.EstimatedScalingFactors = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.EstimatedScalingFactors))
+	out.EstimatedScalingFactors = *(*map[v1.ResourceName]int64)(unsafe.Pointer(&in.EstimatedScalingFactors))
+	out.QoSEstimatedScalingFactors = *(*map[v1.PodQOSClass]map[v1.ResourceName]int64)(unsafe.Pointer(&in.QoSEstimatedScalingFactors))
 	if in.Aggregated != nil {
 		in, out := &in.Aggregated, &out.Aggregated
 		*out = new(LoadAwareSchedulingAggregatedArgs)
@@ -282,6 +448,78 @@ func autoConvert_config_LoadAwareSchedulingArgs_To_v1beta2_LoadAwareSchedulingAr
 	} else {
 		out.Aggregated = nil
 	}
+	out.FilterUsageSource = NodeUsageSource(in.FilterUsageSource)
+	out.FilterUsageType = LoadAwareUsageType(in.FilterUsageType)
+	out.ScoreUsageType = LoadAwareUsageType(in.ScoreUsageType)
+	out.ScoreResourceBase = LoadAwareResourceBase(in.ScoreResourceBase)
+	out.PreferredNodeAffinityWeight = in.PreferredNodeAffinityWeight
+	out.SiblingPodPenaltyWeight = in.SiblingPodPenaltyWeight
+	out.SameWorkloadAffinityWeight = in.SameWorkloadAffinityWeight
+	out.RequestsUsageGapWeight = in.RequestsUsageGapWeight
+	out.CompressibilityWeight = in.CompressibilityWeight
+	out.UsageTrendWeight = in.UsageTrendWeight
+	out.ThresholdProximityPenalty = in.ThresholdProximityPenalty
+	out.ScoreScalingPercentage = in.ScoreScalingPercentage
+	out.ScoreSafetyBufferPercent = in.ScoreSafetyBufferPercent
+	out.NodePoolLabelKey = in.NodePoolLabelKey
+	out.NodeMetricShardLabelKey = in.NodeMetricShardLabelKey
+	out.ExemptNodeLabelKey = in.ExemptNodeLabelKey
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.FallbackToMetricsServer, &out.FallbackToMetricsServer, s); err != nil {
+		return err
+	}
+	out.FreeCoresScoreWeight = in.FreeCoresScoreWeight
+	out.MaxScoreFreeCores = in.MaxScoreFreeCores
+	out.PodDensityWeight = in.PodDensityWeight
+	out.ScoringStrategy = LoadAwareScoringStrategy(in.ScoringStrategy)
+	out.EstimatedOverflowPolicy = LoadAwareEstimatedOverflowPolicy(in.EstimatedOverflowPolicy)
+	out.CriticalResources = *(*[]v1.ResourceName)(unsafe.Pointer(&in.CriticalResources))
+	out.MemoryCacheDiscountRatio = in.MemoryCacheDiscountRatio
+	out.MemoryPressureKnee = in.MemoryPressureKnee
+	out.ScoreTopKNodes = in.ScoreTopKNodes
+	out.ScoreSampleNodes = in.ScoreSampleNodes
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.EstimateWithoutInitContainers, &out.EstimateWithoutInitContainers, s); err != nil {
+		return err
+	}
+	out.EstimateMode = LoadAwareEstimateMode(in.EstimateMode)
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.RespectZeroRequests, &out.RespectZeroRequests, s); err != nil {
+		return err
+	}
+	out.SidecarOverheads = *(*[]LoadAwareSidecarOverhead)(unsafe.Pointer(&in.SidecarOverheads))
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.AdvisoryOnly, &out.AdvisoryOnly, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.SoftThreshold, &out.SoftThreshold, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.PreferNominatedNode, &out.PreferNominatedNode, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.RequireNodeMetric, &out.RequireNodeMetric, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ReserveCheckThresholds, &out.ReserveCheckThresholds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.ScaleEstimateByReportOverlap, &out.ScaleEstimateByReportOverlap, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.EstimateReservations, &out.EstimateReservations, s); err != nil {
+		return err
+	}
+	out.ReclaimableUsageWeight = (*int64)(unsafe.Pointer(in.ReclaimableUsageWeight))
+	out.EstimatedPodMaxPriority = (*int32)(unsafe.Pointer(in.EstimatedPodMaxPriority))
+	out.AllNodeMetricsExpiredRequeueSeconds = in.AllNodeMetricsExpiredRequeueSeconds
+	out.ThresholdRelaxationPercentPerAttempt = in.ThresholdRelaxationPercentPerAttempt
+	out.MaxThresholdRelaxationPercent = in.MaxThresholdRelaxationPercent
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.RecordScoreBreakdown, &out.RecordScoreBreakdown, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.LogBindLoadProfile, &out.LogBindLoadProfile, s); err != nil {
+		return err
+	}
+	out.ScoringDecisionRecordDir = in.ScoringDecisionRecordDir
+	out.DynamicArgsConfigMapNamespace = in.DynamicArgsConfigMapNamespace
+	out.DynamicArgsConfigMapName = in.DynamicArgsConfigMapName
 	return nil
 }
 
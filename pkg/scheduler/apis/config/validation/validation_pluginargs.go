@@ -21,8 +21,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 )
 
@@ -33,16 +38,92 @@ func ValidateLoadAwareSchedulingArgs(args *config.LoadAwareSchedulingArgs) error
 	if args.NodeMetricExpirationSeconds != nil && *args.NodeMetricExpirationSeconds <= 0 {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("nodeMetricExpiredSeconds"), *args.NodeMetricExpirationSeconds, "nodeMetricExpiredSeconds should be a positive value"))
 	}
+	if args.DefaultNodeMetricReportIntervalSeconds != nil && *args.DefaultNodeMetricReportIntervalSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("defaultNodeMetricReportIntervalSeconds"), *args.DefaultNodeMetricReportIntervalSeconds, "defaultNodeMetricReportIntervalSeconds should be a positive value"))
+	}
+	if args.NodeMetricTimeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("nodeMetricTimeout"), args.NodeMetricTimeout, "nodeMetricTimeout should not be negative"))
+	}
 
 	if err := validateResourceWeights(args.ResourceWeights); err != nil {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("resourceWeights"), args.ResourceWeights, err.Error()))
 	}
+	if args.DefaultResourceWeight < 0 || args.DefaultResourceWeight > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("defaultResourceWeight"), args.DefaultResourceWeight, "defaultResourceWeight should be in the range [0, 100]"))
+	}
 	if err := validateResourceThresholds(args.UsageThresholds); err != nil {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("usageThresholds"), args.UsageThresholds, err.Error()))
 	}
+	if err := validateResourceThresholds(args.MandatoryThresholds); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("mandatoryThresholds"), args.MandatoryThresholds, err.Error()))
+	}
+	if args.CombinedThreshold < 0 || args.CombinedThreshold > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("combinedThreshold"), args.CombinedThreshold, "combinedThreshold should be in the range [0, 100]"))
+	}
+	if args.GPUUsageThreshold < 0 || args.GPUUsageThreshold > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("gpuUsageThreshold"), args.GPUUsageThreshold, "gpuUsageThreshold should be in the range [0, 100]"))
+	}
+	if args.MemoryCacheDiscountRatio < 0 || args.MemoryCacheDiscountRatio > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("memoryCacheDiscountRatio"), args.MemoryCacheDiscountRatio, "memoryCacheDiscountRatio should be in the range [0, 100]"))
+	}
+	if args.MemoryPressureKnee < 0 || args.MemoryPressureKnee >= 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("memoryPressureKnee"), args.MemoryPressureKnee, "memoryPressureKnee should be in the range [0, 100)"))
+	}
+	if args.Aggregated != nil && args.Aggregated.MinSampleCount < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("aggregated", "minSampleCount"), args.Aggregated.MinSampleCount, "minSampleCount should not be negative"))
+	}
+	if args.Aggregated != nil {
+		for resourceName, aggregationType := range args.Aggregated.ResourceAggregationTypes {
+			switch aggregationType {
+			case slov1alpha1.AVG, slov1alpha1.P50, slov1alpha1.P90, slov1alpha1.P95, slov1alpha1.P99:
+			default:
+				allErrs = append(allErrs, field.NotSupported(field.NewPath("aggregated", "resourceAggregationTypes").Key(string(resourceName)), aggregationType,
+					[]string{string(slov1alpha1.AVG), string(slov1alpha1.P50), string(slov1alpha1.P90), string(slov1alpha1.P95), string(slov1alpha1.P99)}))
+			}
+		}
+	}
+	if args.ScoreTopKNodes < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scoreTopKNodes"), args.ScoreTopKNodes, "scoreTopKNodes should not be negative"))
+	}
+	if args.ScoreSampleNodes < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scoreSampleNodes"), args.ScoreSampleNodes, "scoreSampleNodes should not be negative"))
+	} else if args.ScoreSampleNodes > 0 && args.ScoreTopKNodes > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scoreSampleNodes"), args.ScoreSampleNodes,
+			"scoreSampleNodes and scoreTopKNodes are mutually exclusive"))
+	}
 	if err := validateEstimatedResourceThresholds(args.EstimatedScalingFactors); err != nil {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("estimatedScalingFactors"), args.EstimatedScalingFactors, err.Error()))
 	}
+	for qosClass, scalingFactors := range args.QoSEstimatedScalingFactors {
+		switch qosClass {
+		case corev1.PodQOSGuaranteed, corev1.PodQOSBurstable, corev1.PodQOSBestEffort:
+		default:
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("qosEstimatedScalingFactors"), qosClass,
+				[]string{string(corev1.PodQOSGuaranteed), string(corev1.PodQOSBurstable), string(corev1.PodQOSBestEffort)}))
+			continue
+		}
+		if err := validateEstimatedResourceThresholds(scalingFactors); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("qosEstimatedScalingFactors").Key(string(qosClass)), scalingFactors, err.Error()))
+		}
+	}
+
+	for priorityClass, resourceWeights := range args.PriorityClassResourceWeights {
+		switch priorityClass {
+		case apiext.PriorityProd, apiext.PriorityMid, apiext.PriorityBatch, apiext.PriorityFree:
+		default:
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("priorityClassResourceWeights"), priorityClass,
+				[]string{string(apiext.PriorityProd), string(apiext.PriorityMid), string(apiext.PriorityBatch), string(apiext.PriorityFree)}))
+			continue
+		}
+		if err := validateResourceWeights(resourceWeights); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("priorityClassResourceWeights").Key(string(priorityClass)), resourceWeights, err.Error()))
+		}
+	}
+
+	if args.DynamicResourceWeightMaxBoostPercent < 0 || args.DynamicResourceWeightMaxBoostPercent > 1000 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("dynamicResourceWeightMaxBoostPercent"), args.DynamicResourceWeightMaxBoostPercent,
+			"dynamicResourceWeightMaxBoostPercent should be in the range [0, 1000]"))
+	}
 
 	for resourceName := range args.ResourceWeights {
 		if _, ok := args.EstimatedScalingFactors[resourceName]; !ok {
@@ -51,6 +132,178 @@ func ValidateLoadAwareSchedulingArgs(args *config.LoadAwareSchedulingArgs) error
 		}
 	}
 
+	for i, resourceName := range args.CriticalResources {
+		if _, ok := args.ResourceWeights[resourceName]; !ok {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("criticalResources").Index(i), resourceName, "critical resource should be in resourceWeights"))
+		}
+	}
+
+	if args.PreferredNodeAffinityWeight < 0 || args.PreferredNodeAffinityWeight > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("preferredNodeAffinityWeight"), args.PreferredNodeAffinityWeight,
+			fmt.Sprintf("preferredNodeAffinityWeight should be in the range [0, %d]", framework.MaxNodeScore)))
+	}
+
+	if args.SiblingPodPenaltyWeight < 0 || args.SiblingPodPenaltyWeight > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("siblingPodPenaltyWeight"), args.SiblingPodPenaltyWeight,
+			fmt.Sprintf("siblingPodPenaltyWeight should be in the range [0, %d]", framework.MaxNodeScore)))
+	}
+
+	if args.SameWorkloadAffinityWeight < 0 || args.SameWorkloadAffinityWeight > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("sameWorkloadAffinityWeight"), args.SameWorkloadAffinityWeight,
+			fmt.Sprintf("sameWorkloadAffinityWeight should be in the range [0, %d]", framework.MaxNodeScore)))
+	} else if args.SameWorkloadAffinityWeight > 0 && args.SiblingPodPenaltyWeight > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("sameWorkloadAffinityWeight"), args.SameWorkloadAffinityWeight,
+			"sameWorkloadAffinityWeight and siblingPodPenaltyWeight are mutually exclusive"))
+	}
+
+	if args.RequestsUsageGapWeight < 0 || args.RequestsUsageGapWeight > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("requestsUsageGapWeight"), args.RequestsUsageGapWeight,
+			fmt.Sprintf("requestsUsageGapWeight should be in the range [0, %d]", framework.MaxNodeScore)))
+	}
+	if args.CompressibilityWeight < 0 || args.CompressibilityWeight > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("compressibilityWeight"), args.CompressibilityWeight,
+			fmt.Sprintf("compressibilityWeight should be in the range [0, %d]", framework.MaxNodeScore)))
+	}
+
+	if args.UsageTrendWeight < 0 || args.UsageTrendWeight > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("usageTrendWeight"), args.UsageTrendWeight,
+			fmt.Sprintf("usageTrendWeight should be in the range [0, %d]", framework.MaxNodeScore)))
+	}
+
+	if args.ThresholdProximityPenalty < 0 || args.ThresholdProximityPenalty > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("thresholdProximityPenalty"), args.ThresholdProximityPenalty,
+			fmt.Sprintf("thresholdProximityPenalty should be in the range [0, %d]", framework.MaxNodeScore)))
+	}
+
+	if args.ScoreScalingPercentage < 0 || args.ScoreScalingPercentage > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scoreScalingPercentage"), args.ScoreScalingPercentage,
+			"scoreScalingPercentage should be in the range [0, 100]"))
+	}
+
+	if args.ScoreSafetyBufferPercent < 0 || args.ScoreSafetyBufferPercent > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scoreSafetyBufferPercent"), args.ScoreSafetyBufferPercent,
+			"scoreSafetyBufferPercent should be in the range [0, 100]"))
+	}
+
+	if args.ReclaimableUsageWeight != nil && (*args.ReclaimableUsageWeight < 0 || *args.ReclaimableUsageWeight > 100) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("reclaimableUsageWeight"), *args.ReclaimableUsageWeight,
+			"reclaimableUsageWeight should be in the range [0, 100]"))
+	}
+	if args.AllNodeMetricsExpiredRequeueSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("allNodeMetricsExpiredRequeueSeconds"), args.AllNodeMetricsExpiredRequeueSeconds,
+			"allNodeMetricsExpiredRequeueSeconds should be a positive value"))
+	}
+	if args.ThresholdRelaxationPercentPerAttempt < 0 || args.ThresholdRelaxationPercentPerAttempt > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("thresholdRelaxationPercentPerAttempt"), args.ThresholdRelaxationPercentPerAttempt,
+			"thresholdRelaxationPercentPerAttempt should be in the range [0, 100]"))
+	}
+	if args.MaxThresholdRelaxationPercent < 0 || args.MaxThresholdRelaxationPercent > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("maxThresholdRelaxationPercent"), args.MaxThresholdRelaxationPercent,
+			"maxThresholdRelaxationPercent should be in the range [0, 100]"))
+	} else if args.ThresholdRelaxationPercentPerAttempt > 0 && args.MaxThresholdRelaxationPercent == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("maxThresholdRelaxationPercent"),
+			"maxThresholdRelaxationPercent is required when thresholdRelaxationPercentPerAttempt is set"))
+	}
+	if args.FreeCoresScoreWeight < 0 || args.FreeCoresScoreWeight > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("freeCoresScoreWeight"), args.FreeCoresScoreWeight,
+			"freeCoresScoreWeight should be in the range [0, 100]"))
+	}
+	if args.MaxScoreFreeCores < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("maxScoreFreeCores"), args.MaxScoreFreeCores, "maxScoreFreeCores should be a positive value"))
+	} else if args.FreeCoresScoreWeight > 0 && args.MaxScoreFreeCores == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("maxScoreFreeCores"), "maxScoreFreeCores is required when freeCoresScoreWeight is set"))
+	}
+
+	if args.PodDensityWeight < 0 || args.PodDensityWeight > 100 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("podDensityWeight"), args.PodDensityWeight,
+			"podDensityWeight should be in the range [0, 100]"))
+	}
+
+	if args.NodePoolLabelKey != "" {
+		for _, msg := range validation.IsQualifiedName(args.NodePoolLabelKey) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("nodePoolLabelKey"), args.NodePoolLabelKey, msg))
+		}
+	}
+	if args.NodeMetricShardLabelKey != "" {
+		for _, msg := range validation.IsQualifiedName(args.NodeMetricShardLabelKey) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("nodeMetricShardLabelKey"), args.NodeMetricShardLabelKey, msg))
+		}
+	}
+	if args.ExemptNodeLabelKey != "" {
+		for _, msg := range validation.IsQualifiedName(args.ExemptNodeLabelKey) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("exemptNodeLabelKey"), args.ExemptNodeLabelKey, msg))
+		}
+	}
+
+	if args.DynamicArgsConfigMapName != "" && args.DynamicArgsConfigMapNamespace == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("dynamicArgsConfigMapNamespace"), "dynamicArgsConfigMapNamespace is required when dynamicArgsConfigMapName is set"))
+	}
+
+	switch args.FilterUsageSource {
+	case "", config.NodeUsageSourceNodeUsage, config.NodeUsageSourcePodsUsage:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("filterUsageSource"), args.FilterUsageSource,
+			[]string{string(config.NodeUsageSourceNodeUsage), string(config.NodeUsageSourcePodsUsage)}))
+	}
+
+	switch args.FilterUsageType {
+	case "", config.LoadAwareUsageTypeTotal, config.LoadAwareUsageTypeProd:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("filterUsageType"), args.FilterUsageType,
+			[]string{string(config.LoadAwareUsageTypeTotal), string(config.LoadAwareUsageTypeProd)}))
+	}
+	switch args.ScoreUsageType {
+	case "", config.LoadAwareUsageTypeTotal, config.LoadAwareUsageTypeProd:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("scoreUsageType"), args.ScoreUsageType,
+			[]string{string(config.LoadAwareUsageTypeTotal), string(config.LoadAwareUsageTypeProd)}))
+	}
+	if args.ScoreUsageType == config.LoadAwareUsageTypeTotal && args.ScoreAccordingProdUsage {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scoreUsageType"), args.ScoreUsageType, "scoreUsageType Total conflicts with scoreAccordingProdUsage"))
+	}
+	switch args.ScoreResourceBase {
+	case "", config.LoadAwareResourceBaseAllocatable, config.LoadAwareResourceBaseCapacity:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("scoreResourceBase"), args.ScoreResourceBase,
+			[]string{string(config.LoadAwareResourceBaseAllocatable), string(config.LoadAwareResourceBaseCapacity)}))
+	}
+	if args.ScoreResourceBase == config.LoadAwareResourceBaseCapacity && args.ScoreAccordingNodeReservation {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scoreResourceBase"), args.ScoreResourceBase, "scoreResourceBase Capacity conflicts with scoreAccordingNodeReservation"))
+	}
+
+	switch args.ScoringStrategy {
+	case "", config.LoadAwareScoringStrategyLeastUsage, config.LoadAwareScoringStrategyBestFit, config.LoadAwareScoringStrategyProportionalHeadroom:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("scoringStrategy"), args.ScoringStrategy,
+			[]string{string(config.LoadAwareScoringStrategyLeastUsage), string(config.LoadAwareScoringStrategyBestFit),
+				string(config.LoadAwareScoringStrategyProportionalHeadroom)}))
+	}
+	switch args.EstimatedOverflowPolicy {
+	case "", config.LoadAwareEstimatedOverflowScoreZero, config.LoadAwareEstimatedOverflowClampToAllocatable:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("estimatedOverflowPolicy"), args.EstimatedOverflowPolicy,
+			[]string{string(config.LoadAwareEstimatedOverflowScoreZero), string(config.LoadAwareEstimatedOverflowClampToAllocatable)}))
+	}
+	switch args.EstimateMode {
+	case "", config.LoadAwareEstimateModeDefault, config.LoadAwareEstimateModeUseLimits:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("estimateMode"), args.EstimateMode,
+			[]string{string(config.LoadAwareEstimateModeDefault), string(config.LoadAwareEstimateModeUseLimits)}))
+	}
+	for i, sidecarOverhead := range args.SidecarOverheads {
+		path := field.NewPath("sidecarOverheads").Index(i)
+		if sidecarOverhead.NamespaceSelector == nil {
+			allErrs = append(allErrs, field.Required(path.Child("namespaceSelector"), "namespaceSelector is required"))
+		} else if _, err := metav1.LabelSelectorAsSelector(sidecarOverhead.NamespaceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("namespaceSelector"), sidecarOverhead.NamespaceSelector, err.Error()))
+		}
+		for resourceName, quantity := range sidecarOverhead.Overhead {
+			if quantity.Sign() < 0 {
+				allErrs = append(allErrs, field.Invalid(path.Child("overhead").Key(string(resourceName)), quantity.String(), "overhead should not be negative"))
+			}
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -93,6 +346,55 @@ func validateEstimatedResourceThresholds(thresholds map[corev1.ResourceName]int6
 	return nil
 }
 
+// ValidateBatchResourceFitArgs validates that BatchResourceFitArgs are correct.
+func ValidateBatchResourceFitArgs(args *config.BatchResourceFitArgs) error {
+	var allErrs field.ErrorList
+
+	supportedResources := []string{string(apiext.BatchCPU), string(apiext.BatchMemory)}
+	for resourceName, ratio := range args.OvercommitRatios {
+		if resourceName != apiext.BatchCPU && resourceName != apiext.BatchMemory {
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("overcommitRatios"), resourceName, supportedResources))
+			continue
+		}
+		if ratio <= 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("overcommitRatios").Key(string(resourceName)), ratio, "overcommit ratio should be a positive value"))
+		}
+	}
+
+	for resourceName := range args.ResourceWeights {
+		if resourceName != apiext.BatchCPU && resourceName != apiext.BatchMemory {
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("resourceWeights"), resourceName, supportedResources))
+		}
+	}
+	if err := validateResourceWeights(args.ResourceWeights); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("resourceWeights"), args.ResourceWeights, err.Error()))
+	}
+
+	if args.EvictionCountWeight < 0 || args.EvictionCountWeight > framework.MaxNodeScore {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("evictionCountWeight"), args.EvictionCountWeight,
+			fmt.Sprintf("evictionCountWeight should be in the range [0, %d]", framework.MaxNodeScore)))
+	}
+
+	switch args.ScoringStrategy {
+	case "", config.BatchResourceLeastAllocated, config.BatchResourceMostAllocated:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("scoringStrategy"), args.ScoringStrategy,
+			[]string{string(config.BatchResourceLeastAllocated), string(config.BatchResourceMostAllocated)}))
+	}
+
+	switch args.InitContainerMode {
+	case "", config.BatchInitContainerLegacyMax, config.BatchInitContainerSidecarAware:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("initContainerMode"), args.InitContainerMode,
+			[]string{string(config.BatchInitContainerLegacyMax), string(config.BatchInitContainerSidecarAware)}))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
+
 func ValidateElasticQuotaArgs(elasticArgs *config.ElasticQuotaArgs) error {
 	for resName, q := range elasticArgs.DefaultQuotaGroupMax {
 		if q.Cmp(*resource.NewQuantity(0, resource.DecimalSI)) == -1 {
@@ -22,19 +22,61 @@ limitations under the License.
 package config
 
 import (
-	corev1 "k8s.io/api/core/v1"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	apisconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+
+	extension "github.com/koordinator-sh/koordinator/apis/extension"
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchResourceFitArgs) DeepCopyInto(out *BatchResourceFitArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.OvercommitRatios != nil {
+		in, out := &in.OvercommitRatios, &out.OvercommitRatios
+		*out = make(map[v1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceWeights != nil {
+		in, out := &in.ResourceWeights, &out.ResourceWeights
+		*out = make(map[v1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchResourceFitArgs.
+func (in *BatchResourceFitArgs) DeepCopy() *BatchResourceFitArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchResourceFitArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BatchResourceFitArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	if in.DefaultTimeout != nil {
 		in, out := &in.DefaultTimeout, &out.DefaultTimeout
-		*out = new(v1.Duration)
+		*out = new(metav1.Duration)
 		**out = **in
 	}
 	if in.ControllerWorkers != nil {
@@ -94,24 +136,24 @@ func (in *ElasticQuotaArgs) DeepCopyInto(out *ElasticQuotaArgs) {
 	out.TypeMeta = in.TypeMeta
 	if in.DelayEvictTime != nil {
 		in, out := &in.DelayEvictTime, &out.DelayEvictTime
-		*out = new(v1.Duration)
+		*out = new(metav1.Duration)
 		**out = **in
 	}
 	if in.RevokePodInterval != nil {
 		in, out := &in.RevokePodInterval, &out.RevokePodInterval
-		*out = new(v1.Duration)
+		*out = new(metav1.Duration)
 		**out = **in
 	}
 	if in.DefaultQuotaGroupMax != nil {
 		in, out := &in.DefaultQuotaGroupMax, &out.DefaultQuotaGroupMax
-		*out = make(corev1.ResourceList, len(*in))
+		*out = make(v1.ResourceList, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
 	if in.SystemQuotaGroupMax != nil {
 		in, out := &in.SystemQuotaGroupMax, &out.SystemQuotaGroupMax
-		*out = make(corev1.ResourceList, len(*in))
+		*out = make(v1.ResourceList, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val.DeepCopy()
 		}
@@ -152,13 +194,20 @@ func (in *LoadAwareSchedulingAggregatedArgs) DeepCopyInto(out *LoadAwareScheduli
 	*out = *in
 	if in.UsageThresholds != nil {
 		in, out := &in.UsageThresholds, &out.UsageThresholds
-		*out = make(map[corev1.ResourceName]int64, len(*in))
+		*out = make(map[v1.ResourceName]int64, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
 	out.UsageAggregatedDuration = in.UsageAggregatedDuration
 	out.ScoreAggregatedDuration = in.ScoreAggregatedDuration
+	if in.ResourceAggregationTypes != nil {
+		in, out := &in.ResourceAggregationTypes, &out.ResourceAggregationTypes
+		*out = make(map[v1.ResourceName]v1alpha1.AggregationType, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -186,39 +235,108 @@ func (in *LoadAwareSchedulingArgs) DeepCopyInto(out *LoadAwareSchedulingArgs) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.DefaultNodeMetricReportIntervalSeconds != nil {
+		in, out := &in.DefaultNodeMetricReportIntervalSeconds, &out.DefaultNodeMetricReportIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	out.NodeMetricTimeout = in.NodeMetricTimeout
 	if in.ResourceWeights != nil {
 		in, out := &in.ResourceWeights, &out.ResourceWeights
-		*out = make(map[corev1.ResourceName]int64, len(*in))
+		*out = make(map[v1.ResourceName]int64, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.PriorityClassResourceWeights != nil {
+		in, out := &in.PriorityClassResourceWeights, &out.PriorityClassResourceWeights
+		*out = make(map[extension.PriorityClass]map[v1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			var outVal map[v1.ResourceName]int64
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(map[v1.ResourceName]int64, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.UsageThresholds != nil {
 		in, out := &in.UsageThresholds, &out.UsageThresholds
-		*out = make(map[corev1.ResourceName]int64, len(*in))
+		*out = make(map[v1.ResourceName]int64, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
 	if in.ProdUsageThresholds != nil {
 		in, out := &in.ProdUsageThresholds, &out.ProdUsageThresholds
-		*out = make(map[corev1.ResourceName]int64, len(*in))
+		*out = make(map[v1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MandatoryThresholds != nil {
+		in, out := &in.MandatoryThresholds, &out.MandatoryThresholds
+		*out = make(map[v1.ResourceName]int64, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
 	if in.EstimatedScalingFactors != nil {
 		in, out := &in.EstimatedScalingFactors, &out.EstimatedScalingFactors
-		*out = make(map[corev1.ResourceName]int64, len(*in))
+		*out = make(map[v1.ResourceName]int64, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.QoSEstimatedScalingFactors != nil {
+		in, out := &in.QoSEstimatedScalingFactors, &out.QoSEstimatedScalingFactors
+		*out = make(map[v1.PodQOSClass]map[v1.ResourceName]int64, len(*in))
+		for key, val := range *in {
+			var outVal map[v1.ResourceName]int64
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(map[v1.ResourceName]int64, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Aggregated != nil {
 		in, out := &in.Aggregated, &out.Aggregated
 		*out = new(LoadAwareSchedulingAggregatedArgs)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CriticalResources != nil {
+		in, out := &in.CriticalResources, &out.CriticalResources
+		*out = make([]v1.ResourceName, len(*in))
+		copy(*out, *in)
+	}
+	if in.SidecarOverheads != nil {
+		in, out := &in.SidecarOverheads, &out.SidecarOverheads
+		*out = make([]LoadAwareSidecarOverhead, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReclaimableUsageWeight != nil {
+		in, out := &in.ReclaimableUsageWeight, &out.ReclaimableUsageWeight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.EstimatedPodMaxPriority != nil {
+		in, out := &in.EstimatedPodMaxPriority, &out.EstimatedPodMaxPriority
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -240,6 +358,34 @@ func (in *LoadAwareSchedulingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadAwareSidecarOverhead) DeepCopyInto(out *LoadAwareSidecarOverhead) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overhead != nil {
+		in, out := &in.Overhead, &out.Overhead
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadAwareSidecarOverhead.
+func (in *LoadAwareSidecarOverhead) DeepCopy() *LoadAwareSidecarOverhead {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadAwareSidecarOverhead)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNUMAResourceArgs) DeepCopyInto(out *NodeNUMAResourceArgs) {
 	*out = *in
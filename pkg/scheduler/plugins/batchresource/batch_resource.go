@@ -18,13 +18,22 @@ package batchresource
 
 import (
 	"context"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	resschedplug "k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 )
 
 const (
@@ -38,13 +47,53 @@ type batchResource struct {
 
 var (
 	_ framework.FilterPlugin = &Plugin{}
+	_ framework.ScorePlugin  = &Plugin{}
 )
 
 type Plugin struct {
+	handle framework.Handle
+	args   *config.BatchResourceFitArgs
+	// nodeMetricLister is nil if the handle does not support the koordinator informers,
+	// and then the batch allocatable is only annotated on the nodes.
+	nodeMetricLister slolisters.NodeMetricLister
 }
 
 func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
-	return &Plugin{}, nil
+	if args == nil {
+		// the plugin is enabled without the pluginConfig, e.g. in the shipped scheduler config.
+		defaultArgs, err := getDefaultBatchResourceFitArgs()
+		if err != nil {
+			return nil, err
+		}
+		args = defaultArgs
+	}
+	pluginArgs, ok := args.(*config.BatchResourceFitArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type BatchResourceFitArgs, got %T", args)
+	}
+	if err := validation.ValidateBatchResourceFitArgs(pluginArgs); err != nil {
+		return nil, err
+	}
+	var nodeMetricLister slolisters.NodeMetricLister
+	if extendedHandle, ok := handle.(frameworkext.ExtendedHandle); ok {
+		nodeMetricLister = extendedHandle.KoordinatorSharedInformerFactory().Slo().V1alpha1().NodeMetrics().Lister()
+	}
+	return &Plugin{
+		handle:           handle,
+		args:             pluginArgs,
+		nodeMetricLister: nodeMetricLister,
+	}, nil
+}
+
+func getDefaultBatchResourceFitArgs() (*config.BatchResourceFitArgs, error) {
+	var v1beta2args v1beta2.BatchResourceFitArgs
+	v1beta2.SetDefaults_BatchResourceFitArgs(&v1beta2args)
+	var defaultArgs config.BatchResourceFitArgs
+	err := v1beta2.Convert_v1beta2_BatchResourceFitArgs_To_config_BatchResourceFitArgs(&v1beta2args, &defaultArgs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &defaultArgs, nil
 }
 
 func (p *Plugin) Name() string {
@@ -52,7 +101,7 @@ func (p *Plugin) Name() string {
 }
 
 func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
-	insufficientResources := fitsRequest(pod, nodeInfo)
+	insufficientResources := fitsRequest(pod, nodeInfo, p.args, p.nodeMetricLister)
 
 	if len(insufficientResources) != 0 {
 		// We will keep all failure reasons.
@@ -65,15 +114,108 @@ func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *c
 	return nil
 }
 
-func fitsRequest(pod *corev1.Pod, nodeInfo *framework.NodeInfo) []resschedplug.InsufficientResource {
-	podBatchRequest := computePodBatchRequest(pod)
+func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	podBatchRequest := computePodBatchRequest(pod, p.args.InitContainerMode)
+	if podBatchRequest.MilliCPU == 0 && podBatchRequest.Memory == 0 {
+		return framework.MaxNodeScore, nil
+	}
+
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return 0, framework.NewStatus(framework.Error, "node not found")
+	}
+
+	score := allocationScore(podBatchRequest, nodeInfo, p.args, p.nodeMetricLister)
+	if p.args.EvictionCountWeight > 0 {
+		score -= evictionCountPenalty(node, p.args.EvictionCountWeight)
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score, nil
+}
+
+func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// allocationScore scores the node by the batch requests after placing the Pod with the ScoringStrategy,
+// weighted by the ResourceWeights. The node scores MaxNodeScore if no resource weights are configured.
+func allocationScore(podBatchRequest *batchResource, nodeInfo *framework.NodeInfo, args *config.BatchResourceFitArgs, nodeMetricLister slolisters.NodeMetricLister) int64 {
+	if len(args.ResourceWeights) == 0 {
+		return framework.MaxNodeScore
+	}
+	nodeRequested := computeNodeBatchRequested(nodeInfo)
+	nodeAllocatable := computeNodeBatchAllocatableWithOvercommit(nodeInfo, args, nodeMetricLister)
+	requested := map[corev1.ResourceName]int64{
+		apiext.BatchCPU:    nodeRequested.MilliCPU + podBatchRequest.MilliCPU,
+		apiext.BatchMemory: nodeRequested.Memory + podBatchRequest.Memory,
+	}
+	allocatable := map[corev1.ResourceName]int64{
+		apiext.BatchCPU:    nodeAllocatable.MilliCPU,
+		apiext.BatchMemory: nodeAllocatable.Memory,
+	}
+
+	var nodeScore, weightSum int64
+	for resourceName, weight := range args.ResourceWeights {
+		var resourceScore int64
+		if args.ScoringStrategy == config.BatchResourceMostAllocated {
+			resourceScore = mostAllocatedScore(requested[resourceName], allocatable[resourceName])
+		} else {
+			resourceScore = leastAllocatedScore(requested[resourceName], allocatable[resourceName])
+		}
+		nodeScore += resourceScore * weight
+		weightSum += weight
+	}
+	return nodeScore / weightSum
+}
+
+func leastAllocatedScore(requested, capacity int64) int64 {
+	if capacity == 0 || requested > capacity {
+		return 0
+	}
+	return (capacity - requested) * framework.MaxNodeScore / capacity
+}
+
+func mostAllocatedScore(requested, capacity int64) int64 {
+	if capacity == 0 || requested > capacity {
+		return 0
+	}
+	return requested * framework.MaxNodeScore / capacity
+}
+
+// evictionCountPenalty returns the weight multiplied by the number of batch pods recently evicted from the node,
+// which is capped at the max score.
+func evictionCountPenalty(node *corev1.Node, weight int64) int64 {
+	evictionCount, err := apiext.GetNodeBatchEvictionCount(node.Annotations)
+	if err != nil {
+		klog.V(5).InfoS("failed to get batch eviction count of node", "node", node.Name, "err", err)
+		return 0
+	}
+	// the weight is positive, so the penalty must be capped if the count reaches the max score.
+	if evictionCount >= framework.MaxNodeScore {
+		return framework.MaxNodeScore
+	}
+	penalty := evictionCount * weight
+	if penalty > framework.MaxNodeScore {
+		penalty = framework.MaxNodeScore
+	}
+	return penalty
+}
+
+func fitsRequest(pod *corev1.Pod, nodeInfo *framework.NodeInfo, args *config.BatchResourceFitArgs, nodeMetricLister slolisters.NodeMetricLister) []resschedplug.InsufficientResource {
+	podBatchRequest := computePodBatchRequest(pod, args.InitContainerMode)
 	if podBatchRequest.MilliCPU == 0 && podBatchRequest.Memory == 0 {
 		return nil
 	}
 
 	insufficientResources := make([]resschedplug.InsufficientResource, 0, 2)
 	nodeRequested := computeNodeBatchRequested(nodeInfo)
-	nodeAllocatable := computeNodeBatchAllocatable(nodeInfo)
+	nodeAllocatable := computeNodeBatchAllocatableWithOvercommit(nodeInfo, args, nodeMetricLister)
 	if podBatchRequest.MilliCPU > (nodeAllocatable.MilliCPU - nodeRequested.MilliCPU) {
 		insufficientResources = append(insufficientResources, resschedplug.InsufficientResource{
 			ResourceName: apiext.BatchCPU,
@@ -95,29 +237,109 @@ func fitsRequest(pod *corev1.Pod, nodeInfo *framework.NodeInfo) []resschedplug.I
 	return insufficientResources
 }
 
-func computeNodeBatchAllocatable(nodeInfo *framework.NodeInfo) *batchResource {
+// ComputeClusterBatchFree returns the total free batch resources of the nodes for capacity planning,
+// which sums the batch allocatable minus the batch requested of each node. The batch allocatable is
+// computed as Filter does, including the annotated allocatable and the OvercommitRatios. The overcommitted
+// nodes are regarded as no free resources rather than offsetting the free resources of the other nodes.
+func (p *Plugin) ComputeClusterBatchFree(nodeInfos []*framework.NodeInfo) corev1.ResourceList {
+	free := &batchResource{}
+	for _, nodeInfo := range nodeInfos {
+		nodeAllocatable := computeNodeBatchAllocatableWithOvercommit(nodeInfo, p.args, p.nodeMetricLister)
+		nodeRequested := computeNodeBatchRequested(nodeInfo)
+		if nodeAllocatable.MilliCPU > nodeRequested.MilliCPU {
+			free.MilliCPU += nodeAllocatable.MilliCPU - nodeRequested.MilliCPU
+		}
+		if nodeAllocatable.Memory > nodeRequested.Memory {
+			free.Memory += nodeAllocatable.Memory - nodeRequested.Memory
+		}
+	}
+	return corev1.ResourceList{
+		apiext.BatchCPU:    *resource.NewQuantity(free.MilliCPU, resource.DecimalSI),
+		apiext.BatchMemory: *resource.NewQuantity(free.Memory, resource.BinarySI),
+	}
+}
+
+// computeNodeBatchAllocatable returns the batch allocatable in the extended resources of the node.
+// The batch resources absent in the extended resources fall back to the AnnotationNodeBatchAllocatable
+// of the NodeMetric, and then of the node, because some clusters report the batch allocatable there.
+func computeNodeBatchAllocatable(nodeInfo *framework.NodeInfo, nodeMetricLister slolisters.NodeMetricLister) *batchResource {
 	nodeAllocatable := &batchResource{
 		MilliCPU: 0,
 		Memory:   0,
 	}
+	var cpuExist, memoryExist bool
 	// compatible with old format, overwrite BatchCPU, BatchMemory if exist
 	// nolint:staticcheck // SA1019: apiext.KoordBatchCPU is deprecated: because of the limitation of extended resource naming
 	if koordBatchCPU, exist := nodeInfo.Allocatable.ScalarResources[apiext.KoordBatchCPU]; exist {
-		nodeAllocatable.MilliCPU = koordBatchCPU
+		nodeAllocatable.MilliCPU, cpuExist = koordBatchCPU, true
 	}
 	// nolint:staticcheck // SA1019: apiext.KoordBatchMemory is deprecated: because of the limitation of extended resource naming
 	if koordBatchMemory, exist := nodeInfo.Allocatable.ScalarResources[apiext.KoordBatchMemory]; exist {
-		nodeAllocatable.Memory = koordBatchMemory
+		nodeAllocatable.Memory, memoryExist = koordBatchMemory, true
 	}
 	if batchCPU, exist := nodeInfo.Allocatable.ScalarResources[apiext.BatchCPU]; exist {
-		nodeAllocatable.MilliCPU = batchCPU
+		nodeAllocatable.MilliCPU, cpuExist = batchCPU, true
 	}
 	if batchMemory, exist := nodeInfo.Allocatable.ScalarResources[apiext.BatchMemory]; exist {
-		nodeAllocatable.Memory = batchMemory
+		nodeAllocatable.Memory, memoryExist = batchMemory, true
+	}
+	if cpuExist && memoryExist {
+		return nodeAllocatable
+	}
+
+	annotatedAllocatable := getAnnotatedBatchAllocatable(nodeInfo.Node(), nodeMetricLister)
+	if q, ok := annotatedAllocatable[apiext.BatchCPU]; ok && !cpuExist {
+		nodeAllocatable.MilliCPU = q.Value()
+	}
+	if q, ok := annotatedAllocatable[apiext.BatchMemory]; ok && !memoryExist {
+		nodeAllocatable.Memory = q.Value()
 	}
 	return nodeAllocatable
 }
 
+// getAnnotatedBatchAllocatable returns the batch allocatable annotated on the NodeMetric of the node,
+// or on the node if the NodeMetric is not found or not annotated. The invalid annotations are ignored.
+func getAnnotatedBatchAllocatable(node *corev1.Node, nodeMetricLister slolisters.NodeMetricLister) corev1.ResourceList {
+	if node == nil {
+		return nil
+	}
+	if nodeMetricLister != nil {
+		if nodeMetric, err := nodeMetricLister.Get(node.Name); err == nil {
+			allocatable, err := apiext.GetNodeBatchAllocatable(nodeMetric.Annotations)
+			if err != nil {
+				klog.V(5).InfoS("failed to get batch allocatable of nodeMetric", "node", node.Name, "err", err)
+			} else if allocatable != nil {
+				return allocatable
+			}
+		}
+	}
+	allocatable, err := apiext.GetNodeBatchAllocatable(node.Annotations)
+	if err != nil {
+		klog.V(5).InfoS("failed to get batch allocatable of node", "node", node.Name, "err", err)
+		return nil
+	}
+	return allocatable
+}
+
+// computeNodeBatchAllocatableWithOvercommit returns the batch allocatable scaled by the OvercommitRatios.
+func computeNodeBatchAllocatableWithOvercommit(nodeInfo *framework.NodeInfo, args *config.BatchResourceFitArgs, nodeMetricLister slolisters.NodeMetricLister) *batchResource {
+	nodeAllocatable := computeNodeBatchAllocatable(nodeInfo, nodeMetricLister)
+	if ratio, ok := args.OvercommitRatios[apiext.BatchCPU]; ok {
+		nodeAllocatable.MilliCPU = overcommit(nodeAllocatable.MilliCPU, ratio)
+	}
+	if ratio, ok := args.OvercommitRatios[apiext.BatchMemory]; ok {
+		nodeAllocatable.Memory = overcommit(nodeAllocatable.Memory, ratio)
+	}
+	return nodeAllocatable
+}
+
+// overcommit returns the allocatable in milli-cores or bytes scaled by the ratio in percentage, rounded down.
+// It is computed in integers without multiplying the allocatable by the ratio first, which may overflow for
+// the memory in bytes, so that the Pod requesting exactly the overcommitted allocatable always fits.
+func overcommit(allocatable, ratio int64) int64 {
+	return allocatable/100*ratio + allocatable%100*ratio/100
+}
+
 func computeNodeBatchRequested(nodeInfo *framework.NodeInfo) *batchResource {
 	nodeRequested := &batchResource{
 		MilliCPU: 0,
@@ -145,15 +367,20 @@ func computeNodeBatchRequested(nodeInfo *framework.NodeInfo) *batchResource {
 // computePodBERequest returns the total non-zero best-effort requests. If Overhead is defined for the pod and
 // the PodOverhead feature is enabled, the Overhead is added to the result.
 // podBERequest = max(sum(podSpec.Containers), podSpec.InitContainers) + overHead
-func computePodBatchRequest(pod *corev1.Pod) *batchResource {
+// The init containers are taken by the InitContainerMode, see addInitContainerRequests,
+// and are skipped if the pod opts out by the AnnotationBatchIgnoreInitContainers.
+// The ephemeral containers are never counted, because they are only for debugging and get no guaranteed resources.
+func computePodBatchRequest(pod *corev1.Pod, mode config.BatchInitContainerMode) *batchResource {
+	ignoreInitContainers := isBatchInitContainersIgnored(pod)
 	podRequest := &framework.Resource{}
 	for _, container := range pod.Spec.Containers {
 		podRequest.Add(container.Resources.Requests)
 	}
 
-	// take max_resource(sum_pod, any_init_container)
-	for _, container := range pod.Spec.InitContainers {
-		podRequest.SetMaxResource(container.Resources.Requests)
+	if !ignoreInitContainers {
+		addInitContainerRequests(podRequest, pod, mode, func(container *corev1.Container) corev1.ResourceList {
+			return container.Resources.Requests
+		})
 	}
 
 	// If Overhead is being utilized, add to the total requests for the pod
@@ -161,6 +388,80 @@ func computePodBatchRequest(pod *corev1.Pod) *batchResource {
 		podRequest.Add(pod.Spec.Overhead)
 	}
 
+	result := newBatchResource(podRequest)
+	if result.MilliCPU == 0 && result.Memory == 0 {
+		// the batch resources may be declared in the annotation rather than the container spec
+		return computePodBatchRequestFromAnnotation(pod, mode, ignoreInitContainers)
+	}
+	return result
+}
+
+// computePodBatchRequestFromAnnotation returns the batch requests declared in the ExtendedResourceSpec annotation.
+// podBERequest = max(sum(annotation.Containers), annotation.InitContainers)
+func computePodBatchRequestFromAnnotation(pod *corev1.Pod, mode config.BatchInitContainerMode, ignoreInitContainers bool) *batchResource {
+	spec, err := apiext.GetExtendedResourceSpec(pod.Annotations)
+	if err != nil {
+		klog.V(5).InfoS("failed to get extended resource spec of pod", "pod", klog.KObj(pod), "err", err)
+		return &batchResource{}
+	}
+	podRequest := &framework.Resource{}
+	for _, container := range pod.Spec.Containers {
+		if containerSpec, ok := spec.Containers[container.Name]; ok {
+			podRequest.Add(containerSpec.Requests)
+		}
+	}
+	if !ignoreInitContainers {
+		addInitContainerRequests(podRequest, pod, mode, func(container *corev1.Container) corev1.ResourceList {
+			return spec.Containers[container.Name].Requests
+		})
+	}
+	return newBatchResource(podRequest)
+}
+
+// addInitContainerRequests takes the init containers into the requests of the containers by the InitContainerMode.
+// LegacyMax takes max(podRequest, any init container). SidecarAware follows the sidecar containers since kube 1.28:
+// the sidecars are added to the podRequest since they keep running along with the containers, and each init container
+// is counted along with the sidecars started before it.
+func addInitContainerRequests(podRequest *framework.Resource, pod *corev1.Pod, mode config.BatchInitContainerMode,
+	requestsOf func(container *corev1.Container) corev1.ResourceList) {
+	if mode != config.BatchInitContainerSidecarAware {
+		// take max_resource(sum_pod, any_init_container)
+		for i := range pod.Spec.InitContainers {
+			podRequest.SetMaxResource(requestsOf(&pod.Spec.InitContainers[i]))
+		}
+		return
+	}
+
+	sidecars := apiext.GetSidecarInitContainers(pod)
+	sidecarRequests := corev1.ResourceList{}
+	initRequests := corev1.ResourceList{}
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		requests := requestsOf(container)
+		if sidecars.Has(container.Name) {
+			podRequest.Add(requests)
+			sidecarRequests = quotav1.Add(sidecarRequests, requests)
+			requests = sidecarRequests
+		} else {
+			requests = quotav1.Add(requests, sidecarRequests)
+		}
+		initRequests = quotav1.Max(initRequests, requests)
+	}
+	podRequest.SetMaxResource(initRequests)
+}
+
+// isBatchInitContainersIgnored returns false if the AnnotationBatchIgnoreInitContainers is invalid,
+// so that the init containers are still counted, which never underestimates the batch requests.
+func isBatchInitContainersIgnored(pod *corev1.Pod) bool {
+	ignored, err := apiext.IsBatchInitContainersIgnored(pod)
+	if err != nil {
+		klog.V(5).InfoS("failed to parse the annotation of pod, count the init containers", "pod", klog.KObj(pod), "err", err)
+		return false
+	}
+	return ignored
+}
+
+func newBatchResource(podRequest *framework.Resource) *batchResource {
 	result := &batchResource{
 		MilliCPU: 0,
 		Memory:   0,
@@ -0,0 +1,184 @@
+//go:build go1.18
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchresource
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+const (
+	fuzzNamingBatch uint8 = iota
+	fuzzNamingKoordBatch
+	fuzzNamingMixed
+)
+
+// newFuzzBatchRes returns the batch resources with the new or the deprecated names, zero values are omitted.
+func newFuzzBatchRes(milliCPU, memory int64, koord bool) corev1.ResourceList {
+	cpuName, memoryName := apiext.BatchCPU, apiext.BatchMemory
+	if koord {
+		// nolint:staticcheck // SA1019: apiext.KoordBatchCPU is deprecated: because of the limitation of extended resource naming
+		cpuName, memoryName = apiext.KoordBatchCPU, apiext.KoordBatchMemory
+	}
+	resources := corev1.ResourceList{}
+	if milliCPU > 0 {
+		resources[cpuName] = *resource.NewQuantity(milliCPU, resource.DecimalSI)
+	}
+	if memory > 0 {
+		resources[memoryName] = *resource.NewQuantity(memory, resource.BinarySI)
+	}
+	return resources
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func FuzzComputePodBatchRequest(f *testing.F) {
+	// empty pod
+	f.Add(uint8(fuzzNamingBatch), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0))
+	// containers only
+	f.Add(uint8(fuzzNamingBatch), uint32(1000), uint32(1024), uint32(500), uint32(512), uint32(0), uint32(0), uint32(0), uint32(0))
+	// init container dominates
+	f.Add(uint8(fuzzNamingBatch), uint32(1000), uint32(1024), uint32(500), uint32(512), uint32(4000), uint32(4096), uint32(0), uint32(0))
+	// overhead is added after taking the max of init containers
+	f.Add(uint8(fuzzNamingKoordBatch), uint32(1000), uint32(1024), uint32(0), uint32(0), uint32(2000), uint32(1024), uint32(100), uint32(128))
+	// deprecated names in containers and new names in init containers
+	f.Add(uint8(fuzzNamingMixed), uint32(1000), uint32(1024), uint32(1000), uint32(1024), uint32(4000), uint32(4096), uint32(0), uint32(0))
+
+	f.Fuzz(func(t *testing.T, naming uint8, cpuA, memoryA, cpuB, memoryB, initCPU, initMemory, overheadCPU, overheadMemory uint32) {
+		naming = naming % 3
+		containerKoord, initKoord := naming == fuzzNamingKoordBatch, naming == fuzzNamingKoordBatch
+		if naming == fuzzNamingMixed {
+			containerKoord = true
+		}
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "a", Resources: corev1.ResourceRequirements{Requests: newFuzzBatchRes(int64(cpuA), int64(memoryA), containerKoord)}},
+					{Name: "b", Resources: corev1.ResourceRequirements{Requests: newFuzzBatchRes(int64(cpuB), int64(memoryB), containerKoord)}},
+				},
+				InitContainers: []corev1.Container{
+					{Name: "init", Resources: corev1.ResourceRequirements{Requests: newFuzzBatchRes(int64(initCPU), int64(initMemory), initKoord)}},
+				},
+			},
+		}
+		if overheadCPU > 0 || overheadMemory > 0 {
+			pod.Spec.Overhead = newFuzzBatchRes(int64(overheadCPU), int64(overheadMemory), containerKoord)
+		}
+
+		got := computePodBatchRequest(pod, config.BatchInitContainerSidecarAware)
+		if got.MilliCPU < 0 || got.Memory < 0 {
+			t.Fatalf("computePodBatchRequest() = %+v, want non-negative", got)
+		}
+		if naming == fuzzNamingMixed {
+			return
+		}
+
+		// podBatchRequest = max(sum(containers), initContainers) + overhead
+		wantCPU := maxInt64(int64(cpuA)+int64(cpuB), int64(initCPU)) + int64(overheadCPU)
+		wantMemory := maxInt64(int64(memoryA)+int64(memoryB), int64(initMemory)) + int64(overheadMemory)
+		if got.MilliCPU != wantCPU || got.Memory != wantMemory {
+			t.Fatalf("computePodBatchRequest() = %+v, want {MilliCPU:%d Memory:%d}", got, wantCPU, wantMemory)
+		}
+	})
+}
+
+func FuzzFitsRequest(f *testing.F) {
+	// non-batch pod
+	f.Add(uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint8(100))
+	// request equals the free resources
+	f.Add(uint32(1000), uint32(1024), uint32(3000), uint32(3072), uint32(4000), uint32(4096), uint8(100))
+	// request exceeds the free resources by one
+	f.Add(uint32(1001), uint32(1025), uint32(3000), uint32(3072), uint32(4000), uint32(4096), uint8(100))
+	// node is already overcommitted
+	f.Add(uint32(1), uint32(1), uint32(5000), uint32(5000), uint32(4000), uint32(4096), uint8(100))
+	// overcommit ratio rounds down the capacity
+	f.Add(uint32(1000), uint32(1024), uint32(0), uint32(0), uint32(999), uint32(1023), uint8(101))
+	// max values
+	f.Add(uint32(1<<32-1), uint32(1<<32-1), uint32(1<<32-1), uint32(1<<32-1), uint32(1<<32-1), uint32(1<<32-1), uint8(255))
+
+	f.Fuzz(func(t *testing.T, podCPU, podMemory, requestedCPU, requestedMemory, allocatableCPU, allocatableMemory uint32, ratio uint8) {
+		if ratio == 0 {
+			ratio = 100
+		}
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "main", Resources: corev1.ResourceRequirements{Requests: newFuzzBatchRes(int64(podCPU), int64(podMemory), false)}},
+				},
+			},
+		}
+		nodeInfo := &framework.NodeInfo{
+			Requested:   framework.NewResource(newFuzzBatchRes(int64(requestedCPU), int64(requestedMemory), false)),
+			Allocatable: framework.NewResource(newFuzzBatchRes(int64(allocatableCPU), int64(allocatableMemory), false)),
+		}
+		args := &config.BatchResourceFitArgs{
+			OvercommitRatios: map[corev1.ResourceName]int64{
+				apiext.BatchCPU:    int64(ratio),
+				apiext.BatchMemory: int64(ratio),
+			},
+		}
+
+		wantInsufficient := map[corev1.ResourceName]bool{}
+		capacities := map[corev1.ResourceName]int64{
+			apiext.BatchCPU:    int64(allocatableCPU) * int64(ratio) / 100,
+			apiext.BatchMemory: int64(allocatableMemory) * int64(ratio) / 100,
+		}
+		if podCPU > 0 || podMemory > 0 {
+			wantInsufficient[apiext.BatchCPU] = int64(podCPU) > capacities[apiext.BatchCPU]-int64(requestedCPU)
+			wantInsufficient[apiext.BatchMemory] = int64(podMemory) > capacities[apiext.BatchMemory]-int64(requestedMemory)
+		}
+
+		got := fitsRequest(pod, nodeInfo, args, nil)
+		gotInsufficient := map[corev1.ResourceName]bool{}
+		for _, r := range got {
+			if r.ResourceName != apiext.BatchCPU && r.ResourceName != apiext.BatchMemory {
+				t.Fatalf("fitsRequest() reports unexpected resource %v", r.ResourceName)
+			}
+			if gotInsufficient[r.ResourceName] {
+				t.Fatalf("fitsRequest() reports %v more than once", r.ResourceName)
+			}
+			gotInsufficient[r.ResourceName] = true
+			if r.Requested < 0 || r.Used < 0 || r.Capacity < 0 {
+				t.Fatalf("fitsRequest() reports negative values %+v", r)
+			}
+			if r.Capacity != capacities[r.ResourceName] {
+				t.Fatalf("fitsRequest() reports capacity %d of %v, want %d", r.Capacity, r.ResourceName, capacities[r.ResourceName])
+			}
+			if r.Requested <= r.Capacity-r.Used {
+				t.Fatalf("fitsRequest() reports %v insufficient but the request fits, %+v", r.ResourceName, r)
+			}
+		}
+		for _, resourceName := range []corev1.ResourceName{apiext.BatchCPU, apiext.BatchMemory} {
+			if gotInsufficient[resourceName] != wantInsufficient[resourceName] {
+				t.Fatalf("fitsRequest() insufficient %v = %v, want %v", resourceName, gotInsufficient[resourceName], wantInsufficient[resourceName])
+			}
+		}
+	})
+}
@@ -21,14 +21,91 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
 )
 
+var _ framework.SharedLister = &testSharedLister{}
+
+type testSharedLister struct {
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+func newTestSharedLister(nodes []*corev1.Node) *testSharedLister {
+	nodeInfoMap := make(map[string]*framework.NodeInfo)
+	nodeInfos := make([]*framework.NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		nodeInfoMap[node.Name] = nodeInfo
+		nodeInfos = append(nodeInfos, nodeInfo)
+	}
+	return &testSharedLister{
+		nodeInfos:   nodeInfos,
+		nodeInfoMap: nodeInfoMap,
+	}
+}
+
+func (f *testSharedLister) NodeInfos() framework.NodeInfoLister {
+	return f
+}
+
+func (f *testSharedLister) List() ([]*framework.NodeInfo, error) {
+	return f.nodeInfos, nil
+}
+
+func (f *testSharedLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	return f.nodeInfoMap[nodeName], nil
+}
+
+func newPluginForTest(t *testing.T, args *config.BatchResourceFitArgs, nodes []*corev1.Node) *Plugin {
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+		frameworkruntime.WithSnapshotSharedLister(newTestSharedLister(nodes)),
+	)
+	assert.NoError(t, err)
+	p, err := New(args, fh)
+	assert.NoError(t, err)
+	return p.(*Plugin)
+}
+
+func newDefaultArgs(t *testing.T) *config.BatchResourceFitArgs {
+	var v1beta2args v1beta2.BatchResourceFitArgs
+	v1beta2.SetDefaults_BatchResourceFitArgs(&v1beta2args)
+	var args config.BatchResourceFitArgs
+	err := v1beta2.Convert_v1beta2_BatchResourceFitArgs_To_config_BatchResourceFitArgs(&v1beta2args, &args, nil)
+	assert.NoError(t, err)
+	return &args
+}
+
 func newContainerKoordBatchRes(milliCPU, memory int64) corev1.ResourceList {
 	// nolint:staticcheck // SA1019: apiext.KoordBatchCPU is deprecated: because of the limitation of extended resource naming
 	// nolint:staticcheck // SA1019: apiext.KoordBatchMemory is deprecated: because of the limitation of extended resource naming
@@ -73,6 +150,27 @@ func newBatchPod(milliCPU, memory int64) *corev1.Pod {
 	}
 }
 
+func newAnnotationBatchPod(t *testing.T, milliCPU, memory int64) *corev1.Pod {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+				},
+			},
+		},
+	}
+	err := apiext.SetExtendedResourceSpec(pod, &apiext.ExtendedResourceSpec{
+		Containers: map[string]apiext.ExtendedResourceContainerSpec{
+			"main": {
+				Requests: newContainerBatchRes(milliCPU, memory),
+			},
+		},
+	})
+	assert.NoError(t, err)
+	return pod
+}
+
 func newNodeBatchRes(koordMilliCPU, koordMemory, milliCPU, memory *int64) *framework.Resource {
 	result := &framework.Resource{
 		ScalarResources: map[corev1.ResourceName]int64{},
@@ -184,10 +282,32 @@ func TestPlugin_Filter(t *testing.T) {
 			},
 			want: framework.NewStatus(framework.Unschedulable, "Insufficient batch memory"),
 		},
+		{
+			name: "failed with annotation-only batch pod",
+			args: args{
+				pod: newAnnotationBatchPod(t, 3000, 1024),
+				nodeInfo: &framework.NodeInfo{
+					Requested:   newNodeBatchRes(nil, nil, pointer.Int64(2000), pointer.Int64(2048)),
+					Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+				},
+			},
+			want: framework.NewStatus(framework.Unschedulable, "Insufficient batch cpu"),
+		},
+		{
+			name: "success with annotation-only batch pod",
+			args: args{
+				pod: newAnnotationBatchPod(t, 1000, 1024),
+				nodeInfo: &framework.NodeInfo{
+					Requested:   newNodeBatchRes(nil, nil, pointer.Int64(2000), pointer.Int64(2048)),
+					Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+				},
+			},
+			want: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := &Plugin{}
+			p := &Plugin{args: &config.BatchResourceFitArgs{}}
 			if got := p.Filter(context.TODO(), nil, tt.args.pod, tt.args.nodeInfo); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Filter() = %v, want %v", got, tt.want)
 			}
@@ -252,12 +372,745 @@ func Test_computePodBatchRequest(t *testing.T) {
 				Memory:   1024,
 			},
 		},
+		{
+			name: "annotation-only pod with batch resource",
+			args: args{
+				pod: newAnnotationBatchPod(t, 1000, 1024),
+			},
+			want: &batchResource{
+				MilliCPU: 1000,
+				Memory:   1024,
+			},
+		},
+		{
+			name: "spec batch resource takes precedence over annotation",
+			args: args{
+				pod: func() *corev1.Pod {
+					pod := newAnnotationBatchPod(t, 1000, 1024)
+					pod.Spec.Containers[0].Resources.Requests = newContainerBatchRes(2000, 2048)
+					return pod
+				}(),
+			},
+			want: &batchResource{
+				MilliCPU: 2000,
+				Memory:   2048,
+			},
+		},
+		{
+			name: "ephemeral containers are ignored",
+			args: args{
+				pod: &corev1.Pod{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "main",
+								Resources: corev1.ResourceRequirements{
+									Requests: newContainerBatchRes(1000, 1024),
+								},
+							},
+						},
+						EphemeralContainers: []corev1.EphemeralContainer{
+							{
+								EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+									Name: "debugger",
+									Resources: corev1.ResourceRequirements{
+										Requests: newContainerBatchRes(4000, 4096),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: &batchResource{
+				MilliCPU: 1000,
+				Memory:   1024,
+			},
+		},
+		{
+			name: "ephemeral containers in annotation are ignored",
+			args: args{
+				pod: func() *corev1.Pod {
+					pod := &corev1.Pod{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "main",
+								},
+							},
+							EphemeralContainers: []corev1.EphemeralContainer{
+								{
+									EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+										Name: "debugger",
+									},
+								},
+							},
+						},
+					}
+					err := apiext.SetExtendedResourceSpec(pod, &apiext.ExtendedResourceSpec{
+						Containers: map[string]apiext.ExtendedResourceContainerSpec{
+							"main": {
+								Requests: newContainerBatchRes(1000, 1024),
+							},
+							"debugger": {
+								Requests: newContainerBatchRes(4000, 4096),
+							},
+						},
+					})
+					assert.NoError(t, err)
+					return pod
+				}(),
+			},
+			want: &batchResource{
+				MilliCPU: 1000,
+				Memory:   1024,
+			},
+		},
+		{
+			name: "invalid annotation is ignored",
+			args: args{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							apiext.AnnotationExtendedResourceSpec: "{invalid",
+						},
+					},
+				},
+			},
+			want: &batchResource{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := computePodBatchRequest(tt.args.pod); !reflect.DeepEqual(got, tt.want) {
+			if got := computePodBatchRequest(tt.args.pod, config.BatchInitContainerSidecarAware); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("computePodBatchRequest() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestComputePodBatchRequestWithInitContainerMode(t *testing.T) {
+	// the sidecars istio-proxy and log-agent surround the regular init container init-db
+	newPod := func(sidecars string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					apiext.AnnotationSidecarInitContainers: sidecars,
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "main", Resources: corev1.ResourceRequirements{Requests: newContainerBatchRes(1000, 1024)}},
+				},
+				InitContainers: []corev1.Container{
+					{Name: "istio-proxy", Resources: corev1.ResourceRequirements{Requests: newContainerBatchRes(500, 512)}},
+					{Name: "init-db", Resources: corev1.ResourceRequirements{Requests: newContainerBatchRes(2000, 1024)}},
+					{Name: "log-agent", Resources: corev1.ResourceRequirements{Requests: newContainerBatchRes(200, 256)}},
+				},
+			},
+		}
+	}
+	// the same requests declared in the ExtendedResourceSpec annotation
+	newAnnotationPod := func(t *testing.T, sidecars string) *corev1.Pod {
+		pod := newPod(sidecars)
+		spec := &apiext.ExtendedResourceSpec{Containers: map[string]apiext.ExtendedResourceContainerSpec{}}
+		for _, containers := range [][]corev1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+			for i := range containers {
+				spec.Containers[containers[i].Name] = apiext.ExtendedResourceContainerSpec{Requests: containers[i].Resources.Requests}
+				containers[i].Resources.Requests = nil
+			}
+		}
+		assert.NoError(t, apiext.SetExtendedResourceSpec(pod, spec))
+		return pod
+	}
+	tests := []struct {
+		name     string
+		sidecars string
+		mode     config.BatchInitContainerMode
+		want     *batchResource
+	}{
+		{
+			name:     "legacy max takes the max of the containers and each init container",
+			sidecars: "istio-proxy,log-agent",
+			mode:     config.BatchInitContainerLegacyMax,
+			want:     &batchResource{MilliCPU: 2000, Memory: 1024},
+		},
+		{
+			name:     "sidecar aware keeps the sidecars running along with the containers and the later init containers",
+			sidecars: "istio-proxy,log-agent",
+			mode:     config.BatchInitContainerSidecarAware,
+			// max(main + istio-proxy + log-agent, istio-proxy + init-db)
+			want: &batchResource{MilliCPU: 2500, Memory: 1792},
+		},
+		{
+			name:     "sidecar aware is the same as legacy max without sidecars",
+			sidecars: "",
+			mode:     config.BatchInitContainerSidecarAware,
+			want:     &batchResource{MilliCPU: 2000, Memory: 1024},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, computePodBatchRequest(newPod(tt.sidecars), tt.mode))
+			assert.Equal(t, tt.want, computePodBatchRequest(newAnnotationPod(t, tt.sidecars), tt.mode))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(&config.BatchResourceFitArgs{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Name, p.Name())
+
+	p, err = New(&config.LoadAwareSchedulingArgs{}, nil)
+	assert.Error(t, err)
+	assert.Nil(t, p)
+
+	p, err = New(newDefaultArgs(t), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Name, p.Name())
+}
+
+func TestNewWithoutArgs(t *testing.T) {
+	p, err := New(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Name, p.Name())
+	assert.Equal(t, newDefaultArgs(t), p.(*Plugin).args)
+}
+
+func TestNewWithInvalidArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args *config.BatchResourceFitArgs
+	}{
+		{
+			name: "non-positive overcommit ratio",
+			args: &config.BatchResourceFitArgs{
+				OvercommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 0},
+			},
+		},
+		{
+			name: "overcommit ratio of unsupported resource",
+			args: &config.BatchResourceFitArgs{
+				OvercommitRatios: map[corev1.ResourceName]int64{corev1.ResourceCPU: 120},
+			},
+		},
+		{
+			name: "non-positive resource weight",
+			args: &config.BatchResourceFitArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{apiext.BatchMemory: -1},
+			},
+		},
+		{
+			name: "resource weight of unsupported resource",
+			args: &config.BatchResourceFitArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{corev1.ResourceMemory: 1},
+			},
+		},
+		{
+			name: "negative eviction count weight",
+			args: &config.BatchResourceFitArgs{EvictionCountWeight: -1},
+		},
+		{
+			name: "eviction count weight exceeds max score",
+			args: &config.BatchResourceFitArgs{EvictionCountWeight: framework.MaxNodeScore + 1},
+		},
+		{
+			name: "unsupported scoring strategy",
+			args: &config.BatchResourceFitArgs{ScoringStrategy: "Balanced"},
+		},
+		{
+			name: "unsupported init container mode",
+			args: &config.BatchResourceFitArgs{InitContainerMode: "Sum"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.args, nil)
+			assert.Error(t, err)
+			assert.Nil(t, p)
+		})
+	}
+}
+
+func TestFilterWithOvercommitRatios(t *testing.T) {
+	nodeInfo := &framework.NodeInfo{
+		Requested:   newNodeBatchRes(nil, nil, pointer.Int64(3500), pointer.Int64(3000)),
+		Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+	}
+	tests := []struct {
+		name             string
+		overcommitRatios map[corev1.ResourceName]int64
+		want             *framework.Status
+	}{
+		{
+			name: "no overcommit",
+			want: framework.NewStatus(framework.Unschedulable, "Insufficient batch cpu"),
+		},
+		{
+			name:             "overcommit batch cpu",
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 100},
+			want:             nil,
+		},
+		{
+			name:             "reserve batch memory",
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 80},
+			want:             framework.NewStatus(framework.Unschedulable, "Insufficient batch memory"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{args: &config.BatchResourceFitArgs{OvercommitRatios: tt.overcommitRatios}}
+			got := p.Filter(context.TODO(), framework.NewCycleState(), newBatchPod(1000, 1024), nodeInfo)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterWithOvercommitRatiosBoundary(t *testing.T) {
+	tests := []struct {
+		name             string
+		allocatable      [2]int64
+		overcommitRatios map[corev1.ResourceName]int64
+		podRequests      [2]int64
+		want             *framework.Status
+	}{
+		{
+			name:             "pod exactly equals the overcommitted allocatable",
+			allocatable:      [2]int64{4000, 4096},
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 150},
+			podRequests:      [2]int64{6000, 6144},
+			want:             nil,
+		},
+		{
+			name:             "pod exceeds the overcommitted allocatable by one milli-core",
+			allocatable:      [2]int64{4000, 4096},
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 150},
+			podRequests:      [2]int64{6001, 6144},
+			want:             framework.NewStatus(framework.Unschedulable, "Insufficient batch cpu"),
+		},
+		{
+			name:             "pod exceeds the overcommitted allocatable by one byte",
+			allocatable:      [2]int64{4000, 4096},
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 150},
+			podRequests:      [2]int64{6000, 6145},
+			want:             framework.NewStatus(framework.Unschedulable, "Insufficient batch memory"),
+		},
+		{
+			name:             "overcommitted allocatable is rounded down",
+			allocatable:      [2]int64{4001, 4097},
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 150},
+			podRequests:      [2]int64{6001, 6145},
+			want:             nil,
+		},
+		{
+			name:             "overcommitted memory in bytes does not overflow",
+			allocatable:      [2]int64{4000, 1 << 60},
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 700},
+			podRequests:      [2]int64{6000, 7 << 60},
+			want:             nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := &framework.NodeInfo{
+				Requested:   newNodeBatchRes(nil, nil, pointer.Int64(0), pointer.Int64(0)),
+				Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(tt.allocatable[0]), pointer.Int64(tt.allocatable[1])),
+			}
+			p := &Plugin{args: &config.BatchResourceFitArgs{OvercommitRatios: tt.overcommitRatios}}
+			got := p.Filter(context.TODO(), framework.NewCycleState(), newBatchPod(tt.podRequests[0], tt.podRequests[1]), nodeInfo)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScoreWithScoringStrategy(t *testing.T) {
+	newNode := func(name string, milliCPU, memory int64) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					apiext.BatchCPU:    *resource.NewQuantity(milliCPU, resource.DecimalSI),
+					apiext.BatchMemory: *resource.NewQuantity(memory, resource.BinarySI),
+				},
+			},
+		}
+	}
+	nodes := []*corev1.Node{newNode("test-node-1", 4000, 4096), newNode("test-node-2", 8000, 8192)}
+	tests := []struct {
+		name             string
+		scoringStrategy  config.BatchResourceScoringStrategy
+		overcommitRatios map[corev1.ResourceName]int64
+		wantScores       map[string]int64
+	}{
+		{
+			name:            "least allocated",
+			scoringStrategy: config.BatchResourceLeastAllocated,
+			wantScores:      map[string]int64{"test-node-1": 75, "test-node-2": 87},
+		},
+		{
+			name:            "most allocated",
+			scoringStrategy: config.BatchResourceMostAllocated,
+			wantScores:      map[string]int64{"test-node-1": 25, "test-node-2": 12},
+		},
+		{
+			name:             "least allocated with overcommit",
+			scoringStrategy:  config.BatchResourceLeastAllocated,
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 200, apiext.BatchMemory: 200},
+			wantScores:       map[string]int64{"test-node-1": 87, "test-node-2": 93},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := newDefaultArgs(t)
+			args.ScoringStrategy = tt.scoringStrategy
+			if tt.overcommitRatios != nil {
+				args.OvercommitRatios = tt.overcommitRatios
+			}
+			p := newPluginForTest(t, args, nodes)
+			gotScores := map[string]int64{}
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), newBatchPod(1000, 1024), node.Name)
+				assert.True(t, status.IsSuccess())
+				gotScores[node.Name] = score
+			}
+			assert.Equal(t, tt.wantScores, gotScores)
+		})
+	}
+}
+
+func TestScoreWithEvictionCount(t *testing.T) {
+	newNode := func(name string, evictionCount string) *corev1.Node {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{},
+			},
+		}
+		if evictionCount != "" {
+			node.Annotations[apiext.AnnotationNodeBatchEvictionCount] = evictionCount
+		}
+		return node
+	}
+	tests := []struct {
+		name                string
+		pod                 *corev1.Pod
+		evictionCountWeight int64
+		nodes               []*corev1.Node
+		wantScores          map[string]int64
+	}{
+		{
+			name:                "eviction count weight disabled",
+			pod:                 newBatchPod(1000, 1024),
+			evictionCountWeight: 0,
+			nodes:               []*corev1.Node{newNode("test-node-1", "5"), newNode("test-node-2", "")},
+			wantScores:          map[string]int64{"test-node-1": 100, "test-node-2": 100},
+		},
+		{
+			name:                "prefer node with less evictions",
+			pod:                 newBatchPod(1000, 1024),
+			evictionCountWeight: 10,
+			nodes:               []*corev1.Node{newNode("test-node-1", "5"), newNode("test-node-2", "1")},
+			wantScores:          map[string]int64{"test-node-1": 50, "test-node-2": 90},
+		},
+		{
+			name:                "eviction count flips the preferred node",
+			pod:                 newKoordBatchPod(1000, 1024),
+			evictionCountWeight: 10,
+			nodes:               []*corev1.Node{newNode("test-node-1", "0"), newNode("test-node-2", "3")},
+			wantScores:          map[string]int64{"test-node-1": 100, "test-node-2": 70},
+		},
+		{
+			name:                "penalty is capped",
+			pod:                 newBatchPod(1000, 1024),
+			evictionCountWeight: 30,
+			nodes:               []*corev1.Node{newNode("test-node-1", "4"), newNode("test-node-2", "1000")},
+			wantScores:          map[string]int64{"test-node-1": 0, "test-node-2": 0},
+		},
+		{
+			name:                "invalid eviction count is ignored",
+			pod:                 newBatchPod(1000, 1024),
+			evictionCountWeight: 10,
+			nodes:               []*corev1.Node{newNode("test-node-1", "invalid"), newNode("test-node-2", "-1")},
+			wantScores:          map[string]int64{"test-node-1": 100, "test-node-2": 100},
+		},
+		{
+			name:                "non-batch pod is not affected",
+			pod:                 &corev1.Pod{},
+			evictionCountWeight: 10,
+			nodes:               []*corev1.Node{newNode("test-node-1", "5"), newNode("test-node-2", "")},
+			wantScores:          map[string]int64{"test-node-1": 100, "test-node-2": 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPluginForTest(t, &config.BatchResourceFitArgs{EvictionCountWeight: tt.evictionCountWeight}, tt.nodes)
+			gotScores := map[string]int64{}
+			for _, node := range tt.nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), tt.pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				gotScores[node.Name] = score
+			}
+			assert.Equal(t, tt.wantScores, gotScores)
+		})
+	}
+}
+
+func TestComputeClusterBatchFree(t *testing.T) {
+	annotatedNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"4000","kubernetes.io/batch-memory":"4096"}`,
+			},
+		},
+	}
+	annotatedNodeInfo := framework.NewNodeInfo()
+	annotatedNodeInfo.SetNode(annotatedNode)
+	tests := []struct {
+		name             string
+		overcommitRatios map[corev1.ResourceName]int64
+		nodeInfos        []*framework.NodeInfo
+		want             corev1.ResourceList
+	}{
+		{
+			name: "no nodes",
+			want: newContainerBatchRes(0, 0),
+		},
+		{
+			name: "nodes with mixed resource names",
+			nodeInfos: []*framework.NodeInfo{
+				{
+					Requested:   newNodeBatchRes(nil, nil, pointer.Int64(1000), pointer.Int64(1024)),
+					Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+				},
+				{
+					Requested:   newNodeBatchRes(pointer.Int64(500), pointer.Int64(512), nil, nil),
+					Allocatable: newNodeBatchRes(pointer.Int64(2000), pointer.Int64(2048), nil, nil),
+				},
+				{
+					// the requested of the new and deprecated names are accumulated,
+					// and the allocatable of the new names overwrite the deprecated names
+					Requested:   newNodeBatchRes(pointer.Int64(500), pointer.Int64(512), pointer.Int64(500), pointer.Int64(512)),
+					Allocatable: newNodeBatchRes(pointer.Int64(8000), pointer.Int64(8192), pointer.Int64(3000), pointer.Int64(3072)),
+				},
+			},
+			want: newContainerBatchRes(3000+1500+2000, 3072+1536+2048),
+		},
+		{
+			name: "overcommitted node does not offset the others",
+			nodeInfos: []*framework.NodeInfo{
+				{
+					Requested:   newNodeBatchRes(nil, nil, pointer.Int64(1000), pointer.Int64(1024)),
+					Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+				},
+				{
+					Requested:   newNodeBatchRes(nil, nil, pointer.Int64(5000), pointer.Int64(1024)),
+					Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+				},
+			},
+			want: newContainerBatchRes(3000, 3072+3072),
+		},
+		{
+			name: "node without batch resources",
+			nodeInfos: []*framework.NodeInfo{
+				{
+					Requested:   &framework.Resource{},
+					Allocatable: &framework.Resource{MilliCPU: 4000, Memory: 4096},
+				},
+			},
+			want: newContainerBatchRes(0, 0),
+		},
+		{
+			name:             "nodes with overcommit ratios",
+			overcommitRatios: map[corev1.ResourceName]int64{apiext.BatchCPU: 150, apiext.BatchMemory: 50},
+			nodeInfos: []*framework.NodeInfo{
+				{
+					Requested:   newNodeBatchRes(nil, nil, pointer.Int64(1000), pointer.Int64(1024)),
+					Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+				},
+			},
+			want: newContainerBatchRes(5000, 1024),
+		},
+		{
+			name:      "node with annotated batch allocatable",
+			nodeInfos: []*framework.NodeInfo{annotatedNodeInfo},
+			want:      newContainerBatchRes(4000, 4096),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := newDefaultArgs(t)
+			args.OvercommitRatios = tt.overcommitRatios
+			p := newPluginForTest(t, args, nil)
+			got := p.ComputeClusterBatchFree(tt.nodeInfos)
+			assert.True(t, quotav1.Equals(tt.want, got), "want %v, got %v", tt.want, got)
+		})
+	}
+}
+
+func TestFilterWithIgnoredInitContainers(t *testing.T) {
+	nodeInfo := &framework.NodeInfo{
+		Requested:   newNodeBatchRes(nil, nil, pointer.Int64(0), pointer.Int64(0)),
+		Allocatable: newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *framework.Status
+	}{
+		{
+			name: "init container is counted by default",
+			want: framework.NewStatus(framework.Unschedulable, "Insufficient batch cpu", "Insufficient batch memory"),
+		},
+		{
+			name:        "init container is ignored",
+			annotations: map[string]string{apiext.AnnotationBatchIgnoreInitContainers: "true"},
+			want:        nil,
+		},
+		{
+			name:        "init container is counted if not ignored",
+			annotations: map[string]string{apiext.AnnotationBatchIgnoreInitContainers: "false"},
+			want:        framework.NewStatus(framework.Unschedulable, "Insufficient batch cpu", "Insufficient batch memory"),
+		},
+		{
+			name:        "init container is counted with invalid annotation",
+			annotations: map[string]string{apiext.AnnotationBatchIgnoreInitContainers: "invalid"},
+			want:        framework.NewStatus(framework.Unschedulable, "Insufficient batch cpu", "Insufficient batch memory"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newBatchPod(1000, 1024)
+			pod.Annotations = tt.annotations
+			pod.Spec.InitContainers = []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: newContainerBatchRes(8000, 8192),
+					},
+				},
+			}
+			p := &Plugin{args: &config.BatchResourceFitArgs{}}
+			got := p.Filter(context.TODO(), framework.NewCycleState(), pod, nodeInfo)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestComputeNodeBatchAllocatableWithAnnotation(t *testing.T) {
+	newNode := func(annotations map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-node",
+				Annotations: annotations,
+			},
+		}
+	}
+	tests := []struct {
+		name                  string
+		allocatable           *framework.Resource
+		nodeAnnotations       map[string]string
+		nodeMetricAnnotations map[string]string
+		want                  *batchResource
+	}{
+		{
+			name:            "extended resources take precedence",
+			allocatable:     newNodeBatchRes(nil, nil, pointer.Int64(4000), pointer.Int64(4096)),
+			nodeAnnotations: map[string]string{apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"8000","kubernetes.io/batch-memory":"8192"}`},
+			want:            &batchResource{MilliCPU: 4000, Memory: 4096},
+		},
+		{
+			name:            "read from the node annotation",
+			allocatable:     &framework.Resource{},
+			nodeAnnotations: map[string]string{apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"8000","kubernetes.io/batch-memory":"8Gi"}`},
+			want:            &batchResource{MilliCPU: 8000, Memory: 8 * 1024 * 1024 * 1024},
+		},
+		{
+			name:                  "the NodeMetric annotation takes precedence over the node annotation",
+			allocatable:           &framework.Resource{},
+			nodeAnnotations:       map[string]string{apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"8000","kubernetes.io/batch-memory":"8192"}`},
+			nodeMetricAnnotations: map[string]string{apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"2000","kubernetes.io/batch-memory":"2048"}`},
+			want:                  &batchResource{MilliCPU: 2000, Memory: 2048},
+		},
+		{
+			name:            "only the absent resource is read from the annotation",
+			allocatable:     &framework.Resource{ScalarResources: map[corev1.ResourceName]int64{apiext.BatchCPU: 4000}},
+			nodeAnnotations: map[string]string{apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"8000","kubernetes.io/batch-memory":"8192"}`},
+			want:            &batchResource{MilliCPU: 4000, Memory: 8192},
+		},
+		{
+			name:                  "invalid NodeMetric annotation falls back to the node annotation",
+			allocatable:           &framework.Resource{},
+			nodeAnnotations:       map[string]string{apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"8000","kubernetes.io/batch-memory":"8192"}`},
+			nodeMetricAnnotations: map[string]string{apiext.AnnotationNodeBatchAllocatable: `invalid`},
+			want:                  &batchResource{MilliCPU: 8000, Memory: 8192},
+		},
+		{
+			name:            "invalid node annotation is ignored",
+			allocatable:     &framework.Resource{},
+			nodeAnnotations: map[string]string{apiext.AnnotationNodeBatchAllocatable: `invalid`},
+			want:            &batchResource{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordfake.NewSimpleClientset(), 0)
+			nodeMetricInformer := koordSharedInformerFactory.Slo().V1alpha1().NodeMetrics()
+			if tt.nodeMetricAnnotations != nil {
+				err := nodeMetricInformer.Informer().GetStore().Add(&slov1alpha1.NodeMetric{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-node",
+						Annotations: tt.nodeMetricAnnotations,
+					},
+				})
+				assert.NoError(t, err)
+			}
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(newNode(tt.nodeAnnotations))
+			nodeInfo.Allocatable = tt.allocatable
+			got := computeNodeBatchAllocatable(nodeInfo, nodeMetricInformer.Lister())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterWithAnnotatedBatchAllocatable(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				apiext.AnnotationNodeBatchAllocatable: `{"kubernetes.io/batch-cpu":"4000","kubernetes.io/batch-memory":"4096"}`,
+			},
+		},
+	}
+	p := newPluginForTest(t, newDefaultArgs(t), []*corev1.Node{node})
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(node.Name)
+	assert.NoError(t, err)
+	tests := []struct {
+		name       string
+		request    corev1.ResourceList
+		wantStatus *framework.Status
+	}{
+		{
+			name:    "fits the annotated allocatable",
+			request: newContainerBatchRes(2000, 2048),
+		},
+		{
+			name:       "exceeds the annotated allocatable",
+			request:    newContainerBatchRes(8000, 2048),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "Insufficient batch cpu"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "main", Resources: corev1.ResourceRequirements{Requests: tt.request}},
+					},
+				},
+			}
+			assert.Equal(t, tt.wantStatus, p.Filter(context.TODO(), framework.NewCycleState(), pod, nodeInfo))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compatibledefaultpreemption
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	scheduledconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	plfeature "k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+)
+
+const (
+	// CompatibleDefaultPreemptionSubsystem - subsystem name used by CompatibleDefaultPreemption plugin
+	CompatibleDefaultPreemptionSubsystem = "compatible_default_preemption"
+)
+
+var (
+	ResolvedArgs = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      CompatibleDefaultPreemptionSubsystem,
+			Name:           "resolved_args",
+			Help:           "The args resolved by merging the configured args into the defaults, by the arg name. The booleans are 1 if enabled",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"arg"})
+
+	metricsList = []metrics.Registerable{
+		ResolvedArgs,
+	}
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the metrics of CompatibleDefaultPreemption plugin.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		for _, metric := range metricsList {
+			legacyregistry.MustRegister(metric)
+		}
+	})
+}
+
+// recordResolvedArgs records the args in effect, so that the preemption tuning can be audited across clusters.
+func recordResolvedArgs(args *scheduledconfig.DefaultPreemptionArgs, fts plfeature.Features) {
+	ResolvedArgs.WithLabelValues("min_candidate_nodes_percentage").Set(float64(args.MinCandidateNodesPercentage))
+	ResolvedArgs.WithLabelValues("min_candidate_nodes_absolute").Set(float64(args.MinCandidateNodesAbsolute))
+	var podDisruptionBudgetEnabled float64
+	if fts.EnablePodDisruptionBudget {
+		podDisruptionBudgetEnabled = 1
+	}
+	ResolvedArgs.WithLabelValues("pod_disruption_budget_enabled").Set(podDisruptionBudgetEnabled)
+}
@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/util/feature"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	scheduledconfigv1beta2config "k8s.io/kube-scheduler/config/v1beta2"
 	"k8s.io/kubernetes/pkg/features"
 	scheduledconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -38,6 +39,9 @@ const (
 type CompatibleDefaultPreemption struct {
 	args *scheduledconfig.DefaultPreemptionArgs
 	framework.PostFilterPlugin
+	handle            framework.Handle
+	defaultPreemption *defaultpreemption.DefaultPreemption
+	podLister         corelisters.PodLister
 }
 
 func New(dpArgs runtime.Object, fh framework.Handle) (framework.Plugin, error) {
@@ -69,9 +73,14 @@ func New(dpArgs runtime.Object, fh framework.Handle) (framework.Plugin, error) {
 	if err != nil {
 		return nil, err
 	}
+	RegisterMetrics()
+	recordResolvedArgs(defaultPreemptionArgs, fts)
 	return &CompatibleDefaultPreemption{
-		args:             dpArgs.(*scheduledconfig.DefaultPreemptionArgs),
-		PostFilterPlugin: plg.(framework.PostFilterPlugin),
+		args:              dpArgs.(*scheduledconfig.DefaultPreemptionArgs),
+		PostFilterPlugin:  plg.(framework.PostFilterPlugin),
+		handle:            fh,
+		defaultPreemption: plg.(*defaultpreemption.DefaultPreemption),
+		podLister:         fh.SharedInformerFactory().Core().V1().Pods().Lister(),
 	}, nil
 }
 
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/component-base/metrics/testutil"
 	scheduledconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
@@ -120,6 +121,16 @@ func TestNew(t *testing.T) {
 			assert.NotNil(t, p)
 			assert.Equal(t, Name, p.Name())
 			assert.Equal(t, tt.wantArgs, p.(*CompatibleDefaultPreemption).args)
+
+			for arg, want := range map[string]float64{
+				"min_candidate_nodes_percentage": float64(tt.wantArgs.MinCandidateNodesPercentage),
+				"min_candidate_nodes_absolute":   float64(tt.wantArgs.MinCandidateNodesAbsolute),
+				"pod_disruption_budget_enabled":  0,
+			} {
+				value, err := testutil.GetGaugeMetricValue(ResolvedArgs.WithLabelValues(arg))
+				assert.NoError(t, err)
+				assert.Equal(t, want, value, arg)
+			}
 		})
 	}
 }
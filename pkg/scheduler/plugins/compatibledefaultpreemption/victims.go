@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compatibledefaultpreemption
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	apiscorehelper "k8s.io/kubernetes/pkg/apis/core/helper"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+	"k8s.io/kubernetes/pkg/scheduler/metrics"
+	"k8s.io/kubernetes/pkg/scheduler/util"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+type candidate struct {
+	victims *extenderv1.Victims
+	name    string
+}
+
+func (c *candidate) Victims() *extenderv1.Victims {
+	return c.victims
+}
+
+func (c *candidate) Name() string {
+	return c.name
+}
+
+// upstreamKubernetesVersion is the version of k8s.io/kubernetes the preemption here is copied from,
+// which is checked against go.mod by the tests.
+const upstreamKubernetesVersion = "v1.22.6"
+
+// The preemption here is copied from pkg/scheduler/framework/plugins/defaultpreemption/default_preemption.go
+// of k8s.io/kubernetes v1.22.6, and must be re-synced with it when the kube version is upgraded.
+
+// PostFilter follows the default preemption, except that the victims on each candidate node are reselected
+// to prefer the Pods with lower pod-deletion-cost and eviction cost among the Pods with the same priority,
+// because the default preemption of the kube version only orders the victims by priority and start time.
+func (plg *CompatibleDefaultPreemption) PostFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, m framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	defer func() {
+		metrics.PreemptionAttempts.Inc()
+	}()
+
+	nominatedNodeName, status := plg.preempt(ctx, state, pod, m)
+	if !status.IsSuccess() {
+		return nil, status
+	}
+	if nominatedNodeName == "" {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	return &framework.PostFilterResult{NominatedNodeName: nominatedNodeName}, framework.NewStatus(framework.Success)
+}
+
+func (plg *CompatibleDefaultPreemption) preempt(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, m framework.NodeToStatusMap) (string, *framework.Status) {
+	nodeLister := plg.handle.SnapshotSharedLister().NodeInfos()
+
+	podNamespace, podName := pod.Namespace, pod.Name
+	pod, err := plg.podLister.Pods(pod.Namespace).Get(pod.Name)
+	if err != nil {
+		klog.ErrorS(err, "getting the updated preemptor pod object", "pod", klog.KRef(podNamespace, podName))
+		return "", framework.AsStatus(err)
+	}
+
+	if !defaultpreemption.PodEligibleToPreemptOthers(pod, nodeLister, m[pod.Status.NominatedNodeName]) {
+		klog.V(5).InfoS("Pod is not eligible for more preemption", "pod", klog.KObj(pod))
+		return "", nil
+	}
+
+	candidates, nodeToStatusMap, status := plg.defaultPreemption.FindCandidates(ctx, state, pod, m)
+	if !status.IsSuccess() {
+		return "", status
+	}
+	if len(candidates) == 0 {
+		fitError := &framework.FitError{
+			Pod:         pod,
+			NumAllNodes: len(nodeToStatusMap),
+			Diagnosis: framework.Diagnosis{
+				NodeToStatusMap: nodeToStatusMap,
+			},
+		}
+		return "", framework.NewStatus(framework.Unschedulable, fitError.Error())
+	}
+
+	candidates = plg.reselectVictims(ctx, state, pod, candidates)
+
+	candidates, status = defaultpreemption.CallExtenders(plg.handle.Extenders(), pod, nodeLister, candidates)
+	if !status.IsSuccess() {
+		return "", status
+	}
+
+	bestCandidate := defaultpreemption.SelectCandidate(candidates)
+	if bestCandidate == nil || len(bestCandidate.Name()) == 0 {
+		return "", nil
+	}
+
+	if status := defaultpreemption.PrepareCandidate(bestCandidate, plg.handle, plg.handle.ClientSet(), pod, plg.Name()); !status.IsSuccess() {
+		return "", status
+	}
+	return bestCandidate.Name(), nil
+}
+
+// reselectVictims reselects the victims of the candidates by the cost-aware order,
+// and keeps the victims selected by the default preemption if the reselection fails.
+func (plg *CompatibleDefaultPreemption) reselectVictims(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, candidates []defaultpreemption.Candidate) []defaultpreemption.Candidate {
+	nodeLister := plg.handle.SnapshotSharedLister().NodeInfos()
+	result := make([]defaultpreemption.Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		nodeInfo, err := nodeLister.Get(c.Name())
+		if err != nil {
+			result = append(result, c)
+			continue
+		}
+		victims, status := selectVictimsOnNode(ctx, plg.handle, state.Clone(), pod, nodeInfo.Clone())
+		if !status.IsSuccess() {
+			klog.V(4).InfoS("Failed to reselect victims on node, keep the default victims", "pod", klog.KObj(pod), "node", c.Name(), "status", status.Message())
+			result = append(result, c)
+			continue
+		}
+		result = append(result, &candidate{
+			victims: &extenderv1.Victims{
+				Pods: victims,
+				// the PodDisruptionBudgets are not considered by selectVictimsOnNode,
+				// so the violations of the default victims don't apply to the reselected ones.
+				NumPDBViolations: 0,
+			},
+			name: c.Name(),
+		})
+	}
+	return result
+}
+
+// selectVictimsOnNode follows selectVictimsOnNode in pkg/scheduler/framework/plugins/defaultpreemption/default_preemption.go
+// of k8s.io/kubernetes v1.22.6 to remove all the lower priority Pods from the node, and then reprieves as many Pods
+// as possible from the most important one by the order of moreImportantVictim.
+// The PodDisruptionBudgets are not considered because they are disabled in the kube version.
+func selectVictimsOnNode(ctx context.Context, fh framework.Handle, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) ([]*corev1.Pod, *framework.Status) {
+	removePod := func(podInfo *framework.PodInfo) error {
+		if err := nodeInfo.RemovePod(podInfo.Pod); err != nil {
+			return err
+		}
+		return fh.RunPreFilterExtensionRemovePod(ctx, state, pod, podInfo, nodeInfo).AsError()
+	}
+	addPod := func(podInfo *framework.PodInfo) error {
+		nodeInfo.AddPodInfo(podInfo)
+		return fh.RunPreFilterExtensionAddPod(ctx, state, pod, podInfo, nodeInfo).AsError()
+	}
+
+	var potentialVictims []*framework.PodInfo
+	podPriority := corev1helpers.PodPriority(pod)
+	for _, podInfo := range nodeInfo.Pods {
+		if corev1helpers.PodPriority(podInfo.Pod) < podPriority {
+			potentialVictims = append(potentialVictims, podInfo)
+		}
+	}
+	for _, podInfo := range potentialVictims {
+		if err := removePod(podInfo); err != nil {
+			return nil, framework.AsStatus(err)
+		}
+	}
+	if len(potentialVictims) == 0 {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("No victims found on node %v for preemptor pod %v", nodeInfo.Node().Name, pod.Name))
+	}
+	if status := fh.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+		return nil, status
+	}
+
+	sort.SliceStable(potentialVictims, func(i, j int) bool {
+		return moreImportantVictim(potentialVictims[i].Pod, potentialVictims[j].Pod)
+	})
+	var victims []*corev1.Pod
+	for _, podInfo := range potentialVictims {
+		if err := addPod(podInfo); err != nil {
+			return nil, framework.AsStatus(err)
+		}
+		if fh.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo).IsSuccess() {
+			continue
+		}
+		if err := removePod(podInfo); err != nil {
+			return nil, framework.AsStatus(err)
+		}
+		victims = append(victims, podInfo.Pod)
+		klog.V(5).InfoS("Pod is a potential preemption victim on node", "pod", klog.KObj(podInfo.Pod), "node", klog.KObj(nodeInfo.Node()))
+	}
+	return victims, nil
+}
+
+// moreImportantVictim returns true if p1 should be reprieved before p2. The Pods with the same priority are
+// ordered by the pod-deletion-cost and then the eviction cost of koordinator, the higher cost is more important,
+// and then by the start time as the default preemption.
+// The invalid costs are regarded as 0, which is the implicit cost of the Pods without the annotations.
+func moreImportantVictim(p1, p2 *corev1.Pod) bool {
+	p1Priority, p2Priority := corev1helpers.PodPriority(p1), corev1helpers.PodPriority(p2)
+	if p1Priority != p2Priority {
+		return p1Priority > p2Priority
+	}
+	p1DeletionCost, _ := apiscorehelper.GetDeletionCostFromPodAnnotations(p1.Annotations)
+	p2DeletionCost, _ := apiscorehelper.GetDeletionCostFromPodAnnotations(p2.Annotations)
+	if p1DeletionCost != p2DeletionCost {
+		return p1DeletionCost > p2DeletionCost
+	}
+	p1EvictionCost, _ := apiext.GetEvictionCost(p1.Annotations)
+	p2EvictionCost, _ := apiext.GetEvictionCost(p2.Annotations)
+	if p1EvictionCost != p2EvictionCost {
+		return p1EvictionCost > p2EvictionCost
+	}
+	return util.MoreImportantPod(p1, p2)
+}
@@ -0,0 +1,387 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compatibledefaultpreemption
+
+import (
+	"context"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	scheduledconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	scheduledruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+var _ framework.SharedLister = &testSharedLister{}
+
+type testSharedLister struct {
+	nodes       []*corev1.Node
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+func newTestSharedLister(pods []*corev1.Pod, nodes []*corev1.Node) *testSharedLister {
+	nodeInfoMap := make(map[string]*framework.NodeInfo)
+	nodeInfos := make([]*framework.NodeInfo, 0)
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		if _, ok := nodeInfoMap[nodeName]; !ok {
+			nodeInfoMap[nodeName] = framework.NewNodeInfo()
+		}
+		nodeInfoMap[nodeName].AddPod(pod)
+	}
+	for _, node := range nodes {
+		if _, ok := nodeInfoMap[node.Name]; !ok {
+			nodeInfoMap[node.Name] = framework.NewNodeInfo()
+		}
+		nodeInfoMap[node.Name].SetNode(node)
+	}
+
+	for _, v := range nodeInfoMap {
+		nodeInfos = append(nodeInfos, v)
+	}
+
+	return &testSharedLister{
+		nodes:       nodes,
+		nodeInfos:   nodeInfos,
+		nodeInfoMap: nodeInfoMap,
+	}
+}
+
+func (f *testSharedLister) NodeInfos() framework.NodeInfoLister {
+	return f
+}
+
+func (f *testSharedLister) List() ([]*framework.NodeInfo, error) {
+	return f.nodeInfos, nil
+}
+
+func (f *testSharedLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	return f.nodeInfoMap[nodeName], nil
+}
+
+var _ framework.PodNominator = &fakePodNominator{}
+
+type fakePodNominator struct{}
+
+func (f *fakePodNominator) AddNominatedPod(pod *framework.PodInfo, nodeName string) {}
+
+func (f *fakePodNominator) DeleteNominatedPodIfExists(pod *corev1.Pod) {}
+
+func (f *fakePodNominator) UpdateNominatedPod(oldPod *corev1.Pod, newPodInfo *framework.PodInfo) {}
+
+func (f *fakePodNominator) NominatedPodsForNode(nodeName string) []*framework.PodInfo {
+	return nil
+}
+
+func newTestPod(name string, priority int32, milliCPU int64, annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			UID:         types.UID(name),
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(priority),
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(milliCPU, resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPostFilterWithVictimCosts(t *testing.T) {
+	tests := []struct {
+		name              string
+		victims           []*corev1.Pod
+		wantDeletedVictim string
+	}{
+		{
+			name: "prefer the pod with lower pod-deletion-cost",
+			victims: []*corev1.Pod{
+				newTestPod("low-deletion-cost", 0, 2000, map[string]string{corev1.PodDeletionCost: "-100"}),
+				newTestPod("high-deletion-cost", 0, 2000, map[string]string{corev1.PodDeletionCost: "100"}),
+			},
+			wantDeletedVictim: "low-deletion-cost",
+		},
+		{
+			name: "prefer the pod with lower eviction cost",
+			victims: []*corev1.Pod{
+				newTestPod("low-eviction-cost", 0, 2000, map[string]string{apiext.AnnotationEvictionCost: "-100"}),
+				newTestPod("high-eviction-cost", 0, 2000, map[string]string{apiext.AnnotationEvictionCost: "100"}),
+			},
+			wantDeletedVictim: "low-eviction-cost",
+		},
+		{
+			name: "pod-deletion-cost takes precedence over eviction cost",
+			victims: []*corev1.Pod{
+				newTestPod("low-deletion-cost", 0, 2000, map[string]string{corev1.PodDeletionCost: "-100", apiext.AnnotationEvictionCost: "100"}),
+				newTestPod("low-eviction-cost", 0, 2000, map[string]string{apiext.AnnotationEvictionCost: "-100"}),
+			},
+			wantDeletedVictim: "low-deletion-cost",
+		},
+		{
+			name: "priority takes precedence over costs",
+			victims: []*corev1.Pod{
+				newTestPod("low-priority", 0, 2000, map[string]string{corev1.PodDeletionCost: "100"}),
+				newTestPod("high-priority", 5, 2000, map[string]string{corev1.PodDeletionCost: "-100"}),
+			},
+			wantDeletedVictim: "low-priority",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:  resource.MustParse("4"),
+						corev1.ResourcePods: resource.MustParse("110"),
+					},
+				},
+			}
+			preemptor := newTestPod("preemptor", 10, 2000, nil)
+
+			cs := kubefake.NewSimpleClientset()
+			var existingPods []*corev1.Pod
+			// the victims are started in order, so the default preemption would reprieve the earlier started ones
+			startTime := metav1.Now()
+			for i, victim := range tt.victims {
+				victim.Spec.NodeName = node.Name
+				victimStartTime := metav1.NewTime(startTime.Add(time.Duration(i-len(tt.victims)) * time.Minute))
+				victim.Status.StartTime = &victimStartTime
+				_, err := cs.CoreV1().Pods(victim.Namespace).Create(context.TODO(), victim, metav1.CreateOptions{})
+				assert.NoError(t, err)
+				existingPods = append(existingPods, victim)
+			}
+			_, err := cs.CoreV1().Pods(preemptor.Namespace).Create(context.TODO(), preemptor, metav1.CreateOptions{})
+			assert.NoError(t, err)
+
+			registeredPlugins := []schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterPluginAsExtensions(noderesources.FitName, func(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+					args := &scheduledconfig.NodeResourcesFitArgs{
+						ScoringStrategy: &scheduledconfig.ScoringStrategy{
+							Type:      scheduledconfig.LeastAllocated,
+							Resources: []scheduledconfig.ResourceSpec{{Name: string(corev1.ResourceCPU), Weight: 1}},
+						},
+					}
+					return noderesources.NewFit(args, fh, feature.Features{})
+				}, "PreFilter", "Filter"),
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			}
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+				scheduledruntime.WithClientSet(cs),
+				scheduledruntime.WithInformerFactory(informerFactory),
+				scheduledruntime.WithSnapshotSharedLister(newTestSharedLister(existingPods, []*corev1.Node{node})),
+				scheduledruntime.WithPodNominator(&fakePodNominator{}),
+				scheduledruntime.WithEventRecorder(events.NewFakeRecorder(10)),
+			)
+			assert.NoError(t, err)
+
+			p, err := New(nil, fh)
+			assert.NoError(t, err)
+			informerFactory.Start(nil)
+			informerFactory.WaitForCacheSync(nil)
+
+			cycleState := framework.NewCycleState()
+			status := fh.RunPreFilterPlugins(context.TODO(), cycleState, preemptor)
+			assert.True(t, status.IsSuccess())
+			nodeToStatusMap := framework.NodeToStatusMap{
+				node.Name: framework.NewStatus(framework.Unschedulable, "Insufficient cpu"),
+			}
+			result, status := p.(framework.PostFilterPlugin).PostFilter(context.TODO(), cycleState, preemptor, nodeToStatusMap)
+			assert.True(t, status.IsSuccess(), status.Message())
+			assert.Equal(t, &framework.PostFilterResult{NominatedNodeName: node.Name}, result)
+
+			for _, victim := range tt.victims {
+				_, err := cs.CoreV1().Pods(victim.Namespace).Get(context.TODO(), victim.Name, metav1.GetOptions{})
+				if victim.Name == tt.wantDeletedVictim {
+					assert.True(t, errors.IsNotFound(err), "victim %s should be deleted", victim.Name)
+				} else {
+					assert.NoError(t, err, "pod %s should not be deleted", victim.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestMoreImportantVictim(t *testing.T) {
+	now := metav1.Now()
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+	tests := []struct {
+		name string
+		p1   *corev1.Pod
+		p2   *corev1.Pod
+		want bool
+	}{
+		{
+			name: "higher priority is more important",
+			p1:   newTestPod("p1", 10, 0, map[string]string{corev1.PodDeletionCost: "-100"}),
+			p2:   newTestPod("p2", 0, 0, map[string]string{corev1.PodDeletionCost: "100"}),
+			want: true,
+		},
+		{
+			name: "higher pod-deletion-cost is more important",
+			p1:   newTestPod("p1", 0, 0, map[string]string{corev1.PodDeletionCost: "100"}),
+			p2:   newTestPod("p2", 0, 0, nil),
+			want: true,
+		},
+		{
+			name: "lower eviction cost is less important",
+			p1:   newTestPod("p1", 0, 0, map[string]string{apiext.AnnotationEvictionCost: "-1"}),
+			p2:   newTestPod("p2", 0, 0, nil),
+			want: false,
+		},
+		{
+			name: "invalid costs are regarded as 0",
+			p1:   newTestPod("p1", 0, 0, map[string]string{corev1.PodDeletionCost: "invalid", apiext.AnnotationEvictionCost: "invalid"}),
+			p2:   newTestPod("p2", 0, 0, map[string]string{apiext.AnnotationEvictionCost: "1"}),
+			want: false,
+		},
+		{
+			name: "earlier started pod is more important with the same costs",
+			p1: func() *corev1.Pod {
+				pod := newTestPod("p1", 0, 0, nil)
+				pod.Status.StartTime = &earlier
+				return pod
+			}(),
+			p2: func() *corev1.Pod {
+				pod := newTestPod("p2", 0, 0, nil)
+				pod.Status.StartTime = &now
+				return pod
+			}(),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, moreImportantVictim(tt.p1, tt.p2))
+		})
+	}
+}
+
+func TestReselectVictimsResetsPDBViolations(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("4"),
+				corev1.ResourcePods: resource.MustParse("110"),
+			},
+		},
+	}
+	victim := newTestPod("victim", 0, 2000, nil)
+	victim.Spec.NodeName = node.Name
+	preemptor := newTestPod("preemptor", 10, 3000, nil)
+
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterPluginAsExtensions(noderesources.FitName, func(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+			args := &scheduledconfig.NodeResourcesFitArgs{
+				ScoringStrategy: &scheduledconfig.ScoringStrategy{
+					Type:      scheduledconfig.LeastAllocated,
+					Resources: []scheduledconfig.ResourceSpec{{Name: string(corev1.ResourceCPU), Weight: 1}},
+				},
+			}
+			return noderesources.NewFit(args, fh, feature.Features{})
+		}, "PreFilter", "Filter"),
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+		scheduledruntime.WithSnapshotSharedLister(newTestSharedLister([]*corev1.Pod{victim}, []*corev1.Node{node})),
+		scheduledruntime.WithPodNominator(&fakePodNominator{}),
+	)
+	assert.NoError(t, err)
+	plg := &CompatibleDefaultPreemption{handle: fh}
+	cycleState := framework.NewCycleState()
+	assert.True(t, fh.RunPreFilterPlugins(context.TODO(), cycleState, preemptor).IsSuccess())
+
+	// the violations counted by the default preemption are stale for the reselected victims.
+	candidates := []defaultpreemption.Candidate{
+		&candidate{
+			victims: &extenderv1.Victims{
+				Pods:             []*corev1.Pod{victim},
+				NumPDBViolations: 1,
+			},
+			name: node.Name,
+		},
+	}
+	got := plg.reselectVictims(context.TODO(), cycleState, preemptor, candidates)
+	assert.Len(t, got, 1)
+	assert.Equal(t, []*corev1.Pod{victim}, got[0].Victims().Pods)
+	assert.Equal(t, int64(0), got[0].Victims().NumPDBViolations)
+}
+
+// TestUpstreamKubernetesVersion fails once k8s.io/kubernetes is upgraded, to remind that the preemption
+// copied from the default preemption must be re-synced with the new version and upstreamKubernetesVersion bumped.
+func TestUpstreamKubernetesVersion(t *testing.T) {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Skip("build info is not available")
+	}
+	var version string
+	for _, dep := range buildInfo.Deps {
+		if dep.Path != "k8s.io/kubernetes" {
+			continue
+		}
+		version = dep.Version
+		if dep.Replace != nil {
+			version = dep.Replace.Version
+		}
+	}
+	assert.Equal(t, upstreamKubernetesVersion, version,
+		"the preemption is copied from k8s.io/kubernetes %s, re-sync it with %s", upstreamKubernetesVersion, version)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// logBindLoadProfile logs the usage reported in the NodeMetric of the node the Pod is bound to,
+// the estimated usage of the Pod, and the headroom of the weighted resources after placing the Pod.
+// The CPU is in milli cores and the others are in their base units, the same as scoring.
+func (p *Plugin) logBindLoadProfile(args *loadAwareArgs, pod *corev1.Pod, nodeName string) {
+	logger := klog.V(2)
+	if !logger.Enabled() {
+		return
+	}
+	// PreBind runs out of the scheduling cycle, where the snapshot is being updated.
+	node, err := p.nodeLister.Get(nodeName)
+	if err != nil {
+		return
+	}
+	nodeMetric, err := p.getNodeMetric(args.LoadAwareSchedulingArgs, nodeName)
+	if err != nil {
+		logger.InfoS("Pod bound to the node without NodeMetric", "pod", klog.KObj(pod), "node", nodeName, "err", err)
+		return
+	}
+	podEstimatedUsed, err := args.estimator.Estimate(pod)
+	if err != nil {
+		return
+	}
+
+	nodeUsage := map[corev1.ResourceName]int64{}
+	if usages := extractNodeUsages(p.podLister, nodeMetric); usages.total != nil {
+		for resourceName, quantity := range usages.total.ResourceList {
+			nodeUsage[resourceName] = getResourceValue(resourceName, quantity)
+		}
+	}
+	headroom := make(map[corev1.ResourceName]int64, len(args.ResourceWeights))
+	for resourceName := range args.ResourceWeights {
+		quantity, ok := node.Status.Allocatable[resourceName]
+		if !ok {
+			continue
+		}
+		headroom[resourceName] = getResourceValue(resourceName, quantity) - nodeUsage[resourceName] - podEstimatedUsed[resourceName]
+	}
+	logger.InfoS("Pod bound to the node with load profile", "pod", klog.KObj(pod), "node", nodeName,
+		"nodeMetricUpdateTime", nodeMetric.Status.UpdateTime, "nodeUsage", nodeUsage, "podEstimated", podEstimatedUsed, "headroom", headroom)
+}
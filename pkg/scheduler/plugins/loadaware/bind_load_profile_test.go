@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"flag"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+type testLogEntry struct {
+	msg    string
+	fields map[string]interface{}
+}
+
+// testLogger records the entries logged through klog.
+type testLogger struct {
+	lock    *sync.Mutex
+	entries *[]testLogEntry
+}
+
+func newTestLogger() testLogger {
+	return testLogger{lock: &sync.Mutex{}, entries: &[]testLogEntry{}}
+}
+
+func (l testLogger) Enabled() bool { return true }
+
+func (l testLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	*l.entries = append(*l.entries, testLogEntry{msg: msg, fields: fields})
+}
+
+func (l testLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.Info(msg, append(keysAndValues, "err", err)...)
+}
+
+func (l testLogger) V(level int) logr.Logger { return l }
+
+func (l testLogger) WithValues(keysAndValues ...interface{}) logr.Logger { return l }
+
+func (l testLogger) WithName(name string) logr.Logger { return l }
+
+func (l testLogger) getEntry(msg string) *testLogEntry {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for i := range *l.entries {
+		if (*l.entries)[i].msg == msg {
+			return &(*l.entries)[i]
+		}
+	}
+	return nil
+}
+
+func TestPreBindLogLoadProfile(t *testing.T) {
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	verbosity := flags.Lookup("v").Value.String()
+	assert.NoError(t, flags.Set("v", "2"))
+	defer flags.Set("v", verbosity)
+
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		},
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("30"),
+							corev1.ResourceMemory: resource.MustParse("40Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	const msg = "Pod bound to the node with load profile"
+	tests := []struct {
+		name               string
+		logBindLoadProfile *bool
+		wantLogged         bool
+	}{
+		{
+			name: "load profile is not logged by default",
+		},
+		{
+			name:               "log load profile",
+			logBindLoadProfile: pointer.Bool(true),
+			wantLogged:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newTestLogger()
+			klog.SetLogger(logger)
+			defer klog.SetLogger(nil)
+
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				LogBindLoadProfile: tt.logBindLoadProfile,
+			}, nodes, nodeMetrics, nil)
+			assert.True(t, p.PreBind(context.TODO(), framework.NewCycleState(), pod, "test-node-1").IsSuccess())
+
+			entry := logger.getEntry(msg)
+			if !tt.wantLogged {
+				assert.Nil(t, entry)
+				return
+			}
+			assert.NotNil(t, entry)
+			podEstimated, err := p.getArgs().estimator.Estimate(pod)
+			assert.NoError(t, err)
+			nodeUsage := map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    30000,
+				corev1.ResourceMemory: 40 * 1024 * 1024 * 1024,
+			}
+			assert.Equal(t, klog.KObj(pod), entry.fields["pod"])
+			assert.Equal(t, "test-node-1", entry.fields["node"])
+			assert.Equal(t, nodeMetrics[0].Status.UpdateTime, entry.fields["nodeMetricUpdateTime"])
+			assert.Equal(t, nodeUsage, entry.fields["nodeUsage"])
+			assert.Equal(t, podEstimated, entry.fields["podEstimated"])
+			assert.Equal(t, map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    100000 - nodeUsage[corev1.ResourceCPU] - podEstimated[corev1.ResourceCPU],
+				corev1.ResourceMemory: 100*1024*1024*1024 - nodeUsage[corev1.ResourceMemory] - podEstimated[corev1.ResourceMemory],
+			}, entry.fields["headroom"])
+		})
+	}
+}
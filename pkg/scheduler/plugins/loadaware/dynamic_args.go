@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware/estimator"
+)
+
+const (
+	// DynamicArgsConfigMapKey is the key of the dynamic args in the ConfigMap.
+	// The value is a LoadAwareSchedulingArgs in JSON or YAML format, for example:
+	//
+	//	usageThresholds:
+	//	  cpu: 75
+	//	  memory: 90
+	//
+	// Only resourceWeights, usageThresholds, prodUsageThresholds and estimatedScalingFactors are applied.
+	// The first three replace the static ones if set, and they are cleared if set to {}.
+	DynamicArgsConfigMapKey = "loadAwareSchedulingArgs"
+)
+
+// registerDynamicArgsEventHandler watches the ConfigMap of the dynamic args with an informer scoped to the ConfigMap,
+// so that the scheduler neither caches nor needs the permission to watch the ConfigMaps cluster-wide.
+// The static args stay in effect if there is no client to watch the ConfigMap.
+func registerDynamicArgsEventHandler(client kubernetes.Interface, p *Plugin) {
+	namespace, name := p.staticArgs.DynamicArgsConfigMapNamespace, p.staticArgs.DynamicArgsConfigMapName
+	if client == nil {
+		klog.InfoS("No client to watch LoadAwareScheduling dynamic args, use the static args", "configMap", klog.KRef(namespace, name))
+		return
+	}
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	configMapInformer := informerFactory.Core().V1().ConfigMaps().Informer()
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), informerFactory, configMapInformer, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if configMap, ok := obj.(*corev1.ConfigMap); ok {
+				p.updateDynamicArgs(configMap)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if configMap, ok := newObj.(*corev1.ConfigMap); ok {
+				p.updateDynamicArgs(configMap)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			p.updateDynamicArgs(nil)
+		},
+	})
+}
+
+// updateDynamicArgs applies the args in the ConfigMap on top of the static args.
+// The static args are restored if the ConfigMap is deleted or has no dynamic args,
+// and the args in effect are kept if the dynamic args are invalid.
+func (p *Plugin) updateDynamicArgs(configMap *corev1.ConfigMap) {
+	configMapRef := klog.KRef(p.staticArgs.DynamicArgsConfigMapNamespace, p.staticArgs.DynamicArgsConfigMapName)
+	args, err := buildDynamicArgs(p.staticArgs, configMap)
+	if err != nil {
+		klog.ErrorS(err, "Failed to build LoadAwareScheduling dynamic args, keep the args in effect", "configMap", configMapRef)
+		return
+	}
+	args = withoutNonPoolableResourceWeights(args)
+	estimator, err := estimator.NewEstimator(args, p.handle)
+	if err != nil {
+		klog.ErrorS(err, "Failed to build estimator with LoadAwareScheduling dynamic args, keep the args in effect", "configMap", configMapRef)
+		return
+	}
+	p.args.Store(&loadAwareArgs{LoadAwareSchedulingArgs: args, estimator: estimator})
+	p.podAssignCache.setEstimator(estimator)
+	klog.V(4).InfoS("LoadAwareScheduling args updated", "configMap", configMapRef)
+}
+
+func buildDynamicArgs(staticArgs *config.LoadAwareSchedulingArgs, configMap *corev1.ConfigMap) (*config.LoadAwareSchedulingArgs, error) {
+	if configMap == nil || configMap.Data[DynamicArgsConfigMapKey] == "" {
+		return staticArgs, nil
+	}
+
+	var dynamicArgs v1beta2.LoadAwareSchedulingArgs
+	if err := yaml.Unmarshal([]byte(configMap.Data[DynamicArgsConfigMapKey]), &dynamicArgs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s, err: %w", DynamicArgsConfigMapKey, err)
+	}
+
+	args := staticArgs.DeepCopy()
+	// the maps set in the dynamic args replace the static ones, and the empty ones clear them,
+	// e.g. "prodUsageThresholds: {}" disables filtering by the prod usage.
+	if dynamicArgs.ResourceWeights != nil {
+		args.ResourceWeights = dynamicArgs.ResourceWeights
+	}
+	if dynamicArgs.UsageThresholds != nil {
+		args.UsageThresholds = dynamicArgs.UsageThresholds
+	}
+	if dynamicArgs.ProdUsageThresholds != nil {
+		args.ProdUsageThresholds = dynamicArgs.ProdUsageThresholds
+	}
+	// the scaling factors are merged into the static ones by resource.
+	for resourceName, factor := range dynamicArgs.EstimatedScalingFactors {
+		if args.EstimatedScalingFactors == nil {
+			args.EstimatedScalingFactors = map[corev1.ResourceName]int64{}
+		}
+		args.EstimatedScalingFactors[resourceName] = factor
+	}
+	if err := validation.ValidateLoadAwareSchedulingArgs(args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestUpdateDynamicArgs(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2args.FilterExpiredNodeMetrics = pointer.Bool(false)
+	v1beta2args.DynamicArgsConfigMapNamespace = "koordinator-system"
+	v1beta2args.DynamicArgsConfigMapName = "load-aware-scheduling-args"
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		},
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("70"),
+							corev1.ResourceMemory: resource.MustParse("256Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	p, snapshot := newPluginForTest(t, &v1beta2args, nodes, nodeMetrics, nil)
+	nodeInfo, err := snapshot.Get("test-node-1")
+	assert.NoError(t, err)
+
+	exceedCPUStatus := framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU))
+	status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+	assert.True(t, exceedCPUStatus.Equal(status), "want status: %s, but got %s", exceedCPUStatus.Message(), status.Message())
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "koordinator-system",
+			Name:      "load-aware-scheduling-args",
+		},
+		Data: map[string]string{
+			DynamicArgsConfigMapKey: "usageThresholds:\n  cpu: 80\n  memory: 95\n",
+		},
+	}
+	p.updateDynamicArgs(configMap)
+	assert.Equal(t, int64(80), p.getArgs().UsageThresholds[corev1.ResourceCPU])
+	status = p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+	assert.Nil(t, status)
+
+	invalidConfigMap := configMap.DeepCopy()
+	invalidConfigMap.Data[DynamicArgsConfigMapKey] = "usageThresholds:\n  cpu: 200\n"
+	p.updateDynamicArgs(invalidConfigMap)
+	assert.Equal(t, int64(80), p.getArgs().UsageThresholds[corev1.ResourceCPU])
+	status = p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+	assert.Nil(t, status)
+
+	p.updateDynamicArgs(nil)
+	assert.Equal(t, p.staticArgs, p.getArgs().LoadAwareSchedulingArgs)
+	status = p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+	assert.True(t, exceedCPUStatus.Equal(status), "want status: %s, but got %s", exceedCPUStatus.Message(), status.Message())
+}
+
+func TestUpdateDynamicArgsRebuildEstimator(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2args.DynamicArgsConfigMapNamespace = "koordinator-system"
+	v1beta2args.DynamicArgsConfigMapName = "load-aware-scheduling-args"
+	p, _ := newPluginForTest(t, &v1beta2args, nil, nil, nil)
+
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	estimated, err := p.getArgs().estimator.Estimate(pod)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3400), estimated[corev1.ResourceCPU])
+
+	p.updateDynamicArgs(&corev1.ConfigMap{
+		Data: map[string]string{
+			DynamicArgsConfigMapKey: `{"estimatedScalingFactors":{"cpu":50}}`,
+		},
+	})
+	estimated, err = p.getArgs().estimator.Estimate(pod)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000), estimated[corev1.ResourceCPU])
+	assert.Equal(t, int64(70), p.getArgs().EstimatedScalingFactors[corev1.ResourceMemory])
+	assert.Equal(t, int64(85), p.staticArgs.EstimatedScalingFactors[corev1.ResourceCPU])
+}
+
+func TestBuildDynamicArgsClearMaps(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2args.ProdUsageThresholds = map[corev1.ResourceName]int64{
+		corev1.ResourceCPU: 60,
+	}
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+	var staticArgs config.LoadAwareSchedulingArgs
+	assert.NoError(t, v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &staticArgs, nil))
+
+	args, err := buildDynamicArgs(&staticArgs, &corev1.ConfigMap{
+		Data: map[string]string{
+			DynamicArgsConfigMapKey: "usageThresholds:\n  cpu: 80\n",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[corev1.ResourceName]int64{corev1.ResourceCPU: 80}, args.UsageThresholds)
+	assert.Equal(t, staticArgs.ProdUsageThresholds, args.ProdUsageThresholds, "the maps not set are kept")
+
+	args, err = buildDynamicArgs(&staticArgs, &corev1.ConfigMap{
+		Data: map[string]string{
+			DynamicArgsConfigMapKey: "usageThresholds:\n  cpu: 80\nprodUsageThresholds: {}\n",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, args.ProdUsageThresholds, "the maps set to {} are cleared")
+	assert.Equal(t, int64(60), staticArgs.ProdUsageThresholds[corev1.ResourceCPU])
+}
+
+func TestWatchDynamicArgsConfigMap(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2args.DynamicArgsConfigMapNamespace = "koordinator-system"
+	v1beta2args.DynamicArgsConfigMapName = "load-aware-scheduling-args"
+	p, _ := newPluginForTest(t, &v1beta2args, nil, nil, nil)
+
+	_, err := p.handle.ClientSet().CoreV1().ConfigMaps("koordinator-system").Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "koordinator-system",
+			Name:      "load-aware-scheduling-args",
+		},
+		Data: map[string]string{
+			DynamicArgsConfigMapKey: "usageThresholds:\n  cpu: 80\n",
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return p.getArgs().UsageThresholds[corev1.ResourceCPU] == 80
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err = p.handle.ClientSet().CoreV1().ConfigMaps("koordinator-system").Delete(context.TODO(), "load-aware-scheduling-args", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return p.getArgs().LoadAwareSchedulingArgs == p.staticArgs
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatchDynamicArgsWithoutClient(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2args.DynamicArgsConfigMapNamespace = "koordinator-system"
+	v1beta2args.DynamicArgsConfigMapName = "load-aware-scheduling-args"
+	p, _ := newPluginForTest(t, &v1beta2args, nil, nil, nil)
+	args := p.getArgs()
+
+	registerDynamicArgsEventHandler(nil, p)
+	assert.Same(t, args, p.getArgs())
+}
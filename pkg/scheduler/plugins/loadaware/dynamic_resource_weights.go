@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// dynamicResourceWeightsSyncInterval is the minimum interval between deriving the boosts of the resource weights.
+const dynamicResourceWeightsSyncInterval = time.Minute
+
+// dynamicResourceWeights caches the boosts of the resource weights derived from the cluster-wide utilization.
+// The boosts are derived at most once per dynamicResourceWeightsSyncInterval when scoring, and the stale ones
+// are kept if the NodeMetrics fail to be listed.
+type dynamicResourceWeights struct {
+	lock         sync.Mutex
+	lastSyncTime time.Time
+	boosts       map[corev1.ResourceName]int64
+}
+
+func newDynamicResourceWeights() *dynamicResourceWeights {
+	return &dynamicResourceWeights{}
+}
+
+// getResourceWeightBoosts returns the percentage the weight of each resource is boosted by.
+func (p *Plugin) getResourceWeightBoosts(args *loadAwareArgs) map[corev1.ResourceName]int64 {
+	d := p.dynamicResourceWeights
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if time.Since(d.lastSyncTime) >= dynamicResourceWeightsSyncInterval {
+		d.lastSyncTime = time.Now()
+		utilizations, err := p.clusterUtilizations(args)
+		if err != nil {
+			klog.V(4).InfoS("Failed to derive the cluster-wide utilization", "err", err)
+		} else {
+			d.boosts = deriveResourceWeightBoosts(utilizations, args.DynamicResourceWeightMaxBoostPercent)
+		}
+	}
+	return d.boosts
+}
+
+// clusterUtilizations returns the percentage of the sum of the node usage to the sum of the node allocatable
+// of the weighted resources, over the nodes whose NodeMetrics are not expired.
+func (p *Plugin) clusterUtilizations(args *loadAwareArgs) (map[corev1.ResourceName]int64, error) {
+	nodeMetrics, err := p.nodeMetricLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	resourceNames := map[corev1.ResourceName]struct{}{}
+	for resourceName := range args.ResourceWeights {
+		resourceNames[resourceName] = struct{}{}
+	}
+	for _, resourceWeights := range args.PriorityClassResourceWeights {
+		for resourceName := range resourceWeights {
+			resourceNames[resourceName] = struct{}{}
+		}
+	}
+	used := map[corev1.ResourceName]int64{}
+	allocatable := map[corev1.ResourceName]int64{}
+	for _, nodeMetric := range nodeMetrics {
+		if nodeMetric.Status.NodeMetric == nil {
+			continue
+		}
+		if args.NodeMetricExpirationSeconds != nil && isNodeMetricExpired(nodeMetric, *args.NodeMetricExpirationSeconds) {
+			continue
+		}
+		nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeMetric.Name)
+		if err != nil || nodeInfo.Node() == nil {
+			continue
+		}
+		node := nodeInfo.Node()
+		for resourceName := range resourceNames {
+			used[resourceName] += getResourceValue(resourceName, nodeMetric.Status.NodeMetric.NodeUsage.ResourceList[resourceName])
+			allocatable[resourceName] += getResourceValue(resourceName, node.Status.Allocatable[resourceName])
+		}
+	}
+	utilizations := make(map[corev1.ResourceName]int64, len(allocatable))
+	for resourceName, value := range allocatable {
+		if value <= 0 {
+			continue
+		}
+		utilization := used[resourceName] * 100 / value
+		if utilization > 100 {
+			utilization = 100
+		}
+		utilizations[resourceName] = utilization
+	}
+	return utilizations, nil
+}
+
+// deriveResourceWeightBoosts returns the percentage the weight of each resource is boosted by, which is
+// maxBoostPercent scaled by the percentage points the utilization of the resource is below the most utilized one.
+// The resources not boosted are omitted.
+func deriveResourceWeightBoosts(utilizations map[corev1.ResourceName]int64, maxBoostPercent int64) map[corev1.ResourceName]int64 {
+	var maxUtilization int64
+	for _, utilization := range utilizations {
+		if utilization > maxUtilization {
+			maxUtilization = utilization
+		}
+	}
+	boosts := map[corev1.ResourceName]int64{}
+	for resourceName, utilization := range utilizations {
+		if boost := maxBoostPercent * (maxUtilization - utilization) / 100; boost > 0 {
+			boosts[resourceName] = boost
+		}
+	}
+	return boosts
+}
+
+// withResourceWeightBoosts returns the args weighting the resources by the weights boosted by the percentages.
+// All weights are scaled by 100 so that the boosts are not truncated, which keeps the ratios between the weights.
+func withResourceWeightBoosts(args *loadAwareArgs, boosts map[corev1.ResourceName]int64) *loadAwareArgs {
+	if len(boosts) == 0 {
+		return args
+	}
+	resourceWeights := make(map[corev1.ResourceName]int64, len(args.ResourceWeights))
+	for resourceName, weight := range args.ResourceWeights {
+		resourceWeights[resourceName] = weight * (100 + boosts[resourceName])
+	}
+	schedulingArgs := *args.LoadAwareSchedulingArgs
+	schedulingArgs.ResourceWeights = resourceWeights
+	return &loadAwareArgs{LoadAwareSchedulingArgs: &schedulingArgs, estimator: args.estimator}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+)
+
+func newDynamicResourceWeightsTestNodes(usages map[string][2]string, updateTime time.Time) ([]*corev1.Node, []*slov1alpha1.NodeMetric) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, name := range []string{"test-node-1", "test-node-2", "test-node-3"} {
+		usage, ok := usages[name]
+		if !ok {
+			continue
+		}
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: updateTime,
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(usage[0]),
+							corev1.ResourceMemory: resource.MustParse(usage[1]),
+						},
+					},
+				},
+			},
+		})
+	}
+	return nodes, nodeMetrics
+}
+
+func TestDeriveResourceWeightBoosts(t *testing.T) {
+	tests := []struct {
+		name            string
+		utilizations    map[corev1.ResourceName]int64
+		maxBoostPercent int64
+		want            map[corev1.ResourceName]int64
+	}{
+		{
+			name:            "no utilizations",
+			maxBoostPercent: 100,
+			want:            map[corev1.ResourceName]int64{},
+		},
+		{
+			name:            "balanced utilizations",
+			utilizations:    map[corev1.ResourceName]int64{corev1.ResourceCPU: 60, corev1.ResourceMemory: 60},
+			maxBoostPercent: 100,
+			want:            map[corev1.ResourceName]int64{},
+		},
+		{
+			name:            "memory underutilized in the CPU-bound cluster",
+			utilizations:    map[corev1.ResourceName]int64{corev1.ResourceCPU: 80, corev1.ResourceMemory: 30},
+			maxBoostPercent: 100,
+			want:            map[corev1.ResourceName]int64{corev1.ResourceMemory: 50},
+		},
+		{
+			name:            "boost scaled by the max boost",
+			utilizations:    map[corev1.ResourceName]int64{corev1.ResourceCPU: 80, corev1.ResourceMemory: 30},
+			maxBoostPercent: 300,
+			want:            map[corev1.ResourceName]int64{corev1.ResourceMemory: 150},
+		},
+		{
+			name:            "boost in proportion to the gap",
+			utilizations:    map[corev1.ResourceName]int64{corev1.ResourceCPU: 30, corev1.ResourceMemory: 90, corev1.ResourceEphemeralStorage: 0},
+			maxBoostPercent: 100,
+			want:            map[corev1.ResourceName]int64{corev1.ResourceCPU: 60, corev1.ResourceEphemeralStorage: 90},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, deriveResourceWeightBoosts(tt.utilizations, tt.maxBoostPercent))
+		})
+	}
+}
+
+func TestClusterUtilizations(t *testing.T) {
+	nodes, nodeMetrics := newDynamicResourceWeightsTestNodes(map[string][2]string{
+		"test-node-1": {"80", "20Gi"},
+		"test-node-2": {"60", "40Gi"},
+	}, time.Now())
+	expiredNodes, expiredNodeMetrics := newDynamicResourceWeightsTestNodes(map[string][2]string{
+		"test-node-3": {"0", "100Gi"},
+	}, time.Now().Add(-time.Hour))
+	nodes = append(nodes, expiredNodes...)
+	nodeMetrics = append(nodeMetrics, expiredNodeMetrics...)
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nodes, nodeMetrics, nil)
+
+	utilizations, err := p.clusterUtilizations(p.getArgs())
+	assert.NoError(t, err)
+	assert.Equal(t, map[corev1.ResourceName]int64{corev1.ResourceCPU: 70, corev1.ResourceMemory: 30}, utilizations)
+}
+
+func TestScoreWithDynamicResourceWeights(t *testing.T) {
+	// the cluster is CPU-bound, test-node-1 is CPU-rich and test-node-2 is memory-rich by the same margin.
+	nodes, nodeMetrics := newDynamicResourceWeightsTestNodes(map[string][2]string{
+		"test-node-1": {"20", "60Gi"},
+		"test-node-2": {"60", "20Gi"},
+		"test-node-3": {"90", "10Gi"},
+	}, time.Now())
+	pod := &corev1.Pod{}
+
+	tests := []struct {
+		name            string
+		maxBoostPercent int64
+		wantScores      []int64
+	}{
+		{
+			name:       "static weights",
+			wantScores: []int64{59, 59, 49},
+		},
+		{
+			name:            "memory weight boosted",
+			maxBoostPercent: 100,
+			wantScores:      []int64{57, 61, 54},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				DynamicResourceWeightMaxBoostPercent: tt.maxBoostPercent,
+			}, nodes, nodeMetrics, nil)
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestValidateDynamicResourceWeightMaxBoostPercent(t *testing.T) {
+	v1beta2args := &v1beta2.LoadAwareSchedulingArgs{
+		DynamicResourceWeightMaxBoostPercent: 1001,
+	}
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+	err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dynamicResourceWeightMaxBoostPercent should be in the range [0, 1000]")
+}
@@ -21,7 +21,11 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -38,15 +42,47 @@ const (
 )
 
 type DefaultEstimator struct {
-	resourceWeights map[corev1.ResourceName]int64
-	scalingFactors  map[corev1.ResourceName]int64
+	resourceWeights      map[corev1.ResourceName]int64
+	scalingFactors       map[corev1.ResourceName]int64
+	qosScalingFactors    map[corev1.PodQOSClass]map[corev1.ResourceName]int64
+	ignoreInitContainers bool
+	useLimits            bool
+	respectZeroRequests  bool
+	sidecarOverheads     []sidecarOverhead
+	namespaceLister      corev1listers.NamespaceLister
+}
+
+// sidecarOverhead is the parsed config.LoadAwareSidecarOverhead.
+type sidecarOverhead struct {
+	namespaceSelector labels.Selector
+	containerName     string
+	overhead          corev1.ResourceList
 }
 
 func NewDefaultEstimator(args *config.LoadAwareSchedulingArgs, handle framework.Handle) (Estimator, error) {
-	return &DefaultEstimator{
-		resourceWeights: args.ResourceWeights,
-		scalingFactors:  args.EstimatedScalingFactors,
-	}, nil
+	estimator := &DefaultEstimator{
+		resourceWeights:      args.ResourceWeights,
+		scalingFactors:       args.EstimatedScalingFactors,
+		qosScalingFactors:    args.QoSEstimatedScalingFactors,
+		ignoreInitContainers: args.EstimateWithoutInitContainers,
+		useLimits:            args.EstimateMode == config.LoadAwareEstimateModeUseLimits,
+		respectZeroRequests:  args.RespectZeroRequests,
+	}
+	for _, sidecar := range args.SidecarOverheads {
+		selector, err := metav1.LabelSelectorAsSelector(sidecar.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		estimator.sidecarOverheads = append(estimator.sidecarOverheads, sidecarOverhead{
+			namespaceSelector: selector,
+			containerName:     sidecar.ContainerName,
+			overhead:          sidecar.Overhead,
+		})
+	}
+	if len(estimator.sidecarOverheads) > 0 && handle != nil && handle.SharedInformerFactory() != nil {
+		estimator.namespaceLister = handle.SharedInformerFactory().Core().V1().Namespaces().Lister()
+	}
+	return estimator, nil
 }
 
 func (e *DefaultEstimator) Name() string {
@@ -54,26 +90,102 @@ func (e *DefaultEstimator) Name() string {
 }
 
 func (e *DefaultEstimator) Estimate(pod *corev1.Pod) (map[corev1.ResourceName]int64, error) {
-	return estimatedPodUsed(pod, e.resourceWeights, e.scalingFactors), nil
+	if e.ignoreInitContainers && len(pod.Spec.InitContainers) > 0 {
+		// the init containers only run before the containers start, so the steady-state usage
+		// is estimated by the containers only.
+		steadyStatePod := *pod
+		steadyStatePod.Spec.InitContainers = nil
+		pod = &steadyStatePod
+	}
+	estimatedUsed := estimatedPodUsed(pod, e.resourceWeights, e.getScalingFactors(pod), e.useLimits, e.respectZeroRequests)
+	e.addSidecarOverheads(pod, estimatedUsed)
+	return estimatedUsed, nil
+}
+
+// addSidecarOverheads adds the overheads of the sidecars to be injected into the Pod to the estimate,
+// according to the labels of the namespace of the Pod.
+func (e *DefaultEstimator) addSidecarOverheads(pod *corev1.Pod, estimatedUsed map[corev1.ResourceName]int64) {
+	if len(e.sidecarOverheads) == 0 || e.namespaceLister == nil {
+		return
+	}
+	namespace, err := e.namespaceLister.Get(pod.Namespace)
+	if err != nil {
+		return
+	}
+	for _, sidecar := range e.sidecarOverheads {
+		if !sidecar.namespaceSelector.Matches(labels.Set(namespace.Labels)) || hasContainer(pod, sidecar.containerName) {
+			continue
+		}
+		for resourceName := range e.resourceWeights {
+			quantity, ok := sidecar.overhead[resourceName]
+			if !ok {
+				continue
+			}
+			if resourceName == corev1.ResourceCPU {
+				estimatedUsed[resourceName] += quantity.MilliValue()
+			} else {
+				estimatedUsed[resourceName] += quantity.Value()
+			}
+		}
+	}
 }
 
-func estimatedPodUsed(pod *corev1.Pod, resourceWeights map[corev1.ResourceName]int64, scalingFactors map[corev1.ResourceName]int64) map[corev1.ResourceName]int64 {
+// hasContainer returns whether the Pod has the container of the name, false if the name is empty.
+func hasContainer(pod *corev1.Pod, containerName string) bool {
+	if containerName == "" {
+		return false
+	}
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == containerName {
+			return true
+		}
+	}
+	return false
+}
+
+// getScalingFactors returns the scaling factors of the Pod, which are overridden by the factors of its QoS class.
+func (e *DefaultEstimator) getScalingFactors(pod *corev1.Pod) map[corev1.ResourceName]int64 {
+	qosScalingFactors := e.qosScalingFactors[qos.GetPodQOS(pod)]
+	if len(qosScalingFactors) == 0 {
+		return e.scalingFactors
+	}
+	scalingFactors := make(map[corev1.ResourceName]int64, len(e.scalingFactors))
+	for resourceName, factor := range e.scalingFactors {
+		scalingFactors[resourceName] = factor
+	}
+	for resourceName, factor := range qosScalingFactors {
+		scalingFactors[resourceName] = factor
+	}
+	return scalingFactors
+}
+
+func estimatedPodUsed(pod *corev1.Pod, resourceWeights map[corev1.ResourceName]int64, scalingFactors map[corev1.ResourceName]int64, useLimits, respectZeroRequests bool) map[corev1.ResourceName]int64 {
 	requests, limits := resourceapi.PodRequestsAndLimits(pod)
 	estimatedUsed := make(map[corev1.ResourceName]int64)
 	priorityClass := extension.GetPriorityClass(pod)
 	for resourceName := range resourceWeights {
 		realResourceName := extension.TranslateResourceNameByPriorityClass(priorityClass, resourceName)
-		estimatedUsed[resourceName] = estimatedUsedByResource(requests, limits, realResourceName, scalingFactors[resourceName])
+		estimatedUsed[resourceName] = estimatedUsedByResource(requests, limits, realResourceName, scalingFactors[resourceName], useLimits, respectZeroRequests)
 	}
 	return estimatedUsed
 }
 
 // TODO(joseph): Do we need to differentiate scalingFactor according to Koordinator Priority type?
-func estimatedUsedByResource(requests, limits corev1.ResourceList, resourceName corev1.ResourceName, scalingFactor int64) int64 {
-	limitQuantity := limits[resourceName]
-	requestQuantity := requests[resourceName]
+// If useLimits is true, the usage is estimated by the limit scaled by the factor, falling back to the request
+// if no limit is declared.
+// The realResourceName is the resource the Pod declares, e.g. kubernetes.io/batch-cpu translated from cpu for
+// the Batch Pods, so that the estimate is capped by the limit of the declared resource rather than the weighted one.
+// If respectZeroRequests is true, the resource requested as zero explicitly is estimated as zero rather than the default.
+func estimatedUsedByResource(requests, limits corev1.ResourceList, realResourceName corev1.ResourceName, scalingFactor int64, useLimits, respectZeroRequests bool) int64 {
+	limitQuantity := limits[realResourceName]
+	requestQuantity := requests[realResourceName]
 	var quantity resource.Quantity
-	if limitQuantity.Cmp(requestQuantity) > 0 {
+	if useLimits {
+		quantity = limitQuantity
+		if quantity.IsZero() {
+			quantity = requestQuantity
+		}
+	} else if limitQuantity.Cmp(requestQuantity) > 0 {
 		scalingFactor = 100
 		quantity = limitQuantity
 	} else {
@@ -81,7 +193,10 @@ func estimatedUsedByResource(requests, limits corev1.ResourceList, resourceName
 	}
 
 	if quantity.IsZero() {
-		switch resourceName {
+		if _, requested := requests[realResourceName]; requested && respectZeroRequests {
+			return 0
+		}
+		switch realResourceName {
 		case corev1.ResourceCPU, extension.BatchCPU:
 			return DefaultMilliCPURequest
 		case corev1.ResourceMemory, extension.BatchMemory:
@@ -90,16 +205,18 @@ func estimatedUsedByResource(requests, limits corev1.ResourceList, resourceName
 		return 0
 	}
 
+	// Only cap the estimated usage when the limit is declared, since resources such as
+	// extended resources are usually requested without limits.
 	var estimatedUsed int64
-	switch resourceName {
+	switch realResourceName {
 	case corev1.ResourceCPU:
 		estimatedUsed = int64(math.Round(float64(quantity.MilliValue()) * float64(scalingFactor) / 100))
-		if estimatedUsed > limitQuantity.MilliValue() {
+		if !limitQuantity.IsZero() && estimatedUsed > limitQuantity.MilliValue() {
 			estimatedUsed = limitQuantity.MilliValue()
 		}
 	default:
 		estimatedUsed = int64(math.Round(float64(quantity.Value()) * float64(scalingFactor) / 100))
-		if estimatedUsed > limitQuantity.Value() {
+		if !limitQuantity.IsZero() && estimatedUsed > limitQuantity.Value() {
 			estimatedUsed = limitQuantity.Value()
 		}
 	}
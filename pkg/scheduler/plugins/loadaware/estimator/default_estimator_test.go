@@ -17,12 +17,16 @@ limitations under the License.
 package estimator
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -196,6 +200,51 @@ func TestDefaultEstimator(t *testing.T) {
 				corev1.ResourceMemory: 6012954214, // 5.6Gi
 			},
 		},
+		{
+			name: "estimate Batch pod and zoomed factors capped by the batch limits",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						extension.LabelPodQoS: string(extension.QoSBE),
+					},
+				},
+				Spec: corev1.PodSpec{
+					Priority: pointer.Int32(extension.PriorityBatchValueMin),
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Limits: map[corev1.ResourceName]resource.Quantity{
+									extension.BatchCPU:    resource.MustParse("4000"),
+									extension.BatchMemory: resource.MustParse("8Gi"),
+								},
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									extension.BatchCPU:    resource.MustParse("4000"),
+									extension.BatchMemory: resource.MustParse("8Gi"),
+								},
+							},
+						},
+						{
+							Name: "sidecar",
+							Resources: corev1.ResourceRequirements{
+								Limits: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("8"),
+									corev1.ResourceMemory: resource.MustParse("16Gi"),
+								},
+							},
+						},
+					},
+				},
+			},
+			scalarFactors: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    110,
+				corev1.ResourceMemory: 110,
+			},
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    4000,
+				corev1.ResourceMemory: 8589934592, // 8Gi
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -217,3 +266,472 @@ func TestDefaultEstimator(t *testing.T) {
 		})
 	}
 }
+
+func TestEstimatedPodUsedWithoutLimits(t *testing.T) {
+	resourceWeights := map[corev1.ResourceName]int64{
+		corev1.ResourceCPU:        1,
+		corev1.ResourceMemory:     1,
+		extension.ResourceGPUCore: 1,
+	}
+	scalingFactors := map[corev1.ResourceName]int64{
+		corev1.ResourceCPU:        85,
+		corev1.ResourceMemory:     70,
+		extension.ResourceGPUCore: 80,
+	}
+	tests := []struct {
+		name   string
+		limits corev1.ResourceList
+		want   map[corev1.ResourceName]int64
+	}{
+		{
+			name: "estimate requests without limits",
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:        3400,
+				corev1.ResourceMemory:     6012954214, // 5.6Gi
+				extension.ResourceGPUCore: 80,
+			},
+		},
+		{
+			name: "estimate requests with cpu and memory limits",
+			limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:        3400,
+				corev1.ResourceMemory:     6012954214, // 5.6Gi
+				extension.ResourceGPUCore: 80,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Limits: tt.limits,
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:        resource.MustParse("4"),
+									corev1.ResourceMemory:     resource.MustParse("8Gi"),
+									extension.ResourceGPUCore: resource.MustParse("100"),
+								},
+							},
+						},
+					},
+				},
+			}
+			got := estimatedPodUsed(pod, resourceWeights, scalingFactors, false, false)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEstimateWithoutInitContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{
+					Name: "load-data",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                          string
+		estimateWithoutInitContainers *bool
+		want                          map[corev1.ResourceName]int64
+	}{
+		{
+			name: "estimate with init containers by default",
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    3400,
+				corev1.ResourceMemory: 6012954214, // 5.6Gi
+			},
+		},
+		{
+			name:                          "estimate steady-state usage without init containers",
+			estimateWithoutInitContainers: pointer.Bool(true),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    850,
+				corev1.ResourceMemory: 1503238554, // 1.4Gi
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.EstimateWithoutInitContainers = tt.estimateWithoutInitContainers
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			estimator, err := NewDefaultEstimator(&loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+
+			got, err := estimator.Estimate(pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Len(t, pod.Spec.InitContainers, 1, "the pod should not be modified")
+		})
+	}
+}
+
+func TestEstimateWithLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		limits       corev1.ResourceList
+		estimateMode v1beta2.LoadAwareEstimateMode
+		want         map[corev1.ResourceName]int64
+	}{
+		{
+			name: "estimate by requests without limits by default",
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    3400,
+				corev1.ResourceMemory: 6012954214, // 5.6Gi
+			},
+		},
+		{
+			name:         "estimate by requests without limits when using limits",
+			estimateMode: v1beta2.LoadAwareEstimateModeUseLimits,
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    3400,
+				corev1.ResourceMemory: 6012954214, // 5.6Gi
+			},
+		},
+		{
+			name: "estimate by unscaled limits larger than requests by default",
+			limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    8000,
+				corev1.ResourceMemory: 17179869184, // 16Gi
+			},
+		},
+		{
+			name: "estimate by scaled limits when using limits",
+			limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+			estimateMode: v1beta2.LoadAwareEstimateModeUseLimits,
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    6800,
+				corev1.ResourceMemory: 12025908429, // 11.2Gi
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Limits: tt.limits,
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("4"),
+									corev1.ResourceMemory: resource.MustParse("8Gi"),
+								},
+							},
+						},
+					},
+				},
+			}
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.EstimateMode = tt.estimateMode
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			estimator, err := NewDefaultEstimator(&loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+
+			got, err := estimator.Estimate(pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEstimateWithQoSScalingFactors(t *testing.T) {
+	qosScalingFactors := map[corev1.PodQOSClass]map[corev1.ResourceName]int64{
+		corev1.PodQOSGuaranteed: {corev1.ResourceCPU: 100, corev1.ResourceMemory: 100},
+		corev1.PodQOSBurstable:  {corev1.ResourceCPU: 50},
+		corev1.PodQOSBestEffort: {corev1.ResourceCPU: 10, corev1.ResourceMemory: 10},
+	}
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	tests := []struct {
+		name              string
+		qosScalingFactors map[corev1.PodQOSClass]map[corev1.ResourceName]int64
+		requests          corev1.ResourceList
+		limits            corev1.ResourceList
+		want              map[corev1.ResourceName]int64
+	}{
+		{
+			name:     "Guaranteed pod without QoS scaling factors",
+			requests: requests,
+			limits:   requests,
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    3400,
+				corev1.ResourceMemory: 6012954214, // 5.6Gi
+			},
+		},
+		{
+			name:              "Guaranteed pod",
+			qosScalingFactors: qosScalingFactors,
+			requests:          requests,
+			limits:            requests,
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    4000,
+				corev1.ResourceMemory: 8589934592, // 8Gi
+			},
+		},
+		{
+			name:              "Burstable pod falls back to the default scaling factor of memory",
+			qosScalingFactors: qosScalingFactors,
+			requests:          requests,
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    2000,
+				corev1.ResourceMemory: 6012954214, // 5.6Gi
+			},
+		},
+		{
+			name:              "BestEffort pod is estimated by the default requests",
+			qosScalingFactors: qosScalingFactors,
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    DefaultMilliCPURequest,
+				corev1.ResourceMemory: DefaultMemoryRequest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Limits:   tt.limits,
+								Requests: tt.requests,
+							},
+						},
+					},
+				},
+			}
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.QoSEstimatedScalingFactors = tt.qosScalingFactors
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			estimator, err := NewDefaultEstimator(&loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+
+			got, err := estimator.Estimate(pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEstimateWithRespectZeroRequests(t *testing.T) {
+	tests := []struct {
+		name                string
+		requests            corev1.ResourceList
+		respectZeroRequests *bool
+		want                map[corev1.ResourceName]int64
+	}{
+		{
+			name: "explicit zero cpu request is defaulted by default",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("0"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    250,
+				corev1.ResourceMemory: 3006477107, // 2.8Gi
+			},
+		},
+		{
+			name: "explicit zero cpu request is respected",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("0"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			respectZeroRequests: pointer.Bool(true),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    0,
+				corev1.ResourceMemory: 3006477107, // 2.8Gi
+			},
+		},
+		{
+			name: "unset cpu request is defaulted even if zero requests are respected",
+			requests: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			respectZeroRequests: pointer.Bool(true),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    250,
+				corev1.ResourceMemory: 3006477107, // 2.8Gi
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: tt.requests,
+							},
+						},
+					},
+				},
+			}
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.RespectZeroRequests = tt.respectZeroRequests
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			estimator, err := NewDefaultEstimator(&loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+
+			got, err := estimator.Estimate(pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEstimateWithSidecarOverheads(t *testing.T) {
+	meshNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mesh",
+			Labels: map[string]string{
+				"istio-injection": "enabled",
+			},
+		},
+	}
+	plainNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "plain",
+		},
+	}
+	newPod := func(namespace string, containerNames ...string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "test-pod",
+			},
+		}
+		for _, containerName := range containerNames {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+				Name: containerName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+			})
+		}
+		return pod
+	}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want map[corev1.ResourceName]int64
+	}{
+		{
+			name: "pod in the mesh-injected namespace gets the overhead",
+			pod:  newPod("mesh", "main"),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    3400 + 500,
+				corev1.ResourceMemory: 3006477107 + 256*1024*1024,
+			},
+		},
+		{
+			name: "pod with the sidecar injected already",
+			pod:  newPod("mesh", "main", "istio-proxy"),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    6800,
+				corev1.ResourceMemory: 6012954214,
+			},
+		},
+		{
+			name: "pod in the namespace not selected",
+			pod:  newPod("plain", "main"),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    3400,
+				corev1.ResourceMemory: 3006477107,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(meshNamespace, plainNamespace), 0)
+			fh, err := frameworkruntime.NewFramework(nil, nil, frameworkruntime.WithInformerFactory(informerFactory))
+			assert.NoError(t, err)
+
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.SidecarOverheads = []v1beta2.LoadAwareSidecarOverhead{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"istio-injection": "enabled",
+						},
+					},
+					ContainerName: "istio-proxy",
+					Overhead: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+				},
+			}
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err = v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			estimator, err := NewDefaultEstimator(&loadAwareSchedulingArgs, fh)
+			assert.NoError(t, err)
+			informerFactory.Start(ctx.Done())
+			informerFactory.WaitForCacheSync(ctx.Done())
+
+			got, err := estimator.Estimate(tt.pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
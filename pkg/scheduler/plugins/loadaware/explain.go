@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// NodeExplanation explains the load-aware scheduling outcome of a pod on a node.
+type NodeExplanation struct {
+	NodeName string `json:"nodeName"`
+	// Feasible is false if the node is rejected by Filter, and Reason tells why.
+	Feasible bool   `json:"feasible"`
+	Reason   string `json:"reason,omitempty"`
+	// Score is the final score of the node, including the preferred node affinity bonus.
+	Score             int64 `json:"score"`
+	NodeAffinityScore int64 `json:"nodeAffinityScore,omitempty"`
+	// Resources is the score breakdown by the weighted resources,
+	// and it is empty if the node is skipped in scoring.
+	Resources []ResourceScoreExplanation `json:"resources,omitempty"`
+}
+
+// ResourceScoreExplanation explains the score of a weighted resource on a node.
+type ResourceScoreExplanation struct {
+	ResourceName corev1.ResourceName `json:"resourceName"`
+	// Weight is the weight in effect for the pod, after the priority class weights, the default weights
+	// and the dynamic boosts are applied. The boosted weights are scaled by 100.
+	Weight int64 `json:"weight"`
+	// EstimatedUsed is the estimated used of the node after the pod is scheduled,
+	// in milli-cores for CPU and bytes for the others.
+	EstimatedUsed int64 `json:"estimatedUsed"`
+	Allocatable   int64 `json:"allocatable"`
+	Score         int64 `json:"score"`
+}
+
+// Explain explains the load-aware scheduling outcome of the pod on each node.
+// It runs the same Filter, PreScore, Score and NormalizeScore code as the scheduling cycle with the args in effect,
+// but over a snapshot built of the node and pod listers, because the scheduler snapshot is only safe to read
+// in the scheduling cycle. The explanation records no metrics, and the pod is not counted as assigned
+// if it is already bound.
+func (p *Plugin) Explain(ctx context.Context, pod *corev1.Pod) ([]NodeExplanation, error) {
+	explainer, err := p.newExplainer(pod)
+	if err != nil {
+		return nil, err
+	}
+	nodeInfos, err := explainer.handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+
+	args := explainer.getArgs()
+	explanations := make([]NodeExplanation, 0, len(nodeInfos))
+	cycleState := framework.NewCycleState()
+	cycleState.Write(filterStateKey, &filterState{reasons: map[string]Reason{}, softBreaches: map[string]Reason{}})
+	var feasibleNodes []*corev1.Node
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		explanation := NodeExplanation{NodeName: node.Name}
+		// filter is called instead of Filter to skip the metrics of the scheduling cycle.
+		reason, status := explainer.filter(pod, nodeInfo)
+		switch {
+		case !status.IsSuccess():
+			explanation.Reason = status.Message()
+		case reason != nil && !(args.SoftThreshold && isThresholdReason(reason.Code)):
+			recordFilterReason(cycleState, node.Name, *reason)
+			explanation.Reason = reason.Message()
+		default:
+			if reason != nil {
+				recordSoftThresholdBreach(cycleState, node.Name, *reason)
+			}
+			explanation.Feasible = true
+			feasibleNodes = append(feasibleNodes, node)
+		}
+		explanations = append(explanations, explanation)
+	}
+	if len(feasibleNodes) == 0 {
+		return sortNodeExplanations(explanations), nil
+	}
+
+	if status := explainer.PreScore(ctx, cycleState, pod, feasibleNodes); !status.IsSuccess() {
+		return nil, status.AsError()
+	}
+	// the details behind the scores are only recorded for the explanation.
+	getScoreState(cycleState).details = map[string]*nodeScoreDetail{}
+	scores := make(framework.NodeScoreList, 0, len(feasibleNodes))
+	scoreStatuses := map[string]*framework.Status{}
+	for _, node := range feasibleNodes {
+		score, status := explainer.score(ctx, cycleState, pod, node.Name)
+		scoreStatuses[node.Name] = status
+		scores = append(scores, framework.NodeScore{Name: node.Name, Score: score})
+	}
+	if scoreExtensions := explainer.ScoreExtensions(); scoreExtensions != nil {
+		if status := scoreExtensions.NormalizeScore(ctx, cycleState, pod, scores); !status.IsSuccess() {
+			return nil, status.AsError()
+		}
+	}
+
+	scoreState := getScoreState(cycleState).Clone().(*scoreState)
+	explanationIndexes := make(map[string]int, len(explanations))
+	for i := range explanations {
+		explanationIndexes[explanations[i].NodeName] = i
+	}
+	for _, nodeScore := range scores {
+		explanation := &explanations[explanationIndexes[nodeScore.Name]]
+		explanation.Score = nodeScore.Score
+		if status := scoreStatuses[nodeScore.Name]; !status.IsSuccess() {
+			explanation.Reason = status.Message()
+		} else if reason, ok := scoreState.reasons[nodeScore.Name]; ok {
+			explanation.Reason = reason.Message()
+		}
+		detail := scoreState.details[nodeScore.Name]
+		if detail == nil {
+			continue
+		}
+		for resourceName, weight := range detail.resourceWeights {
+			scorer := resourceScorerFor(args.LoadAwareSchedulingArgs, resourceName)
+			explanation.Resources = append(explanation.Resources, ResourceScoreExplanation{
+				ResourceName:  resourceName,
+				Weight:        effectiveResourceWeight(args.LoadAwareSchedulingArgs, resourceName, weight, detail),
+				EstimatedUsed: detail.estimatedUsed[resourceName],
+				Allocatable:   detail.allocatable[resourceName],
+				Score:         scorer(detail.podEstimatedUsed[resourceName], detail.estimatedUsed[resourceName], detail.allocatable[resourceName]),
+			})
+		}
+		sort.Slice(explanation.Resources, func(i, j int) bool {
+			return explanation.Resources[i].ResourceName < explanation.Resources[j].ResourceName
+		})
+		if args.PreferredNodeAffinityWeight > 0 {
+			nodeInfo, err := explainer.handle.SnapshotSharedLister().NodeInfos().Get(nodeScore.Name)
+			if err == nil {
+				explanation.NodeAffinityScore = preferredNodeAffinityScore(pod, nodeInfo.Node(), args.PreferredNodeAffinityWeight)
+			}
+		}
+	}
+	return sortNodeExplanations(explanations), nil
+}
+
+func sortNodeExplanations(explanations []NodeExplanation) []NodeExplanation {
+	sort.Slice(explanations, func(i, j int) bool {
+		return explanations[i].NodeName < explanations[j].NodeName
+	})
+	return explanations
+}
+
+// explainHandle is the handle reading the snapshot built for the explanation.
+type explainHandle struct {
+	framework.Handle
+	snapshot framework.SharedLister
+}
+
+func (h *explainHandle) SnapshotSharedLister() framework.SharedLister {
+	return h.snapshot
+}
+
+// newExplainer returns the plugin sharing the listers, the caches and the args in effect with p,
+// but reading the snapshot of the nodes and the pods assigned to them in the listers except the explained pod.
+func (p *Plugin) newExplainer(explainedPod *corev1.Pod) (*Plugin, error) {
+	nodes, err := p.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	pods, err := p.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	assignedPods := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" && !util.IsPodTerminated(pod) && !isSamePod(pod, explainedPod) {
+			assignedPods = append(assignedPods, pod)
+		}
+	}
+	explainer := &Plugin{
+		handle:                     &explainHandle{Handle: p.handle, snapshot: newReplaySnapshot(nodes, assignedPods)},
+		podLister:                  p.podLister,
+		nodeMetricLister:           p.nodeMetricLister,
+		nodeMetricIndexer:          p.nodeMetricIndexer,
+		nodeLister:                 p.nodeLister,
+		usageThresholdPolicyLister: p.usageThresholdPolicyLister,
+		podAssignCache:             p.podAssignCache,
+		fallbackNodeMetricProvider: p.fallbackNodeMetricProvider,
+		unschedulableAttempts:      p.unschedulableAttempts,
+		reservationIndexer:         p.reservationIndexer,
+		dynamicResourceWeights:     p.dynamicResourceWeights,
+		latestNodeMetricUpdateTime: p.latestNodeMetricUpdateTime,
+		staticArgs:                 p.staticArgs,
+		explainedPod:               explainedPod,
+	}
+	explainer.args.Store(p.getArgs())
+	return explainer, nil
+}
+
+// isExplainedPod returns true if the plugin is an explainer and the pod is the explained pod.
+func (p *Plugin) isExplainedPod(pod *corev1.Pod) bool {
+	return p.explainedPod != nil && isSamePod(pod, p.explainedPod)
+}
+
+func isSamePod(a, b *corev1.Pod) bool {
+	if a.UID != "" && b.UID != "" {
+		return a.UID == b.UID
+	}
+	return a.Namespace == b.Namespace && a.Name == b.Name
+}
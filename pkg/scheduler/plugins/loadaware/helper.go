@@ -17,15 +17,25 @@ limitations under the License.
 package loadaware
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
 	"k8s.io/klog/v2"
+	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
@@ -37,11 +47,50 @@ func isNodeMetricExpired(nodeMetric *slov1alpha1.NodeMetric, nodeMetricExpiratio
 	return nodeMetric == nil ||
 		nodeMetric.Status.UpdateTime == nil ||
 		nodeMetricExpirationSeconds > 0 &&
-			time.Since(nodeMetric.Status.UpdateTime.Time) >= time.Duration(nodeMetricExpirationSeconds)*time.Second
+			time.Since(getNodeMetricUpdateTime(nodeMetric)) >= time.Duration(nodeMetricExpirationSeconds)*time.Second
 }
 
-func getNodeMetricReportInterval(nodeMetric *slov1alpha1.NodeMetric) time.Duration {
+// nodeMetricClockSkewTolerance is the duration the UpdateTime of NodeMetric is expected to be ahead of the scheduler
+// at most, e.g. by the clock drift of the node, beyond which the clock of the node is considered skewed.
+const nodeMetricClockSkewTolerance = 30 * time.Second
+
+// getNodeMetricUpdateTime returns the UpdateTime of the NodeMetric, or the zero time if it is not set.
+// The UpdateTime in the future, which is reported by the node whose clock is ahead of the scheduler, is treated
+// as now, so that the NodeMetric is regarded as just updated rather than misleading the report interval logic.
+func getNodeMetricUpdateTime(nodeMetric *slov1alpha1.NodeMetric) time.Time {
+	if nodeMetric.Status.UpdateTime == nil {
+		return time.Time{}
+	}
+	updateTime := nodeMetric.Status.UpdateTime.Time
+	now := time.Now()
+	if skew := updateTime.Sub(now); skew > 0 {
+		if skew > nodeMetricClockSkewTolerance {
+			klog.V(2).InfoS("UpdateTime of NodeMetric is ahead of the scheduler beyond the clock skew tolerance, treat it as now",
+				"nodeMetric", nodeMetric.Name, "skew", skew, "tolerance", nodeMetricClockSkewTolerance)
+		}
+		return now
+	}
+	return updateTime
+}
+
+// isTransientError returns true if the error is expected to go away by retrying,
+// e.g. the informer is resyncing or the apiserver is temporarily unavailable.
+func isTransientError(err error) bool {
+	return apierrors.IsTimeout(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// getNodeMetricReportInterval returns the report interval of the NodeMetric, which falls back to
+// DefaultNodeMetricReportIntervalSeconds if the CollectPolicy of the NodeMetric does not set it.
+func getNodeMetricReportInterval(args *schedulingconfig.LoadAwareSchedulingArgs, nodeMetric *slov1alpha1.NodeMetric) time.Duration {
 	if nodeMetric.Spec.CollectPolicy == nil || nodeMetric.Spec.CollectPolicy.ReportIntervalSeconds == nil {
+		if args.DefaultNodeMetricReportIntervalSeconds != nil {
+			return time.Duration(*args.DefaultNodeMetricReportIntervalSeconds) * time.Second
+		}
 		return DefaultNodeMetricReportInterval
 	}
 	return time.Duration(*nodeMetric.Spec.CollectPolicy.ReportIntervalSeconds) * time.Second
@@ -55,7 +104,17 @@ func stillInTheReportInterval(assignedTime, updateTime time.Time, reportInterval
 	return assignedTime.Before(updateTime) && updateTime.Sub(assignedTime) < reportInterval
 }
 
-func getTargetAggregatedUsage(nodeMetric *slov1alpha1.NodeMetric, aggregatedDuration *metav1.Duration, aggregationType slov1alpha1.AggregationType) *slov1alpha1.ResourceMap {
+// reportUncoveredFraction returns the fraction of the report interval before the update that the Pod assigned
+// within the interval is absent from, which is the part not covered by the reported usage of the Pod.
+func reportUncoveredFraction(assignedTime, updateTime time.Time, reportInterval time.Duration) float64 {
+	if reportInterval <= 0 {
+		return 1
+	}
+	return 1 - float64(updateTime.Sub(assignedTime))/float64(reportInterval)
+}
+
+// getTargetAggregatedUsage returns nil if the aggregated usage is not reported or has fewer samples than minSampleCount.
+func getTargetAggregatedUsage(nodeMetric *slov1alpha1.NodeMetric, aggregatedDuration *metav1.Duration, aggregationType slov1alpha1.AggregationType, minSampleCount int64) *slov1alpha1.ResourceMap {
 	if nodeMetric.Status.NodeMetric == nil || len(nodeMetric.Status.NodeMetric.AggregatedNodeUsages) == 0 {
 		return nil
 	}
@@ -73,14 +132,14 @@ func getTargetAggregatedUsage(nodeMetric *slov1alpha1.NodeMetric, aggregatedDura
 		}
 		aggregatedNodeUsage := &nodeMetric.Status.NodeMetric.AggregatedNodeUsages[maxIndex]
 		usage := aggregatedNodeUsage.Usage[aggregationType]
-		if len(usage.ResourceList) > 0 {
+		if len(usage.ResourceList) > 0 && aggregatedNodeUsage.SampleCount >= minSampleCount {
 			return &usage
 		}
 	} else if aggregatedDuration != nil {
 		for _, v := range nodeMetric.Status.NodeMetric.AggregatedNodeUsages {
 			if v.Duration.Duration == aggregatedDuration.Duration {
 				usage := v.Usage[aggregationType]
-				if len(usage.ResourceList) > 0 {
+				if len(usage.ResourceList) > 0 && v.SampleCount >= minSampleCount {
 					return &usage
 				}
 			}
@@ -89,6 +148,36 @@ func getTargetAggregatedUsage(nodeMetric *slov1alpha1.NodeMetric, aggregatedDura
 	return nil
 }
 
+// getResourcesAggregatedUsage returns the aggregated usage of the aggregationType, whose usages of the resources
+// in resourceAggregationTypes are replaced by the ones of their own percentile types if reported.
+func getResourcesAggregatedUsage(nodeMetric *slov1alpha1.NodeMetric, aggregatedDuration *metav1.Duration, aggregationType slov1alpha1.AggregationType,
+	resourceAggregationTypes map[corev1.ResourceName]slov1alpha1.AggregationType, minSampleCount int64) *slov1alpha1.ResourceMap {
+	usage := getTargetAggregatedUsage(nodeMetric, aggregatedDuration, aggregationType, minSampleCount)
+	if len(resourceAggregationTypes) == 0 {
+		return usage
+	}
+	merged := &slov1alpha1.ResourceMap{}
+	if usage != nil {
+		merged = usage.DeepCopy()
+	}
+	for resourceName, resourceAggregationType := range resourceAggregationTypes {
+		resourceUsage := getTargetAggregatedUsage(nodeMetric, aggregatedDuration, resourceAggregationType, minSampleCount)
+		if resourceUsage == nil {
+			continue
+		}
+		if quantity, ok := resourceUsage.ResourceList[resourceName]; ok {
+			if merged.ResourceList == nil {
+				merged.ResourceList = corev1.ResourceList{}
+			}
+			merged.ResourceList[resourceName] = quantity.DeepCopy()
+		}
+	}
+	if len(merged.ResourceList) == 0 {
+		return nil
+	}
+	return merged
+}
+
 func filterWithAggregation(args *schedulingconfig.LoadAwareSchedulingAggregatedArgs) bool {
 	return args != nil && len(args.UsageThresholds) > 0 && args.UsageAggregationType != ""
 }
@@ -97,12 +186,90 @@ func scoreWithAggregation(args *schedulingconfig.LoadAwareSchedulingAggregatedAr
 	return args != nil && args.ScoreAggregationType != ""
 }
 
+func getMinSampleCount(args *schedulingconfig.LoadAwareSchedulingAggregatedArgs) int64 {
+	if args == nil {
+		return 0
+	}
+	return args.MinSampleCount
+}
+
+func getResourceAggregationTypes(args *schedulingconfig.LoadAwareSchedulingAggregatedArgs) map[corev1.ResourceName]slov1alpha1.AggregationType {
+	if args == nil {
+		return nil
+	}
+	return args.ResourceAggregationTypes
+}
+
 type usageThresholdsFilterProfile = extension.CustomUsageThresholds
 
-func generateUsageThresholdsFilterProfile(node *corev1.Node, args *schedulingconfig.LoadAwareSchedulingArgs) *usageThresholdsFilterProfile {
+// ValidateCustomUsageThresholds returns the errors of the custom usage thresholds of a node that reference
+// the resources neither weighted nor limited by the args, because such thresholds are likely to do nothing,
+// e.g. the usage of the resource is not reported by koordlet.
+func ValidateCustomUsageThresholds(customUsageThresholds *extension.CustomUsageThresholds, args *schedulingconfig.LoadAwareSchedulingArgs) field.ErrorList {
+	if customUsageThresholds == nil {
+		return nil
+	}
+	configured := sets.NewString()
+	for _, resources := range []map[corev1.ResourceName]int64{args.ResourceWeights, args.UsageThresholds, args.ProdUsageThresholds} {
+		for resourceName := range resources {
+			configured.Insert(string(resourceName))
+		}
+	}
+	if args.Aggregated != nil {
+		for resourceName := range args.Aggregated.UsageThresholds {
+			configured.Insert(string(resourceName))
+		}
+	}
+
+	var allErrs field.ErrorList
+	validate := func(path *field.Path, thresholds map[corev1.ResourceName]int64) {
+		resourceNames := make([]string, 0, len(thresholds))
+		for resourceName := range thresholds {
+			resourceNames = append(resourceNames, string(resourceName))
+		}
+		sort.Strings(resourceNames)
+		for _, resourceName := range resourceNames {
+			if !configured.Has(resourceName) {
+				allErrs = append(allErrs, field.NotSupported(path.Key(resourceName), resourceName, configured.List()))
+			}
+		}
+	}
+	validate(field.NewPath("usageThresholds"), customUsageThresholds.UsageThresholds)
+	validate(field.NewPath("prodUsageThresholds"), customUsageThresholds.ProdUsageThresholds)
+	if customUsageThresholds.AggregatedUsage != nil {
+		validate(field.NewPath("aggregatedUsage", "usageThresholds"), customUsageThresholds.AggregatedUsage.UsageThresholds)
+	}
+	return allErrs
+}
+
+// customUsageThresholdsConflicts stores the last conflict of the custom usage thresholds logged for each node.
+var customUsageThresholdsConflicts sync.Map
+
+// logCustomUsageThresholdsConflict logs the conflict of the custom usage thresholds of the node with the args
+// regardless of the verbosity. The thresholds are validated on every read, and the conflict is only logged
+// when it changes, so that reading the same thresholds of the node does not flood the log.
+func logCustomUsageThresholdsConflict(nodeName string, errs field.ErrorList) {
+	if len(errs) == 0 {
+		customUsageThresholdsConflicts.Delete(nodeName)
+		return
+	}
+	conflict := errs.ToAggregate().Error()
+	if last, ok := customUsageThresholdsConflicts.Load(nodeName); ok && last.(string) == conflict {
+		return
+	}
+	customUsageThresholdsConflicts.Store(nodeName, conflict)
+	klog.InfoS("Custom usage thresholds of node reference resources not configured in LoadAwareScheduling",
+		"node", nodeName, "err", conflict)
+}
+
+// EffectiveUsageThresholds returns the usage thresholds of the node as Filter applies them. The custom usage thresholds
+// in the node annotation take precedence over the args, falling back to the args if the annotation is malformed,
+// and the MandatoryThresholds in args are always applied.
+func EffectiveUsageThresholds(node *corev1.Node, args *schedulingconfig.LoadAwareSchedulingArgs) *extension.CustomUsageThresholds {
 	usageThresholds, prodUsageThresholds := args.UsageThresholds, args.ProdUsageThresholds
 	customUsageThresholds, err := extension.GetCustomUsageThresholds(node)
 	if err != nil {
+		// the errors are counted by the node event handler once per change of the annotation.
 		klog.V(5).ErrorS(err, "failed to GetCustomUsageThresholds from", "node", node.Name)
 		customUsageThresholds = &extension.CustomUsageThresholds{
 			UsageThresholds:     usageThresholds,
@@ -116,6 +283,7 @@ func generateUsageThresholdsFilterProfile(node *corev1.Node, args *schedulingcon
 			}
 		}
 	} else {
+		logCustomUsageThresholdsConflict(node.Name, ValidateCustomUsageThresholds(customUsageThresholds, args))
 		if len(customUsageThresholds.UsageThresholds) == 0 {
 			customUsageThresholds.UsageThresholds = usageThresholds
 		}
@@ -136,9 +304,93 @@ func generateUsageThresholdsFilterProfile(node *corev1.Node, args *schedulingcon
 			}
 		}
 	}
+	if len(args.MandatoryThresholds) > 0 {
+		customUsageThresholds.UsageThresholds = mergeMandatoryThresholds(customUsageThresholds.UsageThresholds, args.MandatoryThresholds)
+		if customUsageThresholds.AggregatedUsage != nil {
+			aggregatedUsage := *customUsageThresholds.AggregatedUsage
+			aggregatedUsage.UsageThresholds = mergeMandatoryThresholds(aggregatedUsage.UsageThresholds, args.MandatoryThresholds)
+			customUsageThresholds.AggregatedUsage = &aggregatedUsage
+		}
+	}
 	return customUsageThresholds
 }
 
+// getFilterUsageThresholds returns the usage thresholds filtering the node usage and the reason code of the rejection,
+// the aggregated usage thresholds take precedence over the usage thresholds if set.
+func getFilterUsageThresholds(filterProfile *usageThresholdsFilterProfile) (map[corev1.ResourceName]int64, ReasonCode) {
+	if filterProfile.AggregatedUsage != nil {
+		return filterProfile.AggregatedUsage.UsageThresholds, ReasonCodeAggregatedUsageExceedThreshold
+	}
+	return filterProfile.UsageThresholds, ReasonCodeUsageExceedThreshold
+}
+
+// mergeMandatoryThresholds returns a new thresholds map with the mandatory thresholds,
+// and the stricter one is used if a resource has both thresholds.
+func mergeMandatoryThresholds(thresholds, mandatoryThresholds map[corev1.ResourceName]int64) map[corev1.ResourceName]int64 {
+	merged := make(map[corev1.ResourceName]int64, len(thresholds)+len(mandatoryThresholds))
+	for resourceName, threshold := range thresholds {
+		merged[resourceName] = threshold
+	}
+	for resourceName, threshold := range mandatoryThresholds {
+		if threshold == 0 {
+			continue
+		}
+		if current := merged[resourceName]; current == 0 || threshold < current {
+			merged[resourceName] = threshold
+		}
+	}
+	return merged
+}
+
+// relaxUsageThresholds returns a copy of the filter profile with the usage thresholds relaxed by the percentage points
+// up to 100%, and the mandatory thresholds are applied again so that they are never relaxed.
+func relaxUsageThresholds(filterProfile *usageThresholdsFilterProfile, relaxation int64, mandatoryThresholds map[corev1.ResourceName]int64) *usageThresholdsFilterProfile {
+	if relaxation <= 0 {
+		return filterProfile
+	}
+	relaxed := *filterProfile
+	relaxed.UsageThresholds = mergeMandatoryThresholds(relaxThresholds(filterProfile.UsageThresholds, relaxation), mandatoryThresholds)
+	relaxed.ProdUsageThresholds = relaxThresholds(filterProfile.ProdUsageThresholds, relaxation)
+	if filterProfile.AggregatedUsage != nil {
+		aggregatedUsage := *filterProfile.AggregatedUsage
+		aggregatedUsage.UsageThresholds = mergeMandatoryThresholds(relaxThresholds(aggregatedUsage.UsageThresholds, relaxation), mandatoryThresholds)
+		relaxed.AggregatedUsage = &aggregatedUsage
+	}
+	return &relaxed
+}
+
+func relaxThresholds(thresholds map[corev1.ResourceName]int64, relaxation int64) map[corev1.ResourceName]int64 {
+	if len(thresholds) == 0 {
+		return thresholds
+	}
+	relaxed := make(map[corev1.ResourceName]int64, len(thresholds))
+	for resourceName, threshold := range thresholds {
+		// the zero threshold disables the resource rather than rejecting all nodes.
+		if threshold > 0 {
+			threshold += relaxation
+			if threshold > 100 {
+				threshold = 100
+			}
+		}
+		relaxed[resourceName] = threshold
+	}
+	return relaxed
+}
+
+// sortedResourceNames returns the resources of the thresholds in order, so that the node exceeding
+// multiple thresholds is always rejected by the same resource and the identical reasons of the nodes
+// are aggregated by the scheduler.
+func sortedResourceNames(thresholds map[corev1.ResourceName]int64) []corev1.ResourceName {
+	resourceNames := make([]corev1.ResourceName, 0, len(thresholds))
+	for resourceName := range thresholds {
+		resourceNames = append(resourceNames, resourceName)
+	}
+	sort.Slice(resourceNames, func(i, j int) bool {
+		return resourceNames[i] < resourceNames[j]
+	})
+	return resourceNames
+}
+
 func getPodNamespacedName(namespace, name string) string {
 	return fmt.Sprintf("%s/%s", namespace, name)
 }
@@ -150,23 +402,210 @@ func getResourceValue(resourceName corev1.ResourceName, quantity resource.Quanti
 	return quantity.Value()
 }
 
-func buildPodMetricMap(podLister corev1listers.PodLister, nodeMetric *slov1alpha1.NodeMetric, filterProdPod bool) map[string]corev1.ResourceList {
+// nonPoolableResourcePrefixes is the prefixes of the resources whose usage is not comparable to the allocatable
+// like the poolable ones, e.g. the hugepages are preallocated to the Pods, so they are not scored by default.
+var nonPoolableResourcePrefixes = []string{
+	corev1.ResourceHugePagesPrefix,
+	corev1.ResourceAttachableVolumesPrefix,
+}
+
+func isNonPoolableResource(resourceName corev1.ResourceName) bool {
+	for _, prefix := range nonPoolableResourcePrefixes {
+		if strings.HasPrefix(string(resourceName), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutNonPoolableResourceWeights returns the args without the weights of the non-poolable resources
+// unless ScoreNonPoolableResources is enabled, and warns about the ignored ones. The weights are kept
+// if all weighted resources are non-poolable, otherwise no resource is scored.
+func withoutNonPoolableResourceWeights(args *schedulingconfig.LoadAwareSchedulingArgs) *schedulingconfig.LoadAwareSchedulingArgs {
+	if args.ScoreNonPoolableResources {
+		return args
+	}
+	var ignored []corev1.ResourceName
+	for resourceName := range args.ResourceWeights {
+		if isNonPoolableResource(resourceName) {
+			ignored = append(ignored, resourceName)
+		}
+	}
+	if len(ignored) == 0 || len(ignored) == len(args.ResourceWeights) {
+		return args
+	}
+	sort.Slice(ignored, func(i, j int) bool {
+		return ignored[i] < ignored[j]
+	})
+	klog.InfoS("Ignore the weights of the non-poolable resources in scoring, enable scoreNonPoolableResources to score them", "resources", ignored)
+	resourceWeights := make(map[corev1.ResourceName]int64, len(args.ResourceWeights)-len(ignored))
+	for resourceName, weight := range args.ResourceWeights {
+		if !isNonPoolableResource(resourceName) {
+			resourceWeights[resourceName] = weight
+		}
+	}
+	newArgs := *args
+	newArgs.ResourceWeights = resourceWeights
+	return &newArgs
+}
+
+// getPriorityClassResourceWeights returns the weights of resources for the priority class of the Pod, where the Pods
+// without priority class are regarded as the Prod ones. It returns false if PriorityClassResourceWeights is set but
+// misses the priority class or has no weights for it, and returns ResourceWeights if PriorityClassResourceWeights is not set.
+func getPriorityClassResourceWeights(args *schedulingconfig.LoadAwareSchedulingArgs, pod *corev1.Pod) (map[corev1.ResourceName]int64, bool) {
+	if len(args.PriorityClassResourceWeights) == 0 {
+		return args.ResourceWeights, true
+	}
+	priorityClass := extension.GetPriorityClass(pod)
+	if priorityClass == extension.PriorityNone {
+		priorityClass = extension.PriorityProd
+	}
+	resourceWeights := args.PriorityClassResourceWeights[priorityClass]
+	return resourceWeights, len(resourceWeights) > 0
+}
+
+// withPriorityClassResourceWeights returns the args weighting the resources by the weights of the priority class
+// of the Pod in PriorityClassResourceWeights.
+func withPriorityClassResourceWeights(args *loadAwareArgs, pod *corev1.Pod) *loadAwareArgs {
+	if len(args.PriorityClassResourceWeights) == 0 {
+		return args
+	}
+	resourceWeights, ok := getPriorityClassResourceWeights(args.LoadAwareSchedulingArgs, pod)
+	if !ok {
+		return args
+	}
+	schedulingArgs := *args.LoadAwareSchedulingArgs
+	schedulingArgs.ResourceWeights = resourceWeights
+	return &loadAwareArgs{LoadAwareSchedulingArgs: &schedulingArgs, estimator: args.estimator}
+}
+
+// withDefaultResourceWeights returns the args that also weight the resources requested by the Pod
+// but missing in ResourceWeights by DefaultResourceWeight.
+func withDefaultResourceWeights(args *loadAwareArgs, pod *corev1.Pod) *loadAwareArgs {
+	if args.DefaultResourceWeight <= 0 {
+		return args
+	}
+	// the resources translated from the weighted ones, e.g. the batch resources of the Batch Pods,
+	// are already estimated as the weighted ones.
+	priorityClass := extension.GetPriorityClass(pod)
+	weightedResources := sets.NewString()
+	for resourceName := range args.ResourceWeights {
+		weightedResources.Insert(string(resourceName), string(extension.TranslateResourceNameByPriorityClass(priorityClass, resourceName)))
+	}
+	requests, _ := resourceapi.PodRequestsAndLimits(pod)
+	var resourceWeights map[corev1.ResourceName]int64
+	for resourceName, quantity := range requests {
+		if quantity.IsZero() || weightedResources.Has(string(resourceName)) ||
+			(!args.ScoreNonPoolableResources && isNonPoolableResource(resourceName)) {
+			continue
+		}
+		if resourceWeights == nil {
+			resourceWeights = make(map[corev1.ResourceName]int64, len(args.ResourceWeights)+1)
+			for name, weight := range args.ResourceWeights {
+				resourceWeights[name] = weight
+			}
+		}
+		resourceWeights[resourceName] = args.DefaultResourceWeight
+	}
+	if resourceWeights == nil {
+		return args
+	}
+	schedulingArgs := *args.LoadAwareSchedulingArgs
+	schedulingArgs.ResourceWeights = resourceWeights
+	return &loadAwareArgs{LoadAwareSchedulingArgs: &schedulingArgs, estimator: args.estimator}
+}
+
+// estimatedUsedByRequests returns the requests of the Pod as the estimated used of the weighted resources
+// missing in the estimate, i.e. the resources weighted by DefaultResourceWeight.
+func estimatedUsedByRequests(resourceWeights map[corev1.ResourceName]int64, estimated map[corev1.ResourceName]int64, pod *corev1.Pod) map[corev1.ResourceName]int64 {
+	var requests corev1.ResourceList
+	var estimatedUsed map[corev1.ResourceName]int64
+	for resourceName := range resourceWeights {
+		if _, ok := estimated[resourceName]; ok {
+			continue
+		}
+		if requests == nil {
+			requests, _ = resourceapi.PodRequestsAndLimits(pod)
+		}
+		if quantity, ok := requests[resourceName]; ok {
+			if estimatedUsed == nil {
+				estimatedUsed = map[corev1.ResourceName]int64{}
+			}
+			estimatedUsed[resourceName] = getResourceValue(resourceName, quantity)
+		}
+	}
+	return estimatedUsed
+}
+
+// usagePercentage returns the percentage of the used to the total, the values of the byte resources
+// are used rather than the milli values, which overflow int64 on the nodes with huge memory.
+func usagePercentage(resourceName corev1.ResourceName, used, total resource.Quantity) float64 {
+	return float64(getResourceValue(resourceName, used)) / float64(getResourceValue(resourceName, total)) * 100
+}
+
+// getNodeInfoResourceValue returns the value of the resource in the unit of getResourceValue.
+func getNodeInfoResourceValue(resourceName corev1.ResourceName, r *framework.Resource) int64 {
+	switch resourceName {
+	case corev1.ResourceCPU:
+		return r.MilliCPU
+	case corev1.ResourceMemory:
+		return r.Memory
+	default:
+		return r.ScalarResources[resourceName]
+	}
+}
+
+// nodeUsages is the usages extracted from a NodeMetric in one pass,
+// so that the consumers select the relevant view without reading NodeMetric repeatedly.
+type nodeUsages struct {
+	// total is the usage of the node, nil if it is not reported.
+	total *slov1alpha1.ResourceMap
+	// podMetrics is the usages of the reported Pods that exist, indexed by the namespaced name.
+	podMetrics map[string]corev1.ResourceList
+	// prodPodMetrics is the subset of podMetrics of the Prod Pods.
+	prodPodMetrics map[string]corev1.ResourceList
+	// prod and batch are the sums of the usages of the Prod Pods and of the Batch and Free Pods.
+	prod  corev1.ResourceList
+	batch corev1.ResourceList
+}
+
+// extractNodeUsages extracts the total, Prod and Batch usages from the NodeMetric.
+func extractNodeUsages(podLister corev1listers.PodLister, nodeMetric *slov1alpha1.NodeMetric) *nodeUsages {
+	usages := &nodeUsages{}
+	if nodeMetric.Status.NodeMetric != nil {
+		usages.total = &nodeMetric.Status.NodeMetric.NodeUsage
+	}
 	if len(nodeMetric.Status.PodsMetric) == 0 {
-		return nil
+		return usages
 	}
-	podMetrics := make(map[string]corev1.ResourceList)
+	usages.podMetrics = make(map[string]corev1.ResourceList)
+	usages.prodPodMetrics = make(map[string]corev1.ResourceList)
+	usages.prod = make(corev1.ResourceList)
+	usages.batch = make(corev1.ResourceList)
 	for _, podMetric := range nodeMetric.Status.PodsMetric {
 		pod, err := podLister.Pods(podMetric.Namespace).Get(podMetric.Name)
 		if err != nil {
 			continue
 		}
-		if filterProdPod && extension.GetPriorityClass(pod) != extension.PriorityProd {
-			continue
-		}
 		name := getPodNamespacedName(podMetric.Namespace, podMetric.Name)
-		podMetrics[name] = podMetric.PodUsage.ResourceList
+		usages.podMetrics[name] = podMetric.PodUsage.ResourceList
+		switch {
+		case extension.GetPriorityClass(pod) == extension.PriorityProd:
+			usages.prodPodMetrics[name] = podMetric.PodUsage.ResourceList
+			util.AddResourceList(usages.prod, podMetric.PodUsage.ResourceList)
+		case isReclaimablePod(pod):
+			util.AddResourceList(usages.batch, podMetric.PodUsage.ResourceList)
+		}
+	}
+	return usages
+}
+
+// getPodMetrics returns the usages of the reported Pods, or of the reported Prod Pods only.
+func (u *nodeUsages) getPodMetrics(prodPod bool) map[string]corev1.ResourceList {
+	if prodPod {
+		return u.prodPodMetrics
 	}
-	return podMetrics
+	return u.podMetrics
 }
 
 func sumPodUsages(podMetrics map[string]corev1.ResourceList, estimatedPods sets.String) (podUsages, estimatedPodsUsages corev1.ResourceList) {
@@ -185,6 +624,359 @@ func sumPodUsages(podMetrics map[string]corev1.ResourceList, estimatedPods sets.
 	return podUsages, estimatedPodsUsages
 }
 
+// sumPodsMetricUsage sums the usages of all pods reported in NodeMetric.
+func sumPodsMetricUsage(nodeMetric *slov1alpha1.NodeMetric) *slov1alpha1.ResourceMap {
+	if len(nodeMetric.Status.PodsMetric) == 0 {
+		return nil
+	}
+	usage := make(corev1.ResourceList)
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		util.AddResourceList(usage, podMetric.PodUsage.ResourceList)
+	}
+	return &slov1alpha1.ResourceMap{ResourceList: usage}
+}
+
+// sumPodsAndSystemUsage sums the usages of the reported pods still existing and the SystemUsage reported in NodeMetric,
+// so that the pods deleted since NodeMetric was reported are not counted, which are still in NodeUsage until the next
+// report. It returns nil if NodeMetric has no pods metric or no system usage, e.g. reported by the old koordlet.
+func sumPodsAndSystemUsage(podLister corev1listers.PodLister, nodeMetric *slov1alpha1.NodeMetric) *slov1alpha1.ResourceMap {
+	if nodeMetric.Status.NodeMetric == nil || len(nodeMetric.Status.NodeMetric.SystemUsage.ResourceList) == 0 ||
+		len(nodeMetric.Status.PodsMetric) == 0 {
+		return nil
+	}
+	usage := nodeMetric.Status.NodeMetric.SystemUsage.ResourceList.DeepCopy()
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		if _, err := podLister.Pods(podMetric.Namespace).Get(podMetric.Name); err != nil {
+			continue
+		}
+		util.AddResourceList(usage, podMetric.PodUsage.ResourceList)
+	}
+	return &slov1alpha1.ResourceMap{ResourceList: usage}
+}
+
+// discountMemoryCache returns a copy of the usage with the memory usage discounted by the ratio,
+// which is regarded as the reclaimable page cache. The usage is returned as is if the ratio is 0.
+func discountMemoryCache(usage *slov1alpha1.ResourceMap, discountRatio int64) *slov1alpha1.ResourceMap {
+	if usage == nil || discountRatio <= 0 {
+		return usage
+	}
+	memory, ok := usage.ResourceList[corev1.ResourceMemory]
+	if !ok {
+		return usage
+	}
+	discounted := usage.DeepCopy()
+	discounted.ResourceList[corev1.ResourceMemory] = *resource.NewQuantity(memory.Value()*(100-discountRatio)/100, memory.Format)
+	return discounted
+}
+
+// excludeSystemUsage returns a copy of the usage without the system usage, which is the node usage
+// not accounted to the reported Pods, e.g. the kubelet, the container runtime and the other system processes.
+// The usage is returned as is if NodeMetric has no pods metric.
+func excludeSystemUsage(usage *slov1alpha1.ResourceMap, nodeMetric *slov1alpha1.NodeMetric) *slov1alpha1.ResourceMap {
+	podsUsage := sumPodsMetricUsage(nodeMetric)
+	if usage == nil || podsUsage == nil || nodeMetric.Status.NodeMetric == nil {
+		return usage
+	}
+	excluded := usage.DeepCopy()
+	for resourceName, quantity := range excluded.ResourceList {
+		// the quantity of the shared NodeMetric must be copied before Sub, which may modify its inf.Dec in place.
+		systemUsage := nodeMetric.Status.NodeMetric.NodeUsage.ResourceList[resourceName].DeepCopy()
+		systemUsage.Sub(podsUsage.ResourceList[resourceName])
+		if systemUsage.Sign() <= 0 {
+			continue
+		}
+		quantity.Sub(systemUsage)
+		if quantity.Sign() < 0 {
+			quantity = *resource.NewQuantity(0, quantity.Format)
+		}
+		excluded.ResourceList[resourceName] = quantity
+	}
+	return excluded
+}
+
+// discountReclaimableUsage discounts the usage of the Batch and Free Pods in the node usage
+// to the percentage of reclaimableUsageWeight.
+func discountReclaimableUsage(resourceName corev1.ResourceName, used int64, reclaimableUsages corev1.ResourceList, reclaimableUsageWeight int64) int64 {
+	reclaimable, ok := reclaimableUsages[resourceName]
+	if !ok {
+		return used
+	}
+	used -= getResourceValue(resourceName, reclaimable) * (100 - reclaimableUsageWeight) / 100
+	if used < 0 {
+		return 0
+	}
+	return used
+}
+
+// filterByProdUsage returns whether the Pod is filtered by the usage of the Prod Pods against ProdUsageThresholds
+// rather than by the node usage, which is only for the Prod Pods unless FilterUsageType is Total.
+func filterByProdUsage(args *schedulingconfig.LoadAwareSchedulingArgs, filterProfile *usageThresholdsFilterProfile, pod *corev1.Pod) bool {
+	return args.FilterUsageType != schedulingconfig.LoadAwareUsageTypeTotal && len(filterProfile.ProdUsageThresholds) > 0 &&
+		extension.GetPriorityClass(pod) == extension.PriorityProd
+}
+
+// scoreByProdUsage returns whether the Pod is scored by the usage of the Prod Pods rather than the node usage,
+// which is decided by ScoreUsageType if set, or else by ScoreAccordingProdUsage.
+func scoreByProdUsage(args *schedulingconfig.LoadAwareSchedulingArgs, pod *corev1.Pod) bool {
+	if extension.GetPriorityClass(pod) != extension.PriorityProd {
+		return false
+	}
+	if args.ScoreUsageType != "" {
+		return args.ScoreUsageType == schedulingconfig.LoadAwareUsageTypeProd
+	}
+	return args.ScoreAccordingProdUsage
+}
+
+// getBatchResourceNames returns the batch resources translated from the weighted resources for the Batch
+// and Free Pods, indexed by the weighted resources. It returns nil if ScoreBatchPodByBatchResources is disabled
+// or no weighted resource is translated.
+func getBatchResourceNames(args *schedulingconfig.LoadAwareSchedulingArgs, pod *corev1.Pod) map[corev1.ResourceName]corev1.ResourceName {
+	if !args.ScoreBatchPodByBatchResources {
+		return nil
+	}
+	priorityClass := extension.GetPriorityClass(pod)
+	var resourceNames map[corev1.ResourceName]corev1.ResourceName
+	for resourceName := range args.ResourceWeights {
+		batchResourceName := extension.TranslateResourceNameByPriorityClass(priorityClass, resourceName)
+		if batchResourceName == "" || batchResourceName == resourceName {
+			continue
+		}
+		if resourceNames == nil {
+			resourceNames = make(map[corev1.ResourceName]corev1.ResourceName, len(args.ResourceWeights))
+		}
+		resourceNames[resourceName] = batchResourceName
+	}
+	return resourceNames
+}
+
+// getPriorityClassAllocatableResourceNames returns the resources of the allocatable pools of the priority class
+// of the Pod advertised by the node, e.g. kubernetes.io/batch-cpu for the Batch Pods, indexed by the weighted
+// resources. It returns nil if ScoreAccordingPriorityClassAllocatable is disabled or no pool is advertised.
+func getPriorityClassAllocatableResourceNames(args *schedulingconfig.LoadAwareSchedulingArgs, pod *corev1.Pod, nodeAllocatable corev1.ResourceList) map[corev1.ResourceName]corev1.ResourceName {
+	if !args.ScoreAccordingPriorityClassAllocatable {
+		return nil
+	}
+	priorityClass := extension.GetPriorityClass(pod)
+	var resourceNames map[corev1.ResourceName]corev1.ResourceName
+	for resourceName := range args.ResourceWeights {
+		priorityClassResourceName := extension.TranslateResourceNameByPriorityClass(priorityClass, resourceName)
+		if priorityClassResourceName == "" || priorityClassResourceName == resourceName {
+			continue
+		}
+		if _, ok := nodeAllocatable[priorityClassResourceName]; !ok {
+			continue
+		}
+		if resourceNames == nil {
+			resourceNames = make(map[corev1.ResourceName]corev1.ResourceName, len(args.ResourceWeights))
+		}
+		resourceNames[resourceName] = priorityClassResourceName
+	}
+	return resourceNames
+}
+
+// translateBatchUsage returns the usage where the weighted resources are replaced by the reported usages
+// of the batch resources, which are converted to the units of the weighted resources. The weighted resources
+// whose batch resources are not reported keep their usages.
+func translateBatchUsage(usage *slov1alpha1.ResourceMap, batchResourceNames map[corev1.ResourceName]corev1.ResourceName) *slov1alpha1.ResourceMap {
+	if usage == nil || len(batchResourceNames) == 0 {
+		return usage
+	}
+	translated := usage.DeepCopy()
+	for resourceName, batchResourceName := range batchResourceNames {
+		quantity, ok := translated.ResourceList[batchResourceName]
+		if !ok {
+			continue
+		}
+		delete(translated.ResourceList, batchResourceName)
+		value := getResourceValue(batchResourceName, quantity)
+		if resourceName == corev1.ResourceCPU {
+			translated.ResourceList[resourceName] = *resource.NewMilliQuantity(value, resource.DecimalSI)
+		} else {
+			translated.ResourceList[resourceName] = *resource.NewQuantity(value, quantity.Format)
+		}
+	}
+	return translated
+}
+
+// preferredNodeAffinityScore returns the bonus for the node according to the ratio of
+// the matched weights to all weights of the Pod's preferred node affinity terms.
+func preferredNodeAffinityScore(pod *corev1.Pod, node *corev1.Node, maxBonus int64) int64 {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || len(affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution) == 0 {
+		return 0
+	}
+	terms := affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	preferredTerms, err := nodeaffinity.NewPreferredSchedulingTerms(terms)
+	if err != nil {
+		klog.V(5).ErrorS(err, "failed to parse preferred node affinity", "pod", klog.KObj(pod))
+		return 0
+	}
+	var weightSum int64
+	for _, term := range terms {
+		weightSum += int64(term.Weight)
+	}
+	if weightSum <= 0 {
+		return 0
+	}
+	return maxBonus * preferredTerms.Score(node) / weightSum
+}
+
+// requestsUsageGapScore returns the bonus according to the weighted average percentage of the requested
+// but unused resources to the allocatable of the node.
+func requestsUsageGapScore(nodeInfo *framework.NodeInfo, nodeUsage corev1.ResourceList, resourceWeights map[corev1.ResourceName]int64, maxBonus int64) int64 {
+	var gap, weightSum int64
+	for resourceName, weight := range resourceWeights {
+		requested := getNodeInfoResourceValue(resourceName, nodeInfo.Requested)
+		allocatable := getNodeInfoResourceValue(resourceName, nodeInfo.Allocatable)
+		used := getResourceValue(resourceName, nodeUsage[resourceName])
+		if allocatable > 0 && requested > used {
+			resourceGap := (requested - used) * 100 / allocatable
+			if resourceGap > 100 {
+				resourceGap = 100
+			}
+			gap += resourceGap * weight
+		}
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 0
+	}
+	return maxBonus * gap / weightSum / 100
+}
+
+// compressibilityScore returns the bonus according to the average CPU compressibility of the Pods on the node.
+// The compressibility of a Pod is 1 - requests/limits, the Pods without CPU limits are fully compressible because
+// they can be throttled down to their requests, and the Guaranteed Pods are not compressible at all.
+func compressibilityScore(nodeInfo *framework.NodeInfo, maxBonus int64) int64 {
+	if len(nodeInfo.Pods) == 0 {
+		return 0
+	}
+	var compressibility int64
+	for _, podInfo := range nodeInfo.Pods {
+		requests, limits := resourceapi.PodRequestsAndLimits(podInfo.Pod)
+		limit := limits[corev1.ResourceCPU]
+		if limit.IsZero() {
+			compressibility += 100
+			continue
+		}
+		request := requests[corev1.ResourceCPU]
+		if limit.MilliValue() > request.MilliValue() {
+			compressibility += (limit.MilliValue() - request.MilliValue()) * 100 / limit.MilliValue()
+		}
+	}
+	return maxBonus * compressibility / int64(len(nodeInfo.Pods)) / 100
+}
+
+// usageTrendScore returns the bonus, or the penalty if negative, according to the weighted average percentage of
+// the decrease from the average usage to the latest usage to the allocatable of the node.
+func usageTrendScore(latestUsage, averageUsage corev1.ResourceList, detail *nodeScoreDetail, resourceWeights map[corev1.ResourceName]int64, maxScore int64) int64 {
+	var trend, weightSum int64
+	for resourceName, weight := range resourceWeights {
+		allocatable := detail.allocatable[resourceName]
+		average, ok := averageUsage[resourceName]
+		if allocatable > 0 && ok {
+			resourceTrend := (getResourceValue(resourceName, average) - getResourceValue(resourceName, latestUsage[resourceName])) * 100 / allocatable
+			if resourceTrend > 100 {
+				resourceTrend = 100
+			} else if resourceTrend < -100 {
+				resourceTrend = -100
+			}
+			trend += resourceTrend * weight
+		}
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 0
+	}
+	return maxScore * trend / weightSum / 100
+}
+
+// thresholdProximityPenalty returns the penalty in proportion to how close the estimated utilization
+// is to the nearest usage threshold, which reaches maxPenalty at the threshold.
+func thresholdProximityPenalty(usageThresholds map[corev1.ResourceName]int64, detail *nodeScoreDetail, maxPenalty int64) int64 {
+	var penalty int64
+	for resourceName, threshold := range usageThresholds {
+		allocatable := detail.allocatable[resourceName]
+		if threshold <= 0 || allocatable <= 0 {
+			continue
+		}
+		utilization := detail.estimatedUsed[resourceName] * 100 / allocatable
+		resourcePenalty := maxPenalty * utilization / threshold
+		if resourcePenalty > maxPenalty {
+			resourcePenalty = maxPenalty
+		}
+		if resourcePenalty > penalty {
+			penalty = resourcePenalty
+		}
+	}
+	return penalty
+}
+
+// hostsSameWorkloadPods returns true if the node hosts any other Pod of the same controller as the Pod.
+func hostsSameWorkloadPods(pod *corev1.Pod, nodeInfo *framework.NodeInfo) bool {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return false
+	}
+	for _, podInfo := range nodeInfo.Pods {
+		if podInfo.Pod.UID == pod.UID {
+			continue
+		}
+		if podOwner := metav1.GetControllerOf(podInfo.Pod); podOwner != nil && podOwner.UID == owner.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// gpuResourceNames is the resources requested by the Pods using GPUs.
+var gpuResourceNames = []corev1.ResourceName{
+	extension.ResourceNvidiaGPU,
+	extension.ResourceGPU,
+	extension.ResourceGPUCore,
+	extension.ResourceGPUMemory,
+	extension.ResourceGPUMemoryRatio,
+}
+
+// requestsGPU returns true if any container or init container of the Pod requests GPUs.
+func requestsGPU(pod *corev1.Pod) bool {
+	for _, containers := range [][]corev1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+		for _, container := range containers {
+			for _, resourceName := range gpuResourceNames {
+				if q, ok := container.Resources.Requests[resourceName]; ok && !q.IsZero() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isReclaimablePod returns true if the pod runs with the resources reclaimed from the over-requesting Pods.
+func isReclaimablePod(pod *corev1.Pod) bool {
+	priorityClass := extension.GetPriorityClass(pod)
+	return priorityClass == extension.PriorityBatch || priorityClass == extension.PriorityFree
+}
+
+// isLoadAwareExemptNode returns true if the node is labeled exempt from the load-aware scheduling with the key.
+func isLoadAwareExemptNode(node *corev1.Node, exemptNodeLabelKey string) bool {
+	return exemptNodeLabelKey != "" && node.Labels[exemptNodeLabelKey] == "true"
+}
+
+// isLoadAwareSchedulingSkipped returns false if the AnnotationSkipLoadAwareScheduling is invalid,
+// so that the pod is still scheduled by the utilization.
+func isLoadAwareSchedulingSkipped(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	skipped, err := extension.IsLoadAwareSchedulingSkipped(pod)
+	if err != nil {
+		klog.V(5).InfoS("failed to parse the annotation of pod, schedule by the utilization", "pod", klog.KObj(pod), "err", err)
+		return false
+	}
+	return skipped
+}
+
 // isDaemonSetPod returns true if the pod is a IsDaemonSetPod.
 func isDaemonSetPod(ownerRefList []metav1.OwnerReference) bool {
 	for _, ownerRef := range ownerRefList {
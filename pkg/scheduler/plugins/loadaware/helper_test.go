@@ -0,0 +1,486 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func TestExtractNodeUsages(t *testing.T) {
+	newPodMetric := func(name, cpu string) *slov1alpha1.PodMetricInfo {
+		return &slov1alpha1.PodMetricInfo{
+			Namespace: "default",
+			Name:      name,
+			PodUsage: slov1alpha1.ResourceMap{
+				ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse(cpu),
+				},
+			},
+		}
+	}
+	nodeUsage := slov1alpha1.ResourceMap{
+		ResourceList: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("20"),
+		},
+	}
+	tests := []struct {
+		name       string
+		nodeMetric *slov1alpha1.NodeMetric
+		want       *nodeUsages
+	}{
+		{
+			name:       "nothing reported",
+			nodeMetric: &slov1alpha1.NodeMetric{},
+			want:       &nodeUsages{},
+		},
+		{
+			name: "only node usage reported",
+			nodeMetric: &slov1alpha1.NodeMetric{
+				Status: slov1alpha1.NodeMetricStatus{
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: nodeUsage,
+					},
+				},
+			},
+			want: &nodeUsages{
+				total: &nodeUsage,
+			},
+		},
+		{
+			name: "node and pods usage reported",
+			nodeMetric: &slov1alpha1.NodeMetric{
+				Status: slov1alpha1.NodeMetricStatus{
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: nodeUsage,
+					},
+					PodsMetric: []*slov1alpha1.PodMetricInfo{
+						newPodMetric("prod-pod-1", "4"),
+						newPodMetric("prod-pod-2", "2"),
+						newPodMetric("batch-pod", "3"),
+						newPodMetric("free-pod", "1"),
+						newPodMetric("mid-pod", "5"),
+						newPodMetric("deleted-pod", "6"),
+					},
+				},
+			},
+			want: &nodeUsages{
+				total: &nodeUsage,
+				podMetrics: map[string]corev1.ResourceList{
+					"default/prod-pod-1": {corev1.ResourceCPU: resource.MustParse("4")},
+					"default/prod-pod-2": {corev1.ResourceCPU: resource.MustParse("2")},
+					"default/batch-pod":  {corev1.ResourceCPU: resource.MustParse("3")},
+					"default/free-pod":   {corev1.ResourceCPU: resource.MustParse("1")},
+					"default/mid-pod":    {corev1.ResourceCPU: resource.MustParse("5")},
+				},
+				prodPodMetrics: map[string]corev1.ResourceList{
+					"default/prod-pod-1": {corev1.ResourceCPU: resource.MustParse("4")},
+					"default/prod-pod-2": {corev1.ResourceCPU: resource.MustParse("2")},
+				},
+				prod:  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("6")},
+				batch: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for name, priority := range map[string]int32{
+		"prod-pod-1": extension.PriorityProdValueMin,
+		"prod-pod-2": extension.PriorityProdValueMax,
+		"batch-pod":  extension.PriorityBatchValueMin,
+		"free-pod":   extension.PriorityFreeValueMin,
+		"mid-pod":    extension.PriorityMidValueMin,
+	} {
+		assert.NoError(t, indexer.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Priority: pointer.Int32(priority),
+			},
+		}))
+	}
+	podLister := corev1listers.NewPodLister(indexer)
+	milliCPU := func(resources corev1.ResourceList) int64 {
+		quantity := resources[corev1.ResourceCPU]
+		return quantity.MilliValue()
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractNodeUsages(podLister, tt.nodeMetric)
+			assert.Equal(t, tt.want.total, got.total)
+			assert.Equal(t, len(tt.want.podMetrics), len(got.podMetrics))
+			for podName, usage := range tt.want.podMetrics {
+				assert.Equal(t, milliCPU(usage), milliCPU(got.podMetrics[podName]), podName)
+			}
+			assert.Equal(t, len(tt.want.prodPodMetrics), len(got.prodPodMetrics))
+			for podName := range tt.want.prodPodMetrics {
+				assert.Contains(t, got.prodPodMetrics, podName)
+			}
+			assert.Equal(t, milliCPU(tt.want.prod), milliCPU(got.prod))
+			assert.Equal(t, milliCPU(tt.want.batch), milliCPU(got.batch))
+			assert.Equal(t, got.prodPodMetrics, got.getPodMetrics(true))
+			assert.Equal(t, got.podMetrics, got.getPodMetrics(false))
+		})
+	}
+}
+
+func TestExcludeSystemUsageKeepsNodeMetric(t *testing.T) {
+	// the quantities beyond int64 are stored as inf.Dec, which is shared by the shallow copies.
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("30"),
+						corev1.ResourceMemory: resource.MustParse("100000000000000000000"),
+					},
+				},
+			},
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				{
+					Namespace: "default",
+					Name:      "test-pod-1",
+					PodUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("20"),
+							corev1.ResourceMemory: resource.MustParse("40000000000000000000"),
+						},
+					},
+				},
+			},
+		},
+	}
+	want := nodeMetric.DeepCopy()
+	excluded := excludeSystemUsage(&nodeMetric.Status.NodeMetric.NodeUsage, nodeMetric)
+	assert.Equal(t, "20", excluded.ResourceList.Cpu().String())
+	assert.Equal(t, 0, excluded.ResourceList.Memory().Cmp(resource.MustParse("40000000000000000000")))
+	assert.Equal(t, want, nodeMetric)
+}
+
+func TestValidateCustomUsageThresholds(t *testing.T) {
+	args := &config.LoadAwareSchedulingArgs{
+		ResourceWeights: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU:    1,
+			corev1.ResourceMemory: 1,
+		},
+		UsageThresholds: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU: 65,
+		},
+		Aggregated: &config.LoadAwareSchedulingAggregatedArgs{
+			UsageThresholds: map[corev1.ResourceName]int64{
+				extension.ResourceNetworkRX: 80,
+			},
+		},
+	}
+	tests := []struct {
+		name                  string
+		customUsageThresholds *extension.CustomUsageThresholds
+		wantFields            []string
+	}{
+		{
+			name: "nil thresholds",
+		},
+		{
+			name: "thresholds of configured resources",
+			customUsageThresholds: &extension.CustomUsageThresholds{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:    70,
+					corev1.ResourceMemory: 80,
+				},
+				ProdUsageThresholds: map[corev1.ResourceName]int64{
+					extension.ResourceNetworkRX: 60,
+				},
+			},
+		},
+		{
+			name: "thresholds of resources not configured",
+			customUsageThresholds: &extension.CustomUsageThresholds{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:              70,
+					corev1.ResourceEphemeralStorage: 80,
+				},
+				ProdUsageThresholds: map[corev1.ResourceName]int64{
+					extension.ResourceNetworkTX: 60,
+				},
+				AggregatedUsage: &extension.CustomAggregatedUsage{
+					UsageThresholds: map[corev1.ResourceName]int64{
+						corev1.ResourceMemory: 80,
+						corev1.ResourcePods:   90,
+					},
+				},
+			},
+			wantFields: []string{
+				"usageThresholds[ephemeral-storage]",
+				"prodUsageThresholds[" + string(extension.ResourceNetworkTX) + "]",
+				"aggregatedUsage.usageThresholds[pods]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateCustomUsageThresholds(tt.customUsageThresholds, args)
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestEffectiveUsageThresholds(t *testing.T) {
+	args := &config.LoadAwareSchedulingArgs{
+		UsageThresholds: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU:    65,
+			corev1.ResourceMemory: 95,
+		},
+		ProdUsageThresholds: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU: 55,
+		},
+	}
+	tests := []struct {
+		name           string
+		annotation     string
+		args           *config.LoadAwareSchedulingArgs
+		wantThresholds *extension.CustomUsageThresholds
+	}{
+		{
+			name: "node without annotation uses args",
+			args: args,
+			wantThresholds: &extension.CustomUsageThresholds{
+				UsageThresholds:     args.UsageThresholds,
+				ProdUsageThresholds: args.ProdUsageThresholds,
+			},
+		},
+		{
+			name:       "annotation overrides args",
+			annotation: `{"usageThresholds":{"cpu":80}}`,
+			args:       args,
+			wantThresholds: &extension.CustomUsageThresholds{
+				UsageThresholds:     map[corev1.ResourceName]int64{corev1.ResourceCPU: 80},
+				ProdUsageThresholds: args.ProdUsageThresholds,
+			},
+		},
+		{
+			name:       "malformed annotation falls back to args",
+			annotation: `{malformed`,
+			args:       args,
+			wantThresholds: &extension.CustomUsageThresholds{
+				UsageThresholds:     args.UsageThresholds,
+				ProdUsageThresholds: args.ProdUsageThresholds,
+			},
+		},
+		{
+			name:       "mandatory thresholds are stricter than annotation",
+			annotation: `{"usageThresholds":{"cpu":80}}`,
+			args: &config.LoadAwareSchedulingArgs{
+				UsageThresholds: args.UsageThresholds,
+				MandatoryThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:    70,
+					corev1.ResourceMemory: 90,
+				},
+			},
+			wantThresholds: &extension.CustomUsageThresholds{
+				UsageThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 70, corev1.ResourceMemory: 90},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-effective-thresholds",
+				},
+			}
+			if tt.annotation != "" {
+				node.Annotations = map[string]string{extension.AnnotationCustomUsageThresholds: tt.annotation}
+			}
+			assert.Equal(t, tt.wantThresholds, EffectiveUsageThresholds(node, tt.args))
+		})
+	}
+}
+
+func TestEffectiveUsageThresholdsLogConflict(t *testing.T) {
+	const msg = "Custom usage thresholds of node reference resources not configured in LoadAwareScheduling"
+	logger := newTestLogger()
+	klog.SetLogger(logger)
+	defer klog.SetLogger(nil)
+	countEntries := func() int {
+		logger.lock.Lock()
+		defer logger.lock.Unlock()
+		count := 0
+		for _, entry := range *logger.entries {
+			if entry.msg == msg {
+				count++
+			}
+		}
+		return count
+	}
+
+	args := &config.LoadAwareSchedulingArgs{
+		UsageThresholds: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU: 65,
+		},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-thresholds-conflict",
+			Annotations: map[string]string{
+				extension.AnnotationCustomUsageThresholds: `{"usageThresholds":{"nvidia.com/gpu":80}}`,
+			},
+		},
+	}
+	defer customUsageThresholdsConflicts.Delete(node.Name)
+
+	// the conflict is logged at the default verbosity, and only once for the same thresholds.
+	for i := 0; i < 3; i++ {
+		EffectiveUsageThresholds(node, args)
+	}
+	assert.Equal(t, 1, countEntries())
+
+	// the changed conflict is logged again.
+	node.Annotations[extension.AnnotationCustomUsageThresholds] = `{"usageThresholds":{"nvidia.com/gpu":80,"ephemeral-storage":80}}`
+	EffectiveUsageThresholds(node, args)
+	assert.Equal(t, 2, countEntries())
+
+	// the conflict is forgotten once resolved.
+	node.Annotations[extension.AnnotationCustomUsageThresholds] = `{"usageThresholds":{"cpu":80}}`
+	EffectiveUsageThresholds(node, args)
+	_, ok := customUsageThresholdsConflicts.Load(node.Name)
+	assert.False(t, ok)
+	assert.Equal(t, 2, countEntries())
+}
+
+func TestGetNodeMetricReportInterval(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          *config.LoadAwareSchedulingArgs
+		collectPolicy *slov1alpha1.NodeMetricCollectPolicy
+		want          time.Duration
+	}{
+		{
+			name: "fall back to the default without CollectPolicy",
+			args: &config.LoadAwareSchedulingArgs{},
+			want: DefaultNodeMetricReportInterval,
+		},
+		{
+			name: "fall back to the configured interval without CollectPolicy",
+			args: &config.LoadAwareSchedulingArgs{
+				DefaultNodeMetricReportIntervalSeconds: pointer.Int64(30),
+			},
+			want: 30 * time.Second,
+		},
+		{
+			name: "fall back to the configured interval without ReportIntervalSeconds",
+			args: &config.LoadAwareSchedulingArgs{
+				DefaultNodeMetricReportIntervalSeconds: pointer.Int64(120),
+			},
+			collectPolicy: &slov1alpha1.NodeMetricCollectPolicy{},
+			want:          120 * time.Second,
+		},
+		{
+			name: "ReportIntervalSeconds of CollectPolicy takes precedence",
+			args: &config.LoadAwareSchedulingArgs{
+				DefaultNodeMetricReportIntervalSeconds: pointer.Int64(30),
+			},
+			collectPolicy: &slov1alpha1.NodeMetricCollectPolicy{
+				ReportIntervalSeconds: pointer.Int64(10),
+			},
+			want: 10 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeMetric := &slov1alpha1.NodeMetric{
+				Spec: slov1alpha1.NodeMetricSpec{
+					CollectPolicy: tt.collectPolicy,
+				},
+			}
+			assert.Equal(t, tt.want, getNodeMetricReportInterval(tt.args, nodeMetric))
+		})
+	}
+}
+
+func TestGetNodeMetricUpdateTime(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		updateTime *metav1.Time
+		wantNow    bool
+		wantExpire bool
+	}{
+		{
+			name:       "without UpdateTime",
+			wantExpire: true,
+		},
+		{
+			name:       "UpdateTime in the past",
+			updateTime: &metav1.Time{Time: now.Add(-10 * time.Second)},
+		},
+		{
+			name:       "expired UpdateTime",
+			updateTime: &metav1.Time{Time: now.Add(-time.Hour)},
+			wantExpire: true,
+		},
+		{
+			name:       "UpdateTime slightly in the future",
+			updateTime: &metav1.Time{Time: now.Add(5 * time.Second)},
+			wantNow:    true,
+		},
+		{
+			name:       "UpdateTime far in the future",
+			updateTime: &metav1.Time{Time: now.Add(time.Hour)},
+			wantNow:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeMetric := &slov1alpha1.NodeMetric{
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: tt.updateTime,
+				},
+			}
+			got := getNodeMetricUpdateTime(nodeMetric)
+			if tt.updateTime == nil {
+				assert.True(t, got.IsZero())
+			} else if tt.wantNow {
+				assert.False(t, got.Before(now))
+				assert.False(t, got.After(time.Now()))
+			} else {
+				assert.Equal(t, tt.updateTime.Time, got)
+			}
+			assert.Equal(t, tt.wantExpire, isNodeMetricExpired(nodeMetric, 180))
+		})
+	}
+}
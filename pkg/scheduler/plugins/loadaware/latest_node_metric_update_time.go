@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	slolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+)
+
+// latestNodeMetricUpdateTime tracks the latest UpdateTime of the NodeMetrics in the event handlers,
+// so that whether the NodeMetrics of all nodes are expired is known without listing them.
+// It is the zero time if there are no NodeMetrics with the UpdateTime.
+type latestNodeMetricUpdateTime struct {
+	lock             sync.RWMutex
+	updateTime       time.Time
+	nodeMetricLister slolisters.NodeMetricLister
+}
+
+func newLatestNodeMetricUpdateTime(nodeMetricLister slolisters.NodeMetricLister) *latestNodeMetricUpdateTime {
+	return &latestNodeMetricUpdateTime{
+		nodeMetricLister: nodeMetricLister,
+	}
+}
+
+func (l *latestNodeMetricUpdateTime) get() time.Time {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.updateTime
+}
+
+func (l *latestNodeMetricUpdateTime) update(nodeMetric *slov1alpha1.NodeMetric) {
+	updateTime := getNodeMetricUpdateTime(nodeMetric)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if updateTime.After(l.updateTime) {
+		l.updateTime = updateTime
+	}
+}
+
+// resync recomputes the latest UpdateTime from the lister, which is only required if the latest NodeMetric
+// is deleted, because the UpdateTime of the rest never goes backwards.
+func (l *latestNodeMetricUpdateTime) resync(deleted *slov1alpha1.NodeMetric) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if deleted != nil && getNodeMetricUpdateTime(deleted).Before(l.updateTime) {
+		return
+	}
+	nodeMetrics, err := l.nodeMetricLister.List(labels.Everything())
+	if err != nil {
+		return
+	}
+	var latest time.Time
+	for _, nodeMetric := range nodeMetrics {
+		if updateTime := getNodeMetricUpdateTime(nodeMetric); updateTime.After(latest) {
+			latest = updateTime
+		}
+	}
+	l.updateTime = latest
+}
+
+// registerLatestNodeMetricUpdateTimeEventHandler tracks the latest UpdateTime on the NodeMetric events.
+func registerLatestNodeMetricUpdateTimeEventHandler(koordSharedInformerFactory koordinatorinformers.SharedInformerFactory, latest *latestNodeMetricUpdateTime) {
+	nodeMetricInformer := koordSharedInformerFactory.Slo().V1alpha1().NodeMetrics().Informer()
+	nodeMetricInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if nodeMetric, ok := obj.(*slov1alpha1.NodeMetric); ok {
+				latest.update(nodeMetric)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if nodeMetric, ok := newObj.(*slov1alpha1.NodeMetric); ok {
+				latest.update(nodeMetric)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			switch t := obj.(type) {
+			case *slov1alpha1.NodeMetric:
+				latest.resync(t)
+			case cache.DeletedFinalStateUnknown:
+				nodeMetric, _ := t.Obj.(*slov1alpha1.NodeMetric)
+				latest.resync(nodeMetric)
+			}
+		},
+	})
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	slolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+)
+
+func TestLatestNodeMetricUpdateTime(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	newNodeMetric := func(name string, updateTime time.Time) *slov1alpha1.NodeMetric {
+		return &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{Time: updateTime},
+			},
+		}
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	latest := newLatestNodeMetricUpdateTime(slolisters.NewNodeMetricLister(indexer))
+	assert.True(t, latest.get().IsZero())
+
+	older := newNodeMetric("test-node-1", now.Add(-time.Minute))
+	newer := newNodeMetric("test-node-2", now)
+	for _, nodeMetric := range []*slov1alpha1.NodeMetric{older, newer} {
+		assert.NoError(t, indexer.Add(nodeMetric))
+		latest.update(nodeMetric)
+	}
+	assert.Equal(t, now, latest.get())
+
+	// the UpdateTime never goes backwards on update.
+	latest.update(newNodeMetric("test-node-2", now.Add(-time.Hour)))
+	assert.Equal(t, now, latest.get())
+
+	// the older one is deleted without resyncing.
+	assert.NoError(t, indexer.Delete(older))
+	latest.resync(older)
+	assert.Equal(t, now, latest.get())
+
+	// the latest one is deleted and the rest are resynced.
+	assert.NoError(t, indexer.Add(older))
+	assert.NoError(t, indexer.Delete(newer))
+	latest.resync(newer)
+	assert.Equal(t, now.Add(-time.Minute), latest.get())
+
+	// no NodeMetrics left.
+	assert.NoError(t, indexer.Delete(older))
+	latest.resync(older)
+	assert.True(t, latest.get().IsZero())
+}
@@ -20,30 +20,41 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	configlisters "github.com/koordinator-sh/koordinator/pkg/client/listers/config/v1alpha1"
 	slolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware/estimator"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 const (
 	Name                                    = "LoadAwareScheduling"
 	ErrReasonNodeMetricExpired              = "node(s) nodeMetric expired"
+	ErrReasonNodeMetricNotFound             = "node(s) nodeMetric not found"
 	ErrReasonUsageExceedThreshold           = "node(s) %s usage exceed threshold"
 	ErrReasonAggregatedUsageExceedThreshold = "node(s) %s aggregated usage exceed threshold"
+	ErrReasonCombinedUsageExceedThreshold   = "node(s) combined usage exceed threshold"
+	ErrReasonAllNodeMetricsExpired          = "all nodeMetrics expired, wait for recovery"
 )
 
 const (
@@ -56,18 +67,55 @@ const (
 )
 
 var (
-	_ framework.FilterPlugin  = &Plugin{}
-	_ framework.ScorePlugin   = &Plugin{}
-	_ framework.ReservePlugin = &Plugin{}
+	_ framework.PreFilterPlugin  = &Plugin{}
+	_ framework.FilterPlugin     = &Plugin{}
+	_ framework.PostFilterPlugin = &Plugin{}
+	_ framework.PreScorePlugin   = &Plugin{}
+	_ framework.ScorePlugin      = &Plugin{}
+	_ framework.ScoreExtensions  = &Plugin{}
+	_ framework.ReservePlugin    = &Plugin{}
+	_ framework.PreBindPlugin    = &Plugin{}
 )
 
 type Plugin struct {
 	handle           framework.Handle
-	args             *config.LoadAwareSchedulingArgs
 	podLister        corev1listers.PodLister
 	nodeMetricLister slolisters.NodeMetricLister
-	estimator        estimator.Estimator
-	podAssignCache   *podAssignCache
+	// nodeMetricIndexer indexes the NodeMetrics by the shard label if NodeMetricShardLabelKey is set.
+	nodeMetricIndexer cache.Indexer
+	// nodeLister lists the nodes out of the scheduling cycle, e.g. in the event handlers, where the snapshot is being updated.
+	nodeLister corev1listers.NodeLister
+	// usageThresholdPolicyLister lists the ClusterUsageThresholdPolicy overriding the usage thresholds in args.
+	usageThresholdPolicyLister configlisters.ClusterUsageThresholdPolicyLister
+	podAssignCache             *podAssignCache
+	// fallbackNodeMetricProvider provides the NodeMetrics of the nodes without NodeMetric if FallbackToMetricsServer is enabled.
+	fallbackNodeMetricProvider NodeMetricProvider
+	// unschedulableAttempts tracks the Pods unschedulable due to the usage thresholds to relax the thresholds for them.
+	unschedulableAttempts *unschedulableAttempts
+	// reservationIndexer indexes the Reservations by the node name if EstimateReservations is enabled.
+	reservationIndexer cache.Indexer
+	// dynamicResourceWeights caches the boosts of the resource weights if DynamicResourceWeightMaxBoostPercent is set.
+	dynamicResourceWeights *dynamicResourceWeights
+	// staticArgs is the args configured in KubeSchedulerConfiguration.
+	staticArgs *config.LoadAwareSchedulingArgs
+	// args stores the *loadAwareArgs in effect, which may be replaced by the dynamic args at runtime.
+	args atomic.Value
+	// latestNodeMetricUpdateTime tracks the latest UpdateTime of the NodeMetrics to tell whether all of them are expired.
+	latestNodeMetricUpdateTime *latestNodeMetricUpdateTime
+	// nodeLoads caches the loads of the nodes emitted by the NodeLoadCollector.
+	nodeLoads *nodeLoads
+	// scoringDecisionWriter writes the scoring decisions if ScoringDecisionRecordDir is set.
+	scoringDecisionWriter *scoringDecisionWriter
+	// explainedPod is the Pod being explained if the plugin is built by newExplainer.
+	// The explainer records no metrics and excludes the Pod from the assigned Pods.
+	explainedPod *corev1.Pod
+}
+
+// loadAwareArgs is the args in effect and the estimator built from them.
+// They are always replaced together.
+type loadAwareArgs struct {
+	*config.LoadAwareSchedulingArgs
+	estimator estimator.Estimator
 }
 
 func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
@@ -85,137 +133,287 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		return nil, fmt.Errorf("want handle to be of type frameworkext.ExtendedHandle, got %T", handle)
 	}
 
+	RegisterMetrics()
+
+	effectiveArgs := withoutNonPoolableResourceWeights(pluginArgs)
+	estimator, err := estimator.NewEstimator(effectiveArgs, handle)
+	if err != nil {
+		return nil, err
+	}
+
 	assignCache := newPodAssignCache()
+	assignCache.setEstimator(estimator)
 	podInformer := frameworkExtender.SharedInformerFactory().Core().V1().Pods()
 	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), frameworkExtender.SharedInformerFactory(), podInformer.Informer(), assignCache)
+	registerNodeEventHandler(frameworkExtender.SharedInformerFactory(), assignCache)
+	registerCustomThresholdParseErrorsEventHandler(frameworkExtender.SharedInformerFactory())
+	attempts := newUnschedulableAttempts()
+	registerUnschedulableAttemptsEventHandler(frameworkExtender.SharedInformerFactory(), attempts)
 	podLister := podInformer.Lister()
 	nodeMetricLister := frameworkExtender.KoordinatorSharedInformerFactory().Slo().V1alpha1().NodeMetrics().Lister()
+	usageThresholdPolicyLister := frameworkExtender.KoordinatorSharedInformerFactory().Config().V1alpha1().ClusterUsageThresholdPolicies().Lister()
 
-	estimator, err := estimator.NewEstimator(pluginArgs, handle)
-	if err != nil {
-		return nil, err
+	latestUpdateTime := newLatestNodeMetricUpdateTime(nodeMetricLister)
+	registerLatestNodeMetricUpdateTimeEventHandler(frameworkExtender.KoordinatorSharedInformerFactory(), latestUpdateTime)
+
+	plugin := &Plugin{
+		handle:                     handle,
+		podLister:                  podLister,
+		nodeMetricLister:           nodeMetricLister,
+		nodeLister:                 frameworkExtender.SharedInformerFactory().Core().V1().Nodes().Lister(),
+		usageThresholdPolicyLister: usageThresholdPolicyLister,
+		podAssignCache:             assignCache,
+		unschedulableAttempts:      attempts,
+		dynamicResourceWeights:     newDynamicResourceWeights(),
+		latestNodeMetricUpdateTime: latestUpdateTime,
+		nodeLoads:                  newNodeLoads(),
+		scoringDecisionWriter:      newScoringDecisionWriter(maxScoringDecisionFiles),
+		staticArgs:                 pluginArgs,
+	}
+	if client := handle.ClientSet(); client != nil && pluginArgs.FallbackToMetricsServer {
+		if restClient := client.Discovery().RESTClient(); restClient != nil {
+			provider := newMetricsServerProvider(restClient)
+			go provider.Run(context.TODO().Done())
+			plugin.fallbackNodeMetricProvider = provider
+		}
+	}
+	plugin.args.Store(&loadAwareArgs{LoadAwareSchedulingArgs: effectiveArgs, estimator: estimator})
+	registerNodeLoadEventHandler(frameworkExtender.SharedInformerFactory(), frameworkExtender.KoordinatorSharedInformerFactory(), plugin)
+	registerNodeLoadCollector(plugin)
+	if pluginArgs.NodeMetricShardLabelKey != "" {
+		nodeMetricInformer := frameworkExtender.KoordinatorSharedInformerFactory().Slo().V1alpha1().NodeMetrics().Informer()
+		if err := addNodeMetricShardIndexer(nodeMetricInformer, pluginArgs.NodeMetricShardLabelKey); err != nil {
+			return nil, err
+		}
+		plugin.nodeMetricIndexer = nodeMetricInformer.GetIndexer()
 	}
+	if pluginArgs.EstimateReservations {
+		plugin.reservationIndexer = frameworkExtender.KoordinatorSharedInformerFactory().Scheduling().V1alpha1().Reservations().Informer().GetIndexer()
+	}
+	if pluginArgs.DynamicArgsConfigMapName != "" {
+		registerDynamicArgsEventHandler(handle.ClientSet(), plugin)
+	}
+	return plugin, nil
+}
 
-	return &Plugin{
-		handle:           handle,
-		args:             pluginArgs,
-		podLister:        podLister,
-		nodeMetricLister: nodeMetricLister,
-		estimator:        estimator,
-		podAssignCache:   assignCache,
-	}, nil
+func (p *Plugin) getArgs() *loadAwareArgs {
+	return p.args.Load().(*loadAwareArgs)
 }
 
 func (p *Plugin) Name() string { return Name }
 
 func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	defer recordPhaseDuration(phaseFilter, time.Now())
+	reason, status := p.filter(pod, nodeInfo)
+	if reason == nil {
+		return status
+	}
+	if p.getArgs().SoftThreshold && isThresholdReason(reason.Code) {
+		recordSoftThresholdBreach(state, nodeInfo.Node().Name, *reason)
+		return nil
+	}
+	recordFilterReason(state, nodeInfo.Node().Name, *reason)
+	FilterRejections.WithLabelValues(string(reason.Code)).Inc()
+	return newUnschedulableStatus(*reason)
+}
+
+// filter returns the reason if the node is rejected, or the error status if the node fails to be filtered.
+func (p *Plugin) filter(pod *corev1.Pod, nodeInfo *framework.NodeInfo) (*Reason, *framework.Status) {
 	node := nodeInfo.Node()
 	if node == nil {
-		return framework.NewStatus(framework.Error, "node not found")
+		return nil, framework.NewStatus(framework.Error, "node not found")
 	}
 
-	if isDaemonSetPod(pod.OwnerReferences) {
-		return nil
+	if isDaemonSetPod(pod.OwnerReferences) || isLoadAwareSchedulingSkipped(pod) {
+		return nil, nil
 	}
 
-	nodeMetric, err := p.nodeMetricLister.Get(node.Name)
+	args := p.getArgs()
+	if args.AdvisoryOnly || isLoadAwareExemptNode(node, args.ExemptNodeLabelKey) {
+		return nil, nil
+	}
+
+	nodeMetric, err := p.getNodeMetric(args.LoadAwareSchedulingArgs, node.Name)
 	if err != nil {
 		// For nodes that lack load information, fall back to the situation where there is no load-aware scheduling.
 		// Some nodes in the cluster do not install the koordlet, but users newly created Pod use koord-scheduler to schedule,
 		// and the load-aware scheduling itself is an optimization, so we should skip these nodes.
 		if errors.IsNotFound(err) {
-			return nil
+			if p.explainedPod == nil {
+				NodeMetricAge.Delete(map[string]string{"node": node.Name})
+			}
+			if args.RequireNodeMetric {
+				return &Reason{Code: ReasonCodeNodeMetricNotFound}, nil
+			}
+			return nil, nil
 		}
-		return framework.NewStatus(framework.Error, err.Error())
+		// Transient errors should not fail the whole scheduling attempt, skip the node as it lacks load information.
+		if isTransientError(err) {
+			klog.V(4).InfoS("Failed to get NodeMetric with transient error, skip load-aware filtering", "node", node.Name, "err", err)
+			return nil, nil
+		}
+		return nil, framework.NewStatus(framework.Error, err.Error())
+	}
+	if p.explainedPod == nil {
+		recordNodeMetricAge(nodeMetric)
 	}
 
-	if p.args.FilterExpiredNodeMetrics != nil && *p.args.FilterExpiredNodeMetrics && p.args.NodeMetricExpirationSeconds != nil {
-		if isNodeMetricExpired(nodeMetric, *p.args.NodeMetricExpirationSeconds) {
-			return framework.NewStatus(framework.Unschedulable, ErrReasonNodeMetricExpired)
+	if args.FilterExpiredNodeMetrics != nil && *args.FilterExpiredNodeMetrics && args.NodeMetricExpirationSeconds != nil {
+		if isNodeMetricExpired(nodeMetric, *args.NodeMetricExpirationSeconds) {
+			return &Reason{Code: ReasonCodeNodeMetricExpired}, nil
 		}
 	}
 
-	filterProfile := generateUsageThresholdsFilterProfile(node, p.args)
-	if len(filterProfile.ProdUsageThresholds) > 0 && extension.GetPriorityClass(pod) == extension.PriorityProd {
-		status := p.filterProdUsage(node, nodeMetric, filterProfile.ProdUsageThresholds)
-		if !status.IsSuccess() {
-			return status
+	filterProfile := relaxUsageThresholds(p.generateUsageThresholdsFilterProfile(node, args.LoadAwareSchedulingArgs),
+		p.thresholdRelaxation(args, pod), args.MandatoryThresholds)
+	if filterByProdUsage(args.LoadAwareSchedulingArgs, filterProfile, pod) {
+		if reason := p.filterProdUsage(node, nodeMetric, filterProfile.ProdUsageThresholds); reason != nil {
+			return reason, nil
 		}
 	} else {
-		var usageThresholds map[corev1.ResourceName]int64
-		if filterProfile.AggregatedUsage != nil {
-			usageThresholds = filterProfile.AggregatedUsage.UsageThresholds
-		} else {
-			usageThresholds = filterProfile.UsageThresholds
-		}
-		if len(usageThresholds) > 0 {
-			status := p.filterNodeUsage(node, nodeMetric, filterProfile)
-			if !status.IsSuccess() {
-				return status
+		if usageThresholds, _ := getFilterUsageThresholds(filterProfile); len(usageThresholds) > 0 {
+			if reason := p.filterNodeUsage(args, node, nodeMetric, filterProfile); reason != nil {
+				return reason, nil
+			}
+		}
+		if args.CombinedThreshold > 0 {
+			if reason := p.filterCombinedUsage(args, node, nodeMetric, filterProfile); reason != nil {
+				return reason, nil
 			}
 		}
 	}
+	if args.GPUUsageThreshold > 0 && requestsGPU(pod) {
+		if reason := filterGPUUsage(nodeMetric, args.GPUUsageThreshold); reason != nil {
+			return reason, nil
+		}
+	}
+
+	return nil, nil
+}
 
+// filterGPUUsage rejects the node if the average utilization of the GPUs reported in NodeMetric reaches the threshold.
+func filterGPUUsage(nodeMetric *slov1alpha1.NodeMetric, threshold int64) *Reason {
+	if nodeMetric.Status.NodeMetric == nil {
+		return nil
+	}
+	var utilization, count int64
+	for _, device := range nodeMetric.Status.NodeMetric.NodeUsage.Devices {
+		if device.Type != schedulingv1alpha1.GPU {
+			continue
+		}
+		if q, ok := device.Resources[extension.ResourceGPUCore]; ok {
+			utilization += q.Value()
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	if utilization/count >= threshold {
+		return &Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: extension.ResourceGPUCore}
+	}
 	return nil
 }
 
-func (p *Plugin) filterNodeUsage(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric, filterProfile *usageThresholdsFilterProfile) *framework.Status {
+func (p *Plugin) filterNodeUsage(args *loadAwareArgs, node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric, filterProfile *usageThresholdsFilterProfile) *Reason {
 	if nodeMetric.Status.NodeMetric == nil {
 		return nil
 	}
 
-	var usageThresholds map[corev1.ResourceName]int64
-	if filterProfile.AggregatedUsage != nil {
-		usageThresholds = filterProfile.AggregatedUsage.UsageThresholds
-	} else {
-		usageThresholds = filterProfile.UsageThresholds
+	usageThresholds, reasonCode := getFilterUsageThresholds(filterProfile)
+
+	// TODO(joseph): maybe we should estimate the Pod that just be scheduled that have not reported
+	nodeUsage := getFilterNodeUsage(args, p.podLister, nodeMetric, filterProfile)
+	if nodeUsage == nil {
+		return nil
 	}
 
-	for resourceName, threshold := range usageThresholds {
+	for _, resourceName := range sortedResourceNames(usageThresholds) {
+		threshold := usageThresholds[resourceName]
 		if threshold == 0 {
 			continue
 		}
+		// the resources not declared in the node allocatable or not reported in NodeMetric,
+		// e.g. the network bandwidths, are skipped.
 		total := node.Status.Allocatable[resourceName]
 		if total.IsZero() {
 			continue
 		}
-		// TODO(joseph): maybe we should estimate the Pod that just be scheduled that have not reported
-		var nodeUsage *slov1alpha1.ResourceMap
-		if filterProfile.AggregatedUsage != nil {
-			nodeUsage = getTargetAggregatedUsage(
-				nodeMetric,
-				filterProfile.AggregatedUsage.UsageAggregatedDuration,
-				filterProfile.AggregatedUsage.UsageAggregationType,
-			)
-		} else {
-			nodeUsage = &nodeMetric.Status.NodeMetric.NodeUsage
-		}
-		if nodeUsage == nil {
+		used, ok := nodeUsage.ResourceList[resourceName]
+		if !ok {
 			continue
 		}
-
-		used := nodeUsage.ResourceList[resourceName]
-		usage := int64(math.Round(float64(used.MilliValue()) / float64(total.MilliValue()) * 100))
+		usage := int64(math.Round(usagePercentage(resourceName, used, total)))
 		if usage >= threshold {
-			reason := ErrReasonUsageExceedThreshold
-			if filterProfile.AggregatedUsage != nil {
-				reason = ErrReasonAggregatedUsageExceedThreshold
-			}
-			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf(reason, resourceName))
+			return &Reason{Code: reasonCode, ResourceName: resourceName}
 		}
 	}
 	return nil
 }
 
-func (p *Plugin) filterProdUsage(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric, prodUsageThresholds map[corev1.ResourceName]int64) *framework.Status {
+// filterCombinedUsage rejects the node if the average utilization of the weighted resources
+// reaches the combined threshold, even if each resource is under its own threshold.
+func (p *Plugin) filterCombinedUsage(args *loadAwareArgs, node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric, filterProfile *usageThresholdsFilterProfile) *Reason {
+	if nodeMetric.Status.NodeMetric == nil {
+		return nil
+	}
+	nodeUsage := getFilterNodeUsage(args, p.podLister, nodeMetric, filterProfile)
+	if nodeUsage == nil {
+		return nil
+	}
+
+	var combinedUsage float64
+	var weightSum int64
+	for resourceName, weight := range args.ResourceWeights {
+		total := node.Status.Allocatable[resourceName]
+		if total.IsZero() {
+			continue
+		}
+		used := nodeUsage.ResourceList[resourceName]
+		combinedUsage += usagePercentage(resourceName, used, total) * float64(weight)
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return nil
+	}
+	if int64(math.Round(combinedUsage/float64(weightSum))) >= args.CombinedThreshold {
+		return &Reason{Code: ReasonCodeCombinedUsageExceedThreshold}
+	}
+	return nil
+}
+
+// getFilterNodeUsage returns the node usage compared with the thresholds, nil if the usage is not reported.
+// The memory usage is discounted by the MemoryCacheDiscountRatio.
+func getFilterNodeUsage(args *loadAwareArgs, podLister corev1listers.PodLister, nodeMetric *slov1alpha1.NodeMetric, filterProfile *usageThresholdsFilterProfile) *slov1alpha1.ResourceMap {
+	var nodeUsage *slov1alpha1.ResourceMap
+	if filterProfile.AggregatedUsage != nil {
+		nodeUsage = getResourcesAggregatedUsage(
+			nodeMetric,
+			filterProfile.AggregatedUsage.UsageAggregatedDuration,
+			filterProfile.AggregatedUsage.UsageAggregationType,
+			getResourceAggregationTypes(args.Aggregated),
+			getMinSampleCount(args.Aggregated),
+		)
+	} else {
+		if args.FilterUsageSource == config.NodeUsageSourcePodsUsage {
+			nodeUsage = sumPodsAndSystemUsage(podLister, nodeMetric)
+		}
+		if nodeUsage == nil {
+			nodeUsage = &nodeMetric.Status.NodeMetric.NodeUsage
+		}
+	}
+	return discountMemoryCache(nodeUsage, args.MemoryCacheDiscountRatio)
+}
+
+func (p *Plugin) filterProdUsage(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric, prodUsageThresholds map[corev1.ResourceName]int64) *Reason {
 	if len(nodeMetric.Status.PodsMetric) == 0 {
 		return nil
 	}
 
 	// TODO(joseph): maybe we should estimate the Pod that just be scheduled that have not reported
-	podMetrics := buildPodMetricMap(p.podLister, nodeMetric, true)
-	prodPodUsages, _ := sumPodUsages(podMetrics, nil)
-	for resourceName, threshold := range prodUsageThresholds {
+	prodPodUsages := extractNodeUsages(p.podLister, nodeMetric).prod
+	for _, resourceName := range sortedResourceNames(prodUsageThresholds) {
+		threshold := prodUsageThresholds[resourceName]
 		if threshold == 0 {
 			continue
 		}
@@ -224,20 +422,78 @@ func (p *Plugin) filterProdUsage(node *corev1.Node, nodeMetric *slov1alpha1.Node
 			continue
 		}
 		used := prodPodUsages[resourceName]
-		usage := int64(math.Round(float64(used.MilliValue()) / float64(total.MilliValue()) * 100))
+		usage := int64(math.Round(usagePercentage(resourceName, used, total)))
 		if usage >= threshold {
-			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, resourceName))
+			return &Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: resourceName}
 		}
 	}
 	return nil
 }
 
-func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+func (p *Plugin) Reserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	if args := p.getArgs(); args.ReserveCheckThresholds && !args.AdvisoryOnly && !args.SoftThreshold && !isDaemonSetPod(pod.OwnerReferences) && !isLoadAwareSchedulingSkipped(pod) {
+		nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+		if err == nil && nodeInfo.Node() != nil && !isLoadAwareExemptNode(nodeInfo.Node(), args.ExemptNodeLabelKey) {
+			if reason := p.reserveNodeUsage(args, pod, nodeInfo.Node()); reason != nil {
+				return newUnschedulableStatus(*reason)
+			}
+		}
+	}
+	// forget the attempts even if the relaxation is disabled now, since they may be
+	// recorded before the dynamic args disabled it.
+	p.unschedulableAttempts.forget(pod.UID)
+	p.podAssignCache.assign(nodeName, pod)
 	return nil
 }
 
-func (p *Plugin) Reserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
-	p.podAssignCache.assign(nodeName, pod)
+// reserveNodeUsage rejects the node if the reported usage plus the estimated usage of the Pods
+// assigned but not reported yet, including the Pod, exceeds the usage thresholds.
+func (p *Plugin) reserveNodeUsage(args *loadAwareArgs, pod *corev1.Pod, node *corev1.Node) *Reason {
+	nodeMetric, err := p.getNodeMetric(args.LoadAwareSchedulingArgs, node.Name)
+	if err != nil || nodeMetric.Status.NodeMetric == nil {
+		return nil
+	}
+	filterProfile := relaxUsageThresholds(p.generateUsageThresholdsFilterProfile(node, args.LoadAwareSchedulingArgs),
+		p.thresholdRelaxation(args, pod), args.MandatoryThresholds)
+	if filterByProdUsage(args.LoadAwareSchedulingArgs, filterProfile, pod) {
+		return nil
+	}
+	usageThresholds, reasonCode := getFilterUsageThresholds(filterProfile)
+	if len(usageThresholds) == 0 {
+		return nil
+	}
+	nodeUsage := getFilterNodeUsage(args, p.podLister, nodeMetric, filterProfile)
+	if nodeUsage == nil {
+		return nil
+	}
+	podEstimatedUsed, err := args.estimator.Estimate(pod)
+	if err != nil {
+		return nil
+	}
+	podMetrics := extractNodeUsages(p.podLister, nodeMetric).getPodMetrics(false)
+	assignedPodEstimatedUsed, estimatedPods := p.estimatedAssignedPodUsed(args, node.Name, nodeMetric, podMetrics, false)
+	_, estimatedPodActualUsages := sumPodUsages(podMetrics, estimatedPods)
+
+	for _, resourceName := range sortedResourceNames(usageThresholds) {
+		threshold := usageThresholds[resourceName]
+		if threshold == 0 {
+			continue
+		}
+		total := getResourceValue(resourceName, node.Status.Allocatable[resourceName])
+		if total == 0 {
+			continue
+		}
+		used := nodeUsage.ResourceList[resourceName]
+		if q := estimatedPodActualUsages[resourceName]; !q.IsZero() && used.Cmp(q) >= 0 {
+			used = used.DeepCopy()
+			used.Sub(q)
+		}
+		estimatedUsed := getResourceValue(resourceName, used) + assignedPodEstimatedUsed[resourceName] + podEstimatedUsed[resourceName]
+		usage := int64(math.Round(float64(estimatedUsed) / float64(total) * 100))
+		if usage >= threshold {
+			return &Reason{Code: reasonCode, ResourceName: resourceName}
+		}
+	}
 	return nil
 }
 
@@ -246,51 +502,185 @@ func (p *Plugin) Unreserve(ctx context.Context, state *framework.CycleState, pod
 }
 
 func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	defer recordPhaseDuration(phaseScore, time.Now())
+	score, status := p.score(ctx, state, pod, nodeName)
+	if status.IsSuccess() && p.getArgs().ScoringDecisionRecordDir != "" {
+		if nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName); err == nil && nodeInfo.Node() != nil {
+			recordDecisionScore(state, nodeInfo.Node(), score)
+		}
+	}
+	return score, status
+}
+
+func (p *Plugin) score(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	if isLoadAwareSchedulingSkipped(pod) {
+		// every node scores 0 for the pod opting out, which is neutral among the nodes.
+		return 0, nil
+	}
+	args := p.getArgs()
+	if args.PreferNominatedNode && pod.Status.NominatedNodeName != "" {
+		if nodeName != pod.Status.NominatedNodeName {
+			recordScoreZeroReason(state, nodeName, Reason{Code: ReasonCodeNotNominatedNode})
+			return 0, nil
+		}
+		score := int64(framework.MaxNodeScore)
+		if args.ScoreScalingPercentage > 0 {
+			score = score * args.ScoreScalingPercentage / 100
+		}
+		return score, nil
+	}
+	s := getStateData(state)
+	if s != nil && s.skipScore {
+		return 0, nil
+	}
 	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
-	if err != nil {
-		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
+	if err != nil || nodeInfo.Node() == nil {
+		// the snapshot fails to get the node only if the node is deleted after filtering,
+		// so skip the node and score the node 0 like the missing NodeMetric
+		klog.V(4).InfoS("Node not found in snapshot, skip scoring", "node", nodeName, "err", err)
+		recordScoreZeroReason(state, nodeName, Reason{Code: ReasonCodeNodeNotFound})
+		return 0, nil
 	}
 	node := nodeInfo.Node()
-	if node == nil {
-		return 0, framework.NewStatus(framework.Error, "node not found")
+	if isLoadAwareExemptNode(node, args.ExemptNodeLabelKey) {
+		// the exempt node scores in the middle without reading NodeMetric,
+		// so that it is neither preferred nor avoided by the load.
+		return neutralScore(args.LoadAwareSchedulingArgs), nil
+	}
+	// the nodes not fully scored score in the middle, so that they are neither preferred nor avoided
+	// by the other score plugins compared with the fully scored nodes.
+	if s != nil && s.topKNodes != nil && !s.topKNodes.Has(nodeName) {
+		return neutralScore(args.LoadAwareSchedulingArgs), nil
+	}
+	if s != nil && s.sampledNodes != nil && !s.sampledNodes.Has(nodeName) {
+		return neutralScore(args.LoadAwareSchedulingArgs), nil
+	}
+	score, detail, status := p.scoreNode(ctx, state, args, pod, node)
+	if reason, ok := getSoftThresholdBreach(state, nodeName); ok {
+		// the node exceeding the usage thresholds is admitted by Filter in SoftThreshold mode,
+		// and it scores 0 to be the last resort.
+		score = 0
+		recordScoreZeroReason(state, nodeName, reason)
+	}
+	if args.ScoreScalingPercentage > 0 {
+		score = score * args.ScoreScalingPercentage / 100
+	}
+	if detail != nil {
+		recordScoreDetail(state, nodeName, detail)
+	}
+	if args.RecordScoreBreakdown && detail != nil {
+		recordScoreBreakdown(state, nodeName, newScoreBreakdown(args.LoadAwareSchedulingArgs, score, detail))
+	}
+	return score, status
+}
+
+// neutralScore returns the score in the middle of the range, which is neither preferred nor avoided.
+func neutralScore(args *config.LoadAwareSchedulingArgs) int64 {
+	score := int64(framework.MaxNodeScore) / 2
+	if args.ScoreScalingPercentage > 0 {
+		score = score * args.ScoreScalingPercentage / 100
+	}
+	return score
+}
+
+// nodeScoreDetail is the estimated used and allocatable of each resource behind the score of a node.
+// All maps have an entry for every weighted resource.
+type nodeScoreDetail struct {
+	// resourceWeights is the weights the node is scored with, which are adjusted for the pod.
+	resourceWeights map[corev1.ResourceName]int64
+	// podEstimatedUsed is the estimated used of the pod to be scheduled.
+	podEstimatedUsed map[corev1.ResourceName]int64
+	// estimatedUsed is the estimated used of the node after placing the pod.
+	estimatedUsed map[corev1.ResourceName]int64
+	allocatable   map[corev1.ResourceName]int64
+	// memoryUtilization is the percentage of the reported memory usage to the allocatable of the node,
+	// which is only set if MemoryPressureKnee is enabled.
+	memoryUtilization int64
+}
+
+// scoreNode scores the node and returns the detail behind the score.
+// The detail is nil if the node is skipped in scoring, and the reason is recorded in the cycleState
+// if the node is scored 0 for a specific reason.
+func (p *Plugin) scoreNode(ctx context.Context, cycleState *framework.CycleState, args *loadAwareArgs, pod *corev1.Pod, node *corev1.Node) (int64, *nodeScoreDetail, *framework.Status) {
+	args = withDefaultResourceWeights(withPriorityClassResourceWeights(args, pod), pod)
+	if args.DynamicResourceWeightMaxBoostPercent > 0 {
+		args = withResourceWeightBoosts(args, p.getResourceWeightBoosts(args))
 	}
-	nodeMetric, err := p.nodeMetricLister.Get(nodeName)
+	nodeName := node.Name
+	nodeMetric, err := p.getNodeMetricWithTimeout(ctx, args.LoadAwareSchedulingArgs, nodeName)
 	if err != nil {
 		// caused by load-aware scheduling itself is an optimization,
 		// so we should skip the node and score the node 0
 		if errors.IsNotFound(err) {
-			return 0, nil
+			recordScoreZeroReason(cycleState, nodeName, Reason{Code: ReasonCodeNodeMetricNotFound})
+			return 0, nil, nil
+		}
+		if err == context.DeadlineExceeded {
+			klog.V(4).InfoS("Timeout getting NodeMetric, skip scoring", "node", nodeName, "timeout", args.NodeMetricTimeout.Duration)
+			recordScoreZeroReason(cycleState, nodeName, Reason{Code: ReasonCodeNodeMetricTimeout})
+			return 0, nil, nil
 		}
-		return 0, framework.NewStatus(framework.Error, err.Error())
+		return 0, nil, framework.NewStatus(framework.Error, err.Error())
 	}
-	if p.args.NodeMetricExpirationSeconds != nil && isNodeMetricExpired(nodeMetric, *p.args.NodeMetricExpirationSeconds) {
-		return 0, nil
+	if p.explainedPod == nil {
+		recordNodeMetricAge(nodeMetric)
+	}
+	if args.ScoringDecisionRecordDir != "" {
+		recordDecisionNodeMetric(cycleState, nodeMetric)
+	}
+	if args.NodeMetricExpirationSeconds != nil && isNodeMetricExpired(nodeMetric, *args.NodeMetricExpirationSeconds) {
+		recordScoreZeroReason(cycleState, nodeName, Reason{Code: ReasonCodeNodeMetricExpired})
+		return 0, nil, nil
 	}
 
-	prodPod := extension.GetPriorityClass(pod) == extension.PriorityProd && p.args.ScoreAccordingProdUsage
-	podMetrics := buildPodMetricMap(p.podLister, nodeMetric, prodPod)
+	prodPod := scoreByProdUsage(args.LoadAwareSchedulingArgs, pod)
+	// the Batch and Free Pods are scored with the batch resources translated from the weighted ones if enabled.
+	batchResourceNames := getBatchResourceNames(args.LoadAwareSchedulingArgs, pod)
+	usages := extractNodeUsages(p.podLister, nodeMetric)
+	podMetrics := usages.getPodMetrics(prodPod)
 
-	estimatedUsed, err := p.estimator.Estimate(pod)
+	podEstimatedUsed, err := args.estimator.Estimate(pod)
 	if err != nil {
-		return 0, nil
+		recordScoreZeroReason(cycleState, nodeName, Reason{Code: ReasonCodeEstimateFailed})
+		return 0, nil, nil
 	}
-	assignedPodEstimatedUsed, estimatedPods := p.estimatedAssignedPodUsed(nodeName, nodeMetric, podMetrics, prodPod)
+	for resourceName, value := range estimatedUsedByRequests(args.ResourceWeights, podEstimatedUsed, pod) {
+		podEstimatedUsed[resourceName] = value
+	}
+	if args.ScoreSafetyBufferPercent > 0 {
+		for resourceName, value := range podEstimatedUsed {
+			podEstimatedUsed[resourceName] = value * (100 + args.ScoreSafetyBufferPercent) / 100
+		}
+	}
+	estimatedUsed := make(map[corev1.ResourceName]int64, len(podEstimatedUsed))
+	for resourceName, value := range podEstimatedUsed {
+		estimatedUsed[resourceName] = value
+	}
+	assignedPodEstimatedUsed, estimatedPods := p.estimatedAssignedPodUsed(args, nodeName, nodeMetric, podMetrics, prodPod)
 	for resourceName, value := range assignedPodEstimatedUsed {
 		estimatedUsed[resourceName] += value
 	}
+	if args.EstimateReservations {
+		for resourceName, value := range p.estimatedReservationUsed(args, nodeName, prodPod) {
+			estimatedUsed[resourceName] += value
+		}
+	}
 	podActualUsages, estimatedPodActualUsages := sumPodUsages(podMetrics, estimatedPods)
 	if prodPod {
 		for resourceName, quantity := range podActualUsages {
 			estimatedUsed[resourceName] += getResourceValue(resourceName, quantity)
 		}
 	} else {
-		if nodeMetric.Status.NodeMetric != nil {
-			var nodeUsage *slov1alpha1.ResourceMap
-			if scoreWithAggregation(p.args.Aggregated) {
-				nodeUsage = getTargetAggregatedUsage(nodeMetric, &p.args.Aggregated.ScoreAggregatedDuration, p.args.Aggregated.ScoreAggregationType)
-			} else {
-				nodeUsage = &nodeMetric.Status.NodeMetric.NodeUsage
+		if usages.total != nil {
+			nodeUsage := usages.total
+			if scoreWithAggregation(args.Aggregated) {
+				nodeUsage = getResourcesAggregatedUsage(nodeMetric, &args.Aggregated.ScoreAggregatedDuration, args.Aggregated.ScoreAggregationType, args.Aggregated.ResourceAggregationTypes, args.Aggregated.MinSampleCount)
+			}
+			if args.ScoreResourceBase == config.LoadAwareResourceBaseAllocatable {
+				nodeUsage = excludeSystemUsage(nodeUsage, nodeMetric)
 			}
+			nodeUsage = discountMemoryCache(translateBatchUsage(nodeUsage, batchResourceNames), args.MemoryCacheDiscountRatio)
+			discountReclaimable := args.ReclaimableUsageWeight != nil && extension.GetPriorityClass(pod) == extension.PriorityProd
 			if nodeUsage != nil {
 				for resourceName, quantity := range nodeUsage.ResourceList {
 					if q := estimatedPodActualUsages[resourceName]; !q.IsZero() {
@@ -299,65 +689,359 @@ func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *co
 							quantity.Sub(q)
 						}
 					}
-					estimatedUsed[resourceName] += getResourceValue(resourceName, quantity)
+					used := getResourceValue(resourceName, quantity)
+					if discountReclaimable {
+						used = discountReclaimableUsage(resourceName, used, usages.batch, *args.ReclaimableUsageWeight)
+					}
+					estimatedUsed[resourceName] += used
 				}
 			}
 		}
 	}
 
-	score := loadAwareSchedulingScorer(p.args.ResourceWeights, estimatedUsed, node.Status.Allocatable)
-	return score, nil
+	// the resources missing in the estimate or in the node allocatable are explicitly zero,
+	// so that the score is always computed with every weighted resource.
+	nodeAllocatable := node.Status.Allocatable
+	if args.ScoreResourceBase == config.LoadAwareResourceBaseCapacity {
+		nodeAllocatable = node.Status.Capacity
+	} else if args.ScoreAccordingNodeReservation {
+		nodeAllocatable, _ = util.TrimNodeAllocatableByNodeReservation(node)
+	}
+	// the resources requested by the Pods committed to the node are not available if ScoreAccordingAvailable.
+	var requested *framework.Resource
+	if args.ScoreAccordingAvailable {
+		if nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName); err == nil {
+			requested = nodeInfo.Requested
+		}
+	}
+	priorityClassResourceNames := getPriorityClassAllocatableResourceNames(args.LoadAwareSchedulingArgs, pod, nodeAllocatable)
+	allocatable := make(map[corev1.ResourceName]int64, len(args.ResourceWeights))
+	for resourceName := range args.ResourceWeights {
+		allocatableResourceName := resourceName
+		if batchResourceName, ok := batchResourceNames[resourceName]; ok {
+			allocatableResourceName = batchResourceName
+		} else if priorityClassResourceName, ok := priorityClassResourceNames[resourceName]; ok {
+			allocatableResourceName = priorityClassResourceName
+		}
+		allocatable[resourceName] = getResourceValue(allocatableResourceName, nodeAllocatable[allocatableResourceName])
+		if requested != nil {
+			allocatable[resourceName] -= getNodeInfoResourceValue(allocatableResourceName, requested)
+			if allocatable[resourceName] < 0 {
+				allocatable[resourceName] = 0
+			}
+		}
+		if _, ok := podEstimatedUsed[resourceName]; !ok {
+			podEstimatedUsed[resourceName] = 0
+		}
+		if _, ok := estimatedUsed[resourceName]; !ok {
+			estimatedUsed[resourceName] = 0
+		}
+	}
+	detail := &nodeScoreDetail{
+		resourceWeights:  args.ResourceWeights,
+		podEstimatedUsed: podEstimatedUsed,
+		estimatedUsed:    estimatedUsed,
+		allocatable:      allocatable,
+	}
+	if args.MemoryPressureKnee > 0 && usages.total != nil {
+		memoryUsage := discountMemoryCache(usages.total, args.MemoryCacheDiscountRatio).ResourceList[corev1.ResourceMemory]
+		if memoryAllocatable := allocatable[corev1.ResourceMemory]; memoryAllocatable > 0 {
+			detail.memoryUtilization = memoryUsage.Value() * 100 / memoryAllocatable
+		}
+	}
+
+	for _, resourceName := range args.CriticalResources {
+		scorer := resourceScorerFor(args.LoadAwareSchedulingArgs, resourceName)
+		if scorer(podEstimatedUsed[resourceName], estimatedUsed[resourceName], allocatable[resourceName]) == 0 {
+			recordScoreZeroReason(cycleState, nodeName, Reason{Code: ReasonCodeCriticalResourceExhausted, ResourceName: resourceName})
+			return 0, detail, nil
+		}
+	}
+	score := loadAwareSchedulingScorer(args.LoadAwareSchedulingArgs, detail)
+	if score == 0 && estimatedUsageExceedAllocatable(args.ResourceWeights, detail) {
+		recordScoreZeroReason(cycleState, nodeName, Reason{Code: ReasonCodeEstimatedUsageExceedAllocatable})
+	}
+	if args.PodDensityWeight > 0 {
+		if nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName); err == nil {
+			score = (score*(100-args.PodDensityWeight) + podDensityScore(nodeInfo)*args.PodDensityWeight) / 100
+		}
+	}
+	if args.PreferredNodeAffinityWeight > 0 {
+		score += preferredNodeAffinityScore(pod, node, args.PreferredNodeAffinityWeight)
+		if score > framework.MaxNodeScore {
+			score = framework.MaxNodeScore
+		}
+	}
+	if args.RequestsUsageGapWeight > 0 && usages.total != nil && isReclaimablePod(pod) {
+		if nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName); err == nil {
+			score += requestsUsageGapScore(nodeInfo, usages.total.ResourceList, args.ResourceWeights, args.RequestsUsageGapWeight)
+			if score > framework.MaxNodeScore {
+				score = framework.MaxNodeScore
+			}
+		}
+	}
+	if args.CompressibilityWeight > 0 {
+		if nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName); err == nil {
+			score += compressibilityScore(nodeInfo, args.CompressibilityWeight)
+			if score > framework.MaxNodeScore {
+				score = framework.MaxNodeScore
+			}
+		}
+	}
+	if args.UsageTrendWeight > 0 && usages.total != nil {
+		averageUsage := getTargetAggregatedUsage(nodeMetric, nil, slov1alpha1.AVG, getMinSampleCount(args.Aggregated))
+		if averageUsage != nil {
+			score += usageTrendScore(usages.total.ResourceList, averageUsage.ResourceList, detail, args.ResourceWeights, args.UsageTrendWeight)
+			if score > framework.MaxNodeScore {
+				score = framework.MaxNodeScore
+			} else if score < 0 {
+				score = 0
+			}
+		}
+	}
+	if args.ThresholdProximityPenalty > 0 {
+		usageThresholds := p.scoreUsageThresholds(node, args.LoadAwareSchedulingArgs, prodPod)
+		score -= thresholdProximityPenalty(usageThresholds, detail, args.ThresholdProximityPenalty)
+		if score < 0 {
+			score = 0
+		}
+	}
+	if args.SiblingPodPenaltyWeight > 0 {
+		score -= p.countRecentSiblingPods(args.LoadAwareSchedulingArgs, pod, nodeName, nodeMetric) * args.SiblingPodPenaltyWeight
+		if score < 0 {
+			score = 0
+		}
+	}
+	if args.SameWorkloadAffinityWeight > 0 {
+		if nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName); err == nil && hostsSameWorkloadPods(pod, nodeInfo) {
+			usageThresholds := p.scoreUsageThresholds(node, args.LoadAwareSchedulingArgs, prodPod)
+			score += args.SameWorkloadAffinityWeight - thresholdProximityPenalty(usageThresholds, detail, args.SameWorkloadAffinityWeight)
+			if score > framework.MaxNodeScore {
+				score = framework.MaxNodeScore
+			}
+		}
+	}
+	return score, detail, nil
+}
+
+// scoreUsageThresholds returns the usage thresholds of the node that the Pod is scored against.
+func (p *Plugin) scoreUsageThresholds(node *corev1.Node, args *config.LoadAwareSchedulingArgs, prodPod bool) map[corev1.ResourceName]int64 {
+	filterProfile := p.generateUsageThresholdsFilterProfile(node, args)
+	if prodPod && args.FilterUsageType != config.LoadAwareUsageTypeTotal && len(filterProfile.ProdUsageThresholds) > 0 {
+		return filterProfile.ProdUsageThresholds
+	}
+	return filterProfile.UsageThresholds
 }
 
-func (p *Plugin) estimatedAssignedPodUsed(nodeName string, nodeMetric *slov1alpha1.NodeMetric, podMetrics map[string]corev1.ResourceList, filterProdPod bool) (map[corev1.ResourceName]int64, sets.String) {
+// countRecentSiblingPods returns the number of Pods of the same controller as the Pod
+// that are assigned to the node but not reported in NodeMetric yet.
+func (p *Plugin) countRecentSiblingPods(args *config.LoadAwareSchedulingArgs, pod *corev1.Pod, nodeName string, nodeMetric *slov1alpha1.NodeMetric) int64 {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return 0
+	}
+	nodeMetricUpdateTime := getNodeMetricUpdateTime(nodeMetric)
+	nodeMetricReportInterval := getNodeMetricReportInterval(args, nodeMetric)
+
+	shard := p.podAssignCache.shardOf(nodeName)
+	shard.lock.RLock()
+	defer shard.lock.RUnlock()
+	var count int64
+	for uid, assignInfo := range shard.podInfoItems[nodeName] {
+		if uid == pod.UID {
+			continue
+		}
+		if siblingOwner := metav1.GetControllerOf(assignInfo.pod); siblingOwner == nil || siblingOwner.UID != owner.UID {
+			continue
+		}
+		if missedLatestUpdateTime(assignInfo.timestamp, nodeMetricUpdateTime) ||
+			stillInTheReportInterval(assignInfo.timestamp, nodeMetricUpdateTime, nodeMetricReportInterval) {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *Plugin) estimatedAssignedPodUsed(args *loadAwareArgs, nodeName string, nodeMetric *slov1alpha1.NodeMetric, podMetrics map[string]corev1.ResourceList, filterProdPod bool) (map[corev1.ResourceName]int64, sets.String) {
+	if p.explainedPod == nil {
+		defer recordPhaseDuration(phaseEstimateAssignedPodUsed, time.Now())
+	}
 	estimatedUsed := make(map[corev1.ResourceName]int64)
 	estimatedPods := sets.NewString()
-	var nodeMetricUpdateTime time.Time
-	if nodeMetric.Status.UpdateTime != nil {
-		nodeMetricUpdateTime = nodeMetric.Status.UpdateTime.Time
-	}
-	nodeMetricReportInterval := getNodeMetricReportInterval(nodeMetric)
+	nodeMetricUpdateTime := getNodeMetricUpdateTime(nodeMetric)
+	nodeMetricReportInterval := getNodeMetricReportInterval(args.LoadAwareSchedulingArgs, nodeMetric)
 
-	p.podAssignCache.lock.RLock()
-	defer p.podAssignCache.lock.RUnlock()
-	for _, assignInfo := range p.podAssignCache.podInfoItems[nodeName] {
+	shard := p.podAssignCache.shardOf(nodeName)
+	shard.lock.RLock()
+	defer shard.lock.RUnlock()
+	for _, assignInfo := range shard.podInfoItems[nodeName] {
+		if p.isExplainedPod(assignInfo.pod) {
+			continue
+		}
 		if filterProdPod && extension.GetPriorityClass(assignInfo.pod) != extension.PriorityProd {
 			continue
 		}
+		if args.EstimatedPodMaxPriority != nil && corev1helpers.PodPriority(assignInfo.pod) > *args.EstimatedPodMaxPriority {
+			continue
+		}
 		podName := getPodNamespacedName(assignInfo.pod.Namespace, assignInfo.pod.Name)
 		podUsage := podMetrics[podName]
-		if len(podUsage) == 0 ||
+		fullyEstimated := len(podUsage) == 0 ||
 			missedLatestUpdateTime(assignInfo.timestamp, nodeMetricUpdateTime) ||
-			stillInTheReportInterval(assignInfo.timestamp, nodeMetricUpdateTime, nodeMetricReportInterval) ||
-			(scoreWithAggregation(p.args.Aggregated) &&
-				getTargetAggregatedUsage(nodeMetric, &p.args.Aggregated.ScoreAggregatedDuration, p.args.Aggregated.ScoreAggregationType) == nil) {
-			estimated, err := p.estimator.Estimate(assignInfo.pod)
-			if err != nil {
-				continue
+			(scoreWithAggregation(args.Aggregated) &&
+				getResourcesAggregatedUsage(nodeMetric, &args.Aggregated.ScoreAggregatedDuration, args.Aggregated.ScoreAggregationType, args.Aggregated.ResourceAggregationTypes, args.Aggregated.MinSampleCount) == nil)
+		if !fullyEstimated && !stillInTheReportInterval(assignInfo.timestamp, nodeMetricUpdateTime, nodeMetricReportInterval) {
+			continue
+		}
+		estimated, err := assignInfo.getEstimated(args.estimator)
+		if err != nil {
+			continue
+		}
+		for resourceName, value := range estimatedUsedByRequests(args.ResourceWeights, estimated, assignInfo.pod) {
+			estimatedUsed[resourceName] += value
+		}
+		// the reported usage of the Pod spanning the update covers part of the report interval,
+		// and only the estimate of the uncovered part is counted on top of the reported usage.
+		uncoveredFraction := 1.0
+		if !fullyEstimated && args.ScaleEstimateByReportOverlap {
+			uncoveredFraction = reportUncoveredFraction(assignInfo.timestamp, nodeMetricUpdateTime, nodeMetricReportInterval)
+		}
+		for resourceName, value := range estimated {
+			var usage int64
+			if quantity, ok := podUsage[resourceName]; ok {
+				usage = getResourceValue(resourceName, quantity)
 			}
-			for resourceName, value := range estimated {
-				if quantity, ok := podUsage[resourceName]; ok {
-					usage := getResourceValue(resourceName, quantity)
-					if usage > value {
-						value = usage
-					}
-				}
-				estimatedUsed[resourceName] += value
+			if usage > value {
+				value = usage
+			} else if uncoveredFraction < 1 {
+				value = usage + int64(float64(value-usage)*uncoveredFraction)
 			}
-			estimatedPods.Insert(podName)
+			estimatedUsed[resourceName] += value
 		}
+		estimatedPods.Insert(podName)
 	}
 	return estimatedUsed, estimatedPods
 }
 
-func loadAwareSchedulingScorer(resToWeightMap, used map[corev1.ResourceName]int64, allocatable corev1.ResourceList) int64 {
+// estimatedUsageExceedAllocatable returns true if the estimated used of every weighted resource reaches the allocatable.
+func estimatedUsageExceedAllocatable(resToWeightMap map[corev1.ResourceName]int64, detail *nodeScoreDetail) bool {
+	for resourceName := range resToWeightMap {
+		if detail.estimatedUsed[resourceName] < detail.allocatable[resourceName] {
+			return false
+		}
+	}
+	return true
+}
+
+func loadAwareSchedulingScorer(args *config.LoadAwareSchedulingArgs, detail *nodeScoreDetail) int64 {
 	var nodeScore, weightSum int64
-	for resourceName, weight := range resToWeightMap {
-		resourceScore := leastRequestedScore(used[resourceName], getResourceValue(resourceName, allocatable[resourceName]))
+	for resourceName, weight := range args.ResourceWeights {
+		weight = effectiveResourceWeight(args, resourceName, weight, detail)
+		resourceScore := resourceScorerFor(args, resourceName)(detail.podEstimatedUsed[resourceName], detail.estimatedUsed[resourceName], detail.allocatable[resourceName])
 		nodeScore += resourceScore * weight
 		weightSum += weight
 	}
-	return nodeScore / weightSum
+	if weightSum == 0 {
+		return 0
+	}
+	// round the weighted average once at the end rather than truncating it,
+	// so that the score is not biased downward as more resources are weighted.
+	return int64(math.Round(float64(nodeScore) / float64(weightSum)))
+}
+
+// memoryPressureMaxWeightMultiplier is the multiplier of the memory weight at full memory utilization.
+const memoryPressureMaxWeightMultiplier int64 = 10
+
+// effectiveResourceWeight returns the weight of the resource in effect on the node. The memory weight increases
+// linearly above MemoryPressureKnee up to memoryPressureMaxWeightMultiplier times at full memory utilization.
+func effectiveResourceWeight(args *config.LoadAwareSchedulingArgs, resourceName corev1.ResourceName, weight int64, detail *nodeScoreDetail) int64 {
+	if resourceName != corev1.ResourceMemory || args.MemoryPressureKnee <= 0 || detail.memoryUtilization <= args.MemoryPressureKnee {
+		return weight
+	}
+	utilization := detail.memoryUtilization
+	if utilization > 100 {
+		utilization = 100
+	}
+	kneeRange := 100 - args.MemoryPressureKnee
+	return weight * (kneeRange + (memoryPressureMaxWeightMultiplier-1)*(utilization-args.MemoryPressureKnee)) / kneeRange
+}
+
+// fullyUsedScore is the score of the resource clamped to the allocatable by ClampToAllocatable.
+const fullyUsedScore int64 = 1
+
+// resourceScorerFunc scores a resource by the estimated used of the pod,
+// the estimated used of the node after placing the pod and the allocatable.
+type resourceScorerFunc func(podRequested, requested, capacity int64) int64
+
+func resourceScorer(strategy config.LoadAwareScoringStrategy) resourceScorerFunc {
+	switch strategy {
+	case config.LoadAwareScoringStrategyBestFit:
+		return func(_, requested, capacity int64) int64 {
+			return bestFitScore(requested, capacity)
+		}
+	case config.LoadAwareScoringStrategyProportionalHeadroom:
+		return proportionalHeadroomScore
+	default:
+		return func(_, requested, capacity int64) int64 {
+			return leastRequestedScore(requested, capacity)
+		}
+	}
+}
+
+// resourceScorerFor returns the scorer of the resource, and the CPU score is blended with
+// the free cores score if FreeCoresScoreWeight is set.
+func resourceScorerFor(args *config.LoadAwareSchedulingArgs, resourceName corev1.ResourceName) resourceScorerFunc {
+	scorer := resourceScorer(args.ScoringStrategy)
+	if resourceName == corev1.ResourceCPU && args.FreeCoresScoreWeight > 0 && args.MaxScoreFreeCores > 0 {
+		percentageScorer, weight, maxScoreFreeCores := scorer, args.FreeCoresScoreWeight, args.MaxScoreFreeCores
+		scorer = func(podRequested, requested, capacity int64) int64 {
+			score := percentageScorer(podRequested, requested, capacity)
+			return (score*(100-weight) + freeCoresScore(requested, capacity, maxScoreFreeCores)*weight) / 100
+		}
+	}
+	if args.EstimatedOverflowPolicy == config.LoadAwareEstimatedOverflowClampToAllocatable {
+		scorer = clampOverflowScorer(scorer)
+	}
+	return scorer
+}
+
+// clampOverflowScorer scores the resource whose estimated used exceeds the allocatable as fully used,
+// which is the lowest nonzero score, so that the node is still preferred to the nodes scoring 0.
+func clampOverflowScorer(scorer resourceScorerFunc) resourceScorerFunc {
+	return func(podRequested, requested, capacity int64) int64 {
+		if capacity > 0 && requested > capacity {
+			return fullyUsedScore
+		}
+		return scorer(podRequested, requested, capacity)
+	}
+}
+
+// podDensityScore scores the free Pod slots after placing the pod on the node,
+// and the nodes without the allowed number of Pods score 0.
+func podDensityScore(nodeInfo *framework.NodeInfo) int64 {
+	allowedPodNumber := int64(nodeInfo.Allocatable.AllowedPodNumber)
+	if allowedPodNumber == 0 {
+		return 0
+	}
+	pods := int64(len(nodeInfo.Pods)) + 1
+	if pods >= allowedPodNumber {
+		return 0
+	}
+	return (allowedPodNumber - pods) * framework.MaxNodeScore / allowedPodNumber
+}
+
+// freeCoresScore scores the free cores in milli after placing the pod, and the nodes with
+// at least maxScoreFreeCores free cores score MaxNodeScore.
+func freeCoresScore(requested, capacity, maxScoreFreeCores int64) int64 {
+	free := capacity - requested
+	if free <= 0 {
+		return 0
+	}
+	maxScoreFree := maxScoreFreeCores * 1000
+	if free >= maxScoreFree {
+		return framework.MaxNodeScore
+	}
+	return free * framework.MaxNodeScore / maxScoreFree
 }
 
 func leastRequestedScore(requested, capacity int64) int64 {
@@ -370,3 +1054,33 @@ func leastRequestedScore(requested, capacity int64) int64 {
 
 	return ((capacity - requested) * framework.MaxNodeScore) / capacity
 }
+
+// bestFitScore favors the nodes with the highest utilization after placing the pod,
+// and the nodes without enough capacity score 0.
+func bestFitScore(requested, capacity int64) int64 {
+	if capacity == 0 {
+		return 0
+	}
+	if requested > capacity {
+		return 0
+	}
+
+	return (requested * framework.MaxNodeScore) / capacity
+}
+
+// proportionalHeadroomScore favors the nodes where the pod takes the least proportion of the headroom,
+// which is the ratio of the headroom after placing the pod to the headroom before.
+func proportionalHeadroomScore(podRequested, requested, capacity int64) int64 {
+	if capacity == 0 {
+		return 0
+	}
+	if requested > capacity {
+		return 0
+	}
+	headroom := capacity - requested + podRequested
+	if headroom <= 0 {
+		return 0
+	}
+
+	return ((headroom - podRequested) * framework.MaxNodeScore) / headroom
+}
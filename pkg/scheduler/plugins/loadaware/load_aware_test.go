@@ -25,6 +25,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -32,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
@@ -40,11 +42,14 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
 	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	slolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 )
 
@@ -104,6 +109,58 @@ func (f *testSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
 	return f.nodeInfoMap[nodeName], nil
 }
 
+func newPluginForTest(t testing.TB, v1beta2args *v1beta2.LoadAwareSchedulingArgs, nodes []*corev1.Node, nodeMetrics []*slov1alpha1.NodeMetric, pods []*corev1.Pod) (*Plugin, *testSharedLister) {
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+
+	koordClientSet := koordfake.NewSimpleClientset()
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+	extenderFactory, _ := frameworkext.NewFrameworkExtenderFactory(
+		frameworkext.WithKoordinatorClientSet(koordClientSet),
+		frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+	)
+	proxyNew := frameworkext.PluginFactoryProxy(extenderFactory, New)
+
+	cs := kubefake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	for _, v := range pods {
+		_, err = cs.CoreV1().Pods(v.Namespace).Create(context.TODO(), v, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	for _, v := range nodes {
+		_, err = cs.CoreV1().Nodes().Create(context.TODO(), v, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	for _, v := range nodeMetrics {
+		_, err = koordClientSet.SloV1alpha1().NodeMetrics().Create(context.TODO(), v, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	snapshot := newTestSharedLister(pods, nodes)
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+		frameworkruntime.WithClientSet(cs),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(snapshot),
+	)
+	assert.Nil(t, err)
+
+	p, err := proxyNew(&loadAwareSchedulingArgs, fh)
+	assert.NoError(t, err)
+	assert.NotNil(t, p)
+
+	informerFactory.Start(context.TODO().Done())
+	informerFactory.WaitForCacheSync(context.TODO().Done())
+	koordSharedInformerFactory.Start(context.TODO().Done())
+	koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
+	return p.(*Plugin), snapshot
+}
+
 func TestNew(t *testing.T) {
 	var v1beta2args v1beta2.LoadAwareSchedulingArgs
 	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
@@ -138,6 +195,64 @@ func TestNew(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestNewWithNonPoolableResourceWeights(t *testing.T) {
+	const msg = "Ignore the weights of the non-poolable resources in scoring, enable scoreNonPoolableResources to score them"
+	hugePages2Mi := corev1.ResourceName(corev1.ResourceHugePagesPrefix + "2Mi")
+	tests := []struct {
+		name                      string
+		scoreNonPoolableResources *bool
+		wantResourceWeights       map[corev1.ResourceName]int64
+		wantWarning               bool
+	}{
+		{
+			name: "hugepages weight is ignored with a warning",
+			wantResourceWeights: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    1,
+				corev1.ResourceMemory: 1,
+			},
+			wantWarning: true,
+		},
+		{
+			name:                      "hugepages weight is kept if explicitly overridden",
+			scoreNonPoolableResources: pointer.Bool(true),
+			wantResourceWeights: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    1,
+				corev1.ResourceMemory: 1,
+				hugePages2Mi:          1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newTestLogger()
+			klog.SetLogger(logger)
+			defer klog.SetLogger(nil)
+
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:    1,
+					corev1.ResourceMemory: 1,
+					hugePages2Mi:          1,
+				},
+				EstimatedScalingFactors: map[corev1.ResourceName]int64{
+					hugePages2Mi: 100,
+				},
+				ScoreNonPoolableResources: tt.scoreNonPoolableResources,
+			}, nil, nil, nil)
+			assert.Equal(t, tt.wantResourceWeights, p.getArgs().ResourceWeights)
+			assert.Len(t, p.staticArgs.ResourceWeights, 3, "the static args should not be changed")
+
+			entry := logger.getEntry(msg)
+			if !tt.wantWarning {
+				assert.Nil(t, entry)
+				return
+			}
+			assert.NotNil(t, entry)
+			assert.Equal(t, []corev1.ResourceName{hugePages2Mi}, entry.fields["resources"])
+		})
+	}
+}
+
 func TestFilterExpiredNodeMetric(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1065,7 +1180,7 @@ func TestScore(t *testing.T) {
 					},
 				},
 			},
-			wantScore:  72,
+			wantScore:  73,
 			wantStatus: nil,
 		},
 		{
@@ -1140,7 +1255,7 @@ func TestScore(t *testing.T) {
 					},
 				},
 			},
-			wantScore:  72,
+			wantScore:  73,
 			wantStatus: nil,
 		},
 		{
@@ -1374,7 +1489,7 @@ func TestScore(t *testing.T) {
 					},
 				},
 			},
-			wantScore:  63,
+			wantScore:  64,
 			wantStatus: nil,
 		},
 		{
@@ -1455,7 +1570,7 @@ func TestScore(t *testing.T) {
 					},
 				},
 			},
-			wantScore:  63,
+			wantScore:  64,
 			wantStatus: nil,
 		},
 		{
@@ -1536,7 +1651,7 @@ func TestScore(t *testing.T) {
 					},
 				},
 			},
-			wantScore:  63,
+			wantScore:  64,
 			wantStatus: nil,
 		},
 		{
@@ -1829,11 +1944,12 @@ func TestScore(t *testing.T) {
 			koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
 
 			assignCache := p.(*Plugin).podAssignCache
+			shard := assignCache.shardOf(tt.nodeName)
 			for _, v := range tt.assignedPod {
-				m := assignCache.podInfoItems[tt.nodeName]
+				m := shard.podInfoItems[tt.nodeName]
 				if m == nil {
 					m = map[types.UID]*podAssignInfo{}
-					assignCache.podInfoItems[tt.nodeName] = m
+					shard.podInfoItems[tt.nodeName] = m
 				}
 				m[v.pod.UID] = v
 			}
@@ -1850,3 +1966,4858 @@ func TestScore(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterUsageSource(t *testing.T) {
+	newPodMetric := func(name string) *slov1alpha1.PodMetricInfo {
+		return &slov1alpha1.PodMetricInfo{
+			Namespace: "default",
+			Name:      name,
+			PodUsage: slov1alpha1.ResourceMap{
+				ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("20"),
+					corev1.ResourceMemory: resource.MustParse("64Gi"),
+				},
+			},
+		}
+	}
+	// the pods use 60 cores and the system uses the other 10 cores of the node usage.
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("70"),
+						corev1.ResourceMemory: resource.MustParse("256Gi"),
+					},
+				},
+				SystemUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10"),
+						corev1.ResourceMemory: resource.MustParse("64Gi"),
+					},
+				},
+			},
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				newPodMetric("test-pod-1"),
+				newPodMetric("test-pod-2"),
+				newPodMetric("test-pod-3"),
+			},
+		},
+	}
+	nodeMetricWithoutPods := nodeMetric.DeepCopy()
+	nodeMetricWithoutPods.Status.PodsMetric = nil
+	// reported by the koordlet not reporting the system usage
+	nodeMetricWithoutSystemUsage := nodeMetric.DeepCopy()
+	nodeMetricWithoutSystemUsage.Status.NodeMetric.SystemUsage = slov1alpha1.ResourceMap{}
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node-1",
+			},
+		}
+	}
+	allPods := []*corev1.Pod{newPod("test-pod-1"), newPod("test-pod-2"), newPod("test-pod-3")}
+	// test-pod-3 is deleted since the NodeMetric was reported
+	podsAfterDeletion := []*corev1.Pod{newPod("test-pod-1"), newPod("test-pod-2")}
+
+	tests := []struct {
+		name        string
+		usageSource v1beta2.NodeUsageSource
+		nodeMetric  *slov1alpha1.NodeMetric
+		pods        []*corev1.Pod
+		wantStatus  *framework.Status
+	}{
+		{
+			name:        "filter by node usage",
+			usageSource: v1beta2.NodeUsageSourceNodeUsage,
+			nodeMetric:  nodeMetric,
+			pods:        allPods,
+			wantStatus:  framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+		{
+			name:        "filter by pods usage and system usage",
+			usageSource: v1beta2.NodeUsageSourcePodsUsage,
+			nodeMetric:  nodeMetric,
+			pods:        allPods,
+			wantStatus:  framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+		{
+			name:        "filter by node usage still including the deleted pod",
+			usageSource: v1beta2.NodeUsageSourceNodeUsage,
+			nodeMetric:  nodeMetric,
+			pods:        podsAfterDeletion,
+			wantStatus:  framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+		{
+			name:        "filter by pods usage excluding the deleted pod",
+			usageSource: v1beta2.NodeUsageSourcePodsUsage,
+			nodeMetric:  nodeMetric,
+			pods:        podsAfterDeletion,
+			// the pods use 40 cores and the system uses 10 cores, which is under the threshold.
+			wantStatus: nil,
+		},
+		{
+			name:        "filter by pods usage but fallback to node usage without pods metric",
+			usageSource: v1beta2.NodeUsageSourcePodsUsage,
+			nodeMetric:  nodeMetricWithoutPods,
+			pods:        podsAfterDeletion,
+			wantStatus:  framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+		{
+			name:        "filter by pods usage but fallback to node usage without system usage",
+			usageSource: v1beta2.NodeUsageSourcePodsUsage,
+			nodeMetric:  nodeMetricWithoutSystemUsage,
+			pods:        podsAfterDeletion,
+			wantStatus:  framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.FilterExpiredNodeMetrics = pointer.Bool(false)
+			v1beta2args.FilterUsageSource = tt.usageSource
+			nodes := []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node-1",
+					},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("96"),
+							corev1.ResourceMemory: resource.MustParse("512Gi"),
+						},
+					},
+				},
+			}
+			p, snapshot := newPluginForTest(t, &v1beta2args, nodes, []*slov1alpha1.NodeMetric{tt.nodeMetric}, tt.pods)
+
+			nodeInfo, err := snapshot.Get("test-node-1")
+			assert.NoError(t, err)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.True(t, tt.wantStatus.Equal(status), "want status: %s, but got %s", tt.wantStatus.Message(), status.Message())
+		})
+	}
+}
+
+func TestScoreWithPreferredNodeAffinity(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, v := range []struct{ name, zone string }{{"test-node-1", "a"}, {"test-node-2", "b"}} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   v.name,
+				Labels: map[string]string{corev1.LabelTopologyZone: v.zone},
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: v.name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("32"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	pod.Spec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+				{
+					Weight: 1,
+					Preference: corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      corev1.LabelTopologyZone,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"b"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		affinityWeight int64
+		wantScores     map[string]int64
+	}{
+		{
+			name:       "preferred node affinity is not enabled by default",
+			wantScores: map[string]int64{"test-node-1": 73, "test-node-2": 73},
+		},
+		{
+			name:           "score with preferred node affinity",
+			affinityWeight: 10,
+			wantScores:     map[string]int64{"test-node-1": 73, "test-node-2": 83},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.PreferredNodeAffinityWeight = tt.affinityWeight
+			p, _ := newPluginForTest(t, &v1beta2args, nodes, nodeMetrics, nil)
+			for nodeName, wantScore := range tt.wantScores {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, nodeName)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, wantScore, score, nodeName)
+			}
+		})
+	}
+}
+
+func TestScoreWithResourceMissingInAllocatable(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		},
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("32"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:        resource.MustParse("16"),
+							corev1.ResourceMemory:     resource.MustParse("32Gi"),
+							extension.ResourceGPUCore: resource.MustParse("2"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:        resource.MustParse("16"),
+							corev1.ResourceMemory:     resource.MustParse("32Gi"),
+							extension.ResourceGPUCore: resource.MustParse("2"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		ResourceWeights: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU:        1,
+			corev1.ResourceMemory:     1,
+			extension.ResourceGPUCore: 1,
+			corev1.ResourceStorage:    1,
+		},
+		EstimatedScalingFactors: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU:        85,
+			corev1.ResourceMemory:     70,
+			extension.ResourceGPUCore: 100,
+			corev1.ResourceStorage:    100,
+		},
+	}, nodes, nodeMetrics, nil)
+
+	score, detail, status := p.scoreNode(context.TODO(), framework.NewCycleState(), p.getArgs(), pod, nodes[0])
+	assert.True(t, status.IsSuccess())
+	estimatedUsed, allocatable := detail.estimatedUsed, detail.allocatable
+	for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, extension.ResourceGPUCore, corev1.ResourceStorage} {
+		assert.Contains(t, estimatedUsed, resourceName)
+		assert.Contains(t, allocatable, resourceName)
+	}
+	assert.Equal(t, int64(2), estimatedUsed[extension.ResourceGPUCore])
+	assert.Equal(t, int64(0), allocatable[extension.ResourceGPUCore])
+	assert.Equal(t, int64(0), estimatedUsed[corev1.ResourceStorage])
+	assert.Equal(t, int64(0), allocatable[corev1.ResourceStorage])
+	// cpu scores 52, memory scores 93, and the resources missing in allocatable score 0.
+	assert.Equal(t, int64((52+93)/4), score)
+
+	gotScore, status := p.Score(context.TODO(), framework.NewCycleState(), pod, "test-node-1")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, score, gotScore)
+}
+
+func TestScoreWithScoringStrategy(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, v := range []struct{ name, cpu, memory string }{
+		{"test-node-1", "96", "512Gi"},
+		{"test-node-2", "32", "64Gi"},
+		{"test-node-3", "16", "64Gi"},
+	} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: v.name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(v.cpu),
+					corev1.ResourceMemory: resource.MustParse(v.memory),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: v.name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		scoringStrategy v1beta2.LoadAwareScoringStrategy
+		wantScores      map[string]int64
+	}{
+		{
+			name:       "spread to the big node by default",
+			wantScores: map[string]int64{"test-node-1": 85, "test-node-2": 41, "test-node-3": 25},
+		},
+		{
+			name:            "spread to the big node with LeastUsage",
+			scoringStrategy: v1beta2.LoadAwareScoringStrategyLeastUsage,
+			wantScores:      map[string]int64{"test-node-1": 85, "test-node-2": 41, "test-node-3": 25},
+		},
+		{
+			name:            "fit the small node with BestFit",
+			scoringStrategy: v1beta2.LoadAwareScoringStrategyBestFit,
+			wantScores:      map[string]int64{"test-node-1": 14, "test-node-2": 59, "test-node-3": 25},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.ScoringStrategy = tt.scoringStrategy
+			p, _ := newPluginForTest(t, &v1beta2args, nodes, nodeMetrics, nil)
+			for nodeName, wantScore := range tt.wantScores {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, nodeName)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, wantScore, score, nodeName)
+			}
+		})
+	}
+}
+
+type errNodeMetricLister struct {
+	slolisters.NodeMetricLister
+	err error
+}
+
+func (l *errNodeMetricLister) Get(name string) (*slov1alpha1.NodeMetric, error) {
+	return nil, l.err
+}
+
+func TestFilterWithNodeMetricListerError(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetricResource := schema.GroupResource{Group: slov1alpha1.GroupVersion.Group, Resource: "nodemetrics"}
+	tests := []struct {
+		name              string
+		requireNodeMetric *bool
+		err               error
+		wantStatus        *framework.Status
+	}{
+		{
+			name: "skip node with not found error",
+			err:  apierrors.NewNotFound(nodeMetricResource, node.Name),
+		},
+		{
+			name:              "reject node with not found error if nodeMetric is required",
+			requireNodeMetric: pointer.Bool(true),
+			err:               apierrors.NewNotFound(nodeMetricResource, node.Name),
+			wantStatus:        framework.NewStatus(framework.Unschedulable, ErrReasonNodeMetricNotFound),
+		},
+		{
+			name:              "skip node with transient error if nodeMetric is required",
+			requireNodeMetric: pointer.Bool(true),
+			err:               apierrors.NewServerTimeout(nodeMetricResource, "get", 1),
+		},
+		{
+			name: "skip node with server timeout error",
+			err:  apierrors.NewServerTimeout(nodeMetricResource, "get", 1),
+		},
+		{
+			name: "skip node with too many requests error",
+			err:  apierrors.NewTooManyRequests("too many requests", 1),
+		},
+		{
+			name: "skip node with service unavailable error",
+			err:  apierrors.NewServiceUnavailable("service unavailable"),
+		},
+		{
+			name: "skip node with context deadline exceeded",
+			err:  fmt.Errorf("failed to get nodeMetric: %w", context.DeadlineExceeded),
+		},
+		{
+			name:       "fail with fatal error",
+			err:        apierrors.NewForbidden(nodeMetricResource, node.Name, fmt.Errorf("forbidden")),
+			wantStatus: framework.NewStatus(framework.Error, apierrors.NewForbidden(nodeMetricResource, node.Name, fmt.Errorf("forbidden")).Error()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				RequireNodeMetric: tt.requireNodeMetric,
+			}, []*corev1.Node{node}, nil, nil)
+			p.nodeMetricLister = &errNodeMetricLister{NodeMetricLister: p.nodeMetricLister, err: tt.err}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestFilterWithMandatoryThresholds(t *testing.T) {
+	tests := []struct {
+		name                string
+		usageThresholds     map[corev1.ResourceName]int64
+		mandatoryThresholds map[corev1.ResourceName]int64
+		cpuUsage            string
+		memoryUsage         string
+		wantStatus          *framework.Status
+	}{
+		{
+			name:        "no thresholds",
+			cpuUsage:    "90",
+			memoryUsage: "500Gi",
+		},
+		{
+			name: "mandatory threshold applies with empty usage thresholds",
+			mandatoryThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceMemory: 90,
+			},
+			cpuUsage:    "90",
+			memoryUsage: "500Gi",
+			wantStatus:  newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceMemory}),
+		},
+		{
+			name: "mandatory threshold is stricter than usage threshold",
+			usageThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceMemory: 95,
+			},
+			mandatoryThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceMemory: 80,
+			},
+			cpuUsage:    "10",
+			memoryUsage: "450Gi",
+			wantStatus:  newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceMemory}),
+		},
+		{
+			name: "usage threshold is stricter than mandatory threshold",
+			usageThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceMemory: 80,
+			},
+			mandatoryThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceMemory: 95,
+			},
+			cpuUsage:    "10",
+			memoryUsage: "450Gi",
+			wantStatus:  newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceMemory}),
+		},
+		{
+			name: "usage thresholds still apply with mandatory thresholds",
+			usageThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU: 65,
+			},
+			mandatoryThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceMemory: 90,
+			},
+			cpuUsage:    "70",
+			memoryUsage: "10Gi",
+			wantStatus:  newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+		},
+		{
+			name: "usage under mandatory threshold",
+			mandatoryThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceMemory: 90,
+			},
+			cpuUsage:    "90",
+			memoryUsage: "400Gi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("96"),
+						corev1.ResourceMemory: resource.MustParse("512Gi"),
+					},
+				},
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{
+						Time: time.Now(),
+					},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse(tt.cpuUsage),
+								corev1.ResourceMemory: resource.MustParse(tt.memoryUsage),
+							},
+						},
+					},
+				},
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				MandatoryThresholds: tt.mandatoryThresholds,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+			// the usage thresholds are defaulted if empty, so clear them here.
+			p.getArgs().UsageThresholds = tt.usageThresholds
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestScoreWithProportionalHeadroom(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, v := range []struct{ name, cpu, cpuUsage string }{
+		{"test-node-1", "96", "48"},
+		{"test-node-2", "32", "4"},
+	} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: v.name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(v.cpu),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: v.name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse(v.cpuUsage),
+						},
+					},
+				},
+			},
+		})
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("16"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("16"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		scoringStrategy v1beta2.LoadAwareScoringStrategy
+		wantScores      map[string]int64
+	}{
+		{
+			name:            "absolute utilization prefers the less utilized node",
+			scoringStrategy: v1beta2.LoadAwareScoringStrategyLeastUsage,
+			wantScores:      map[string]int64{"test-node-1": 35, "test-node-2": 45},
+		},
+		{
+			name:            "proportional headroom prefers the node with more headroom",
+			scoringStrategy: v1beta2.LoadAwareScoringStrategyProportionalHeadroom,
+			wantScores:      map[string]int64{"test-node-1": 71, "test-node-2": 51},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 1,
+				},
+				ScoringStrategy: tt.scoringStrategy,
+			}, nodes, nodeMetrics, nil)
+			for nodeName, wantScore := range tt.wantScores {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, nodeName)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, wantScore, score, nodeName)
+			}
+		})
+	}
+}
+
+func TestProportionalHeadroomScore(t *testing.T) {
+	tests := []struct {
+		name         string
+		podRequested int64
+		requested    int64
+		capacity     int64
+		want         int64
+	}{
+		{name: "zero capacity", podRequested: 1000, requested: 1000, capacity: 0, want: 0},
+		{name: "exceed capacity", podRequested: 1000, requested: 5000, capacity: 4000, want: 0},
+		{name: "pod takes all headroom", podRequested: 1000, requested: 4000, capacity: 4000, want: 0},
+		{name: "pod takes half headroom", podRequested: 1000, requested: 3000, capacity: 4000, want: 50},
+		{name: "pod requests nothing", podRequested: 0, requested: 3000, capacity: 4000, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, proportionalHeadroomScore(tt.podRequested, tt.requested, tt.capacity))
+		})
+	}
+}
+
+func TestFilterWithCombinedThreshold(t *testing.T) {
+	tests := []struct {
+		name              string
+		resourceWeights   map[corev1.ResourceName]int64
+		combinedThreshold int64
+		cpuUsage          string
+		memoryUsage       string
+		wantStatus        *framework.Status
+	}{
+		{
+			name:        "combined threshold is not enabled by default",
+			cpuUsage:    "67",
+			memoryUsage: "358Gi",
+		},
+		{
+			name:              "pass per-resource thresholds but fail combined threshold",
+			combinedThreshold: 65,
+			cpuUsage:          "67",
+			memoryUsage:       "358Gi",
+			wantStatus:        newUnschedulableStatus(Reason{Code: ReasonCodeCombinedUsageExceedThreshold}),
+		},
+		{
+			name:              "pass combined threshold",
+			combinedThreshold: 75,
+			cpuUsage:          "67",
+			memoryUsage:       "358Gi",
+		},
+		{
+			name: "combined usage is weighted",
+			resourceWeights: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    1,
+				corev1.ResourceMemory: 3,
+			},
+			combinedThreshold: 65,
+			cpuUsage:          "67",
+			memoryUsage:       "154Gi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("96"),
+						corev1.ResourceMemory: resource.MustParse("512Gi"),
+					},
+				},
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{
+						Time: time.Now(),
+					},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse(tt.cpuUsage),
+								corev1.ResourceMemory: resource.MustParse(tt.memoryUsage),
+							},
+						},
+					},
+				},
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: tt.resourceWeights,
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:    80,
+					corev1.ResourceMemory: 80,
+				},
+				CombinedThreshold: tt.combinedThreshold,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestAdvisoryOnly(t *testing.T) {
+	var nodes []*corev1.Node
+	for _, name := range []string{"test-node-overloaded", "test-node-expired", "test-node-missing"} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-overloaded",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("90"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-expired",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now().Add(-time.Hour),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		advisoryOnly *bool
+		wantStatuses map[string]*framework.Status
+	}{
+		{
+			name: "filter without advisory only",
+			wantStatuses: map[string]*framework.Status{
+				"test-node-overloaded": newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+				"test-node-expired":    newUnschedulableStatus(Reason{Code: ReasonCodeNodeMetricExpired}),
+				"test-node-missing":    nil,
+			},
+		},
+		{
+			name:         "never filter with advisory only",
+			advisoryOnly: pointer.Bool(true),
+			wantStatuses: map[string]*framework.Status{
+				"test-node-overloaded": nil,
+				"test-node-expired":    nil,
+				"test-node-missing":    nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				AdvisoryOnly: tt.advisoryOnly,
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{}
+			for _, node := range nodes {
+				nodeInfo := framework.NewNodeInfo()
+				nodeInfo.SetNode(node)
+				status := p.Filter(context.TODO(), framework.NewCycleState(), pod, nodeInfo)
+				assert.Equal(t, tt.wantStatuses[node.Name], status, node.Name)
+			}
+
+			// score still ranks by load, and the nodes without valid metrics score 0.
+			wantScores := map[string]int64{"test-node-overloaded": 52, "test-node-expired": 0, "test-node-missing": 0}
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, wantScores[node.Name], score, node.Name)
+			}
+		})
+	}
+}
+
+func TestScoreWithCriticalResources(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		},
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("510Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		criticalResources []corev1.ResourceName
+		wantScore         int64
+	}{
+		{
+			name:      "maxed memory pulls the average down",
+			wantScore: 39,
+		},
+		{
+			name:              "maxed critical memory zeroes the node",
+			criticalResources: []corev1.ResourceName{corev1.ResourceMemory},
+			wantScore:         0,
+		},
+		{
+			name:              "critical cpu is not maxed",
+			criticalResources: []corev1.ResourceName{corev1.ResourceCPU},
+			wantScore:         39,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				CriticalResources: tt.criticalResources,
+			}, nodes, nodeMetrics, nil)
+			score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, "test-node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestValidateCriticalResources(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2args.CriticalResources = []corev1.ResourceName{extension.ResourceGPUCore}
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+	err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "criticalResources[0]")
+}
+
+func TestMemoryCacheDiscountRatio(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("32"),
+						corev1.ResourceMemory: resource.MustParse("500Gi"),
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                     string
+		memoryCacheDiscountRatio int64
+		wantStatus               *framework.Status
+	}{
+		{
+			name:       "high-cache node is filtered without discount",
+			wantStatus: newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceMemory}),
+		},
+		{
+			name:                     "high-cache node is not filtered with discount",
+			memoryCacheDiscountRatio: 30,
+		},
+	}
+	var scores []int64
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				MemoryCacheDiscountRatio: tt.memoryCacheDiscountRatio,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+
+			score, status := p.Score(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, node.Name)
+			assert.True(t, status.IsSuccess())
+			scores = append(scores, score)
+		})
+	}
+	assert.Len(t, scores, 2)
+	assert.Greater(t, scores[1], scores[0], "the discounted memory usage should score higher")
+}
+
+func TestScoreWithNodeRemovedFromSnapshot(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("32"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+			},
+		},
+	}
+	p, lister := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+	assert.True(t, status.IsSuccess())
+
+	// the node is deleted between Filter and Score
+	delete(lister.nodeInfoMap, node.Name)
+	lister.nodeInfos = nil
+
+	score, status := p.Score(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, node.Name)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(0), score)
+}
+
+func TestScoreAccordingNodeReservation(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+			Annotations: map[string]string{
+				extension.AnnotationNodeReservation: `{"resources":{"cpu":"48"}}`,
+			},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("24"),
+						corev1.ResourceMemory: resource.MustParse("128Gi"),
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                          string
+		scoreAccordingNodeReservation *bool
+		wantCPUAllocatable            int64
+		wantScore                     int64
+	}{
+		{
+			name:               "score with node allocatable by default",
+			wantCPUAllocatable: 96000,
+			wantScore:          74,
+		},
+		{
+			name:                          "score with allocatable subtracting the reserved cpu",
+			scoreAccordingNodeReservation: pointer.Bool(true),
+			wantCPUAllocatable:            48000,
+			wantScore:                     62,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreAccordingNodeReservation: tt.scoreAccordingNodeReservation,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+			score, detail, status := p.scoreNode(context.TODO(), framework.NewCycleState(), p.getArgs(), &corev1.Pod{}, node)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantCPUAllocatable, detail.allocatable[corev1.ResourceCPU])
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestMinSampleCount(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	newNodeMetric := func(sampleCount int64) *slov1alpha1.NodeMetric {
+		return &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("30"),
+							corev1.ResourceMemory: resource.MustParse("100Gi"),
+						},
+					},
+					AggregatedNodeUsages: []slov1alpha1.AggregatedUsage{
+						{
+							Duration: metav1.Duration{Duration: 5 * time.Minute},
+							Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
+								slov1alpha1.P95: {
+									ResourceList: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("70"),
+										corev1.ResourceMemory: resource.MustParse("256Gi"),
+									},
+								},
+							},
+							SampleCount: sampleCount,
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name             string
+		minSampleCount   int64
+		sampleCount      int64
+		wantStatus       *framework.Status
+		wantEstimatedCPU int64
+	}{
+		{
+			name:             "aggregated usage is trusted by default",
+			sampleCount:      1,
+			wantStatus:       newUnschedulableStatus(Reason{Code: ReasonCodeAggregatedUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+			wantEstimatedCPU: 70250,
+		},
+		{
+			name:             "under-sampled aggregated usage is not trusted",
+			minSampleCount:   5,
+			sampleCount:      1,
+			wantEstimatedCPU: 250,
+		},
+		{
+			name:             "aggregated usage with enough samples is trusted",
+			minSampleCount:   5,
+			sampleCount:      10,
+			wantStatus:       newUnschedulableStatus(Reason{Code: ReasonCodeAggregatedUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+			wantEstimatedCPU: 70250,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				Aggregated: &v1beta2.LoadAwareSchedulingAggregatedArgs{
+					UsageThresholds: map[corev1.ResourceName]int64{
+						corev1.ResourceCPU: 60,
+					},
+					UsageAggregationType:    slov1alpha1.P95,
+					UsageAggregatedDuration: &metav1.Duration{Duration: 5 * time.Minute},
+					ScoreAggregationType:    slov1alpha1.P95,
+					ScoreAggregatedDuration: &metav1.Duration{Duration: 5 * time.Minute},
+					MinSampleCount:          tt.minSampleCount,
+				},
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{newNodeMetric(tt.sampleCount)}, nil)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+
+			_, detail, status := p.scoreNode(context.TODO(), framework.NewCycleState(), p.getArgs(), &corev1.Pod{}, node)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantEstimatedCPU, detail.estimatedUsed[corev1.ResourceCPU])
+		})
+	}
+}
+
+func TestResourceAggregationTypes(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("30"),
+						corev1.ResourceMemory: resource.MustParse("30Gi"),
+					},
+				},
+				AggregatedNodeUsages: []slov1alpha1.AggregatedUsage{
+					{
+						Duration: metav1.Duration{Duration: 5 * time.Minute},
+						Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
+							slov1alpha1.AVG: {
+								ResourceList: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("30"),
+									corev1.ResourceMemory: resource.MustParse("30Gi"),
+								},
+							},
+							slov1alpha1.P95: {
+								ResourceList: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("50"),
+									corev1.ResourceMemory: resource.MustParse("60Gi"),
+								},
+							},
+							slov1alpha1.P99: {
+								ResourceList: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("90"),
+									corev1.ResourceMemory: resource.MustParse("85Gi"),
+								},
+							},
+						},
+						SampleCount: 10,
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                     string
+		resourceAggregationTypes map[corev1.ResourceName]slov1alpha1.AggregationType
+		wantStatus               *framework.Status
+		wantEstimatedUsed        map[corev1.ResourceName]int64
+	}{
+		{
+			name:       "all resources use the aggregation type",
+			wantStatus: nil,
+			wantEstimatedUsed: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    30250,
+				corev1.ResourceMemory: 30*1024*1024*1024 + 200*1024*1024,
+			},
+		},
+		{
+			name: "p95 for cpu and p99 for memory",
+			resourceAggregationTypes: map[corev1.ResourceName]slov1alpha1.AggregationType{
+				corev1.ResourceCPU:    slov1alpha1.P95,
+				corev1.ResourceMemory: slov1alpha1.P99,
+			},
+			wantStatus: newUnschedulableStatus(Reason{Code: ReasonCodeAggregatedUsageExceedThreshold, ResourceName: corev1.ResourceMemory}),
+			wantEstimatedUsed: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    50250,
+				corev1.ResourceMemory: 85*1024*1024*1024 + 200*1024*1024,
+			},
+		},
+		{
+			name: "the resource falls back to the aggregation type if its own is not reported",
+			resourceAggregationTypes: map[corev1.ResourceName]slov1alpha1.AggregationType{
+				corev1.ResourceCPU:    slov1alpha1.P95,
+				corev1.ResourceMemory: slov1alpha1.P90,
+			},
+			wantStatus: nil,
+			wantEstimatedUsed: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    50250,
+				corev1.ResourceMemory: 30*1024*1024*1024 + 200*1024*1024,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				Aggregated: &v1beta2.LoadAwareSchedulingAggregatedArgs{
+					UsageThresholds: map[corev1.ResourceName]int64{
+						corev1.ResourceCPU:    60,
+						corev1.ResourceMemory: 80,
+					},
+					UsageAggregationType:     slov1alpha1.AVG,
+					UsageAggregatedDuration:  &metav1.Duration{Duration: 5 * time.Minute},
+					ScoreAggregationType:     slov1alpha1.AVG,
+					ScoreAggregatedDuration:  &metav1.Duration{Duration: 5 * time.Minute},
+					ResourceAggregationTypes: tt.resourceAggregationTypes,
+				},
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+
+			_, detail, status := p.scoreNode(context.TODO(), framework.NewCycleState(), p.getArgs(), &corev1.Pod{}, node)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantEstimatedUsed, detail.estimatedUsed)
+		})
+	}
+}
+
+func TestValidateResourceAggregationTypes(t *testing.T) {
+	v1beta2args := v1beta2.LoadAwareSchedulingArgs{
+		Aggregated: &v1beta2.LoadAwareSchedulingAggregatedArgs{
+			ScoreAggregationType: slov1alpha1.P95,
+			ResourceAggregationTypes: map[corev1.ResourceName]slov1alpha1.AggregationType{
+				corev1.ResourceCPU:    slov1alpha1.P95,
+				corev1.ResourceMemory: "max",
+			},
+		},
+	}
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+	err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "aggregated.resourceAggregationTypes[memory]")
+	assert.NotContains(t, err.Error(), "aggregated.resourceAggregationTypes[cpu]")
+}
+
+func TestScoreWithSiblingPodPenalty(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i, cpuUsage := range []string{"10", "30", "30"} {
+		name := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpuUsage),
+							corev1.ResourceMemory: resource.MustParse("100Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	newReplica := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID(name),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: "apps/v1",
+						Kind:       "ReplicaSet",
+						Name:       "test-rs",
+						UID:        "test-rs-uid",
+						Controller: pointer.Bool(true),
+					},
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("1Gi"),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name                    string
+		siblingPodPenaltyWeight int64
+		want                    []string
+	}{
+		{
+			name: "replicas are co-located on the least used node without penalty",
+			want: []string{"test-node-1", "test-node-1", "test-node-1"},
+		},
+		{
+			name:                    "replicas are spread with penalty",
+			siblingPodPenaltyWeight: 30,
+			want:                    []string{"test-node-1", "test-node-2", "test-node-3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				SiblingPodPenaltyWeight: tt.siblingPodPenaltyWeight,
+			}, nodes, nodeMetrics, nil)
+
+			var got []string
+			for i := 0; i < 3; i++ {
+				pod := newReplica(fmt.Sprintf("test-pod-%d", i))
+				selected, maxScore := "", int64(-1)
+				for _, node := range nodes {
+					score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+					assert.True(t, status.IsSuccess())
+					if score > maxScore {
+						selected, maxScore = node.Name, score
+					}
+				}
+				assert.True(t, p.Reserve(context.TODO(), framework.NewCycleState(), pod, selected).IsSuccess())
+				got = append(got, selected)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScoreWithSameWorkloadAffinity(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, name := range []string{"test-node-1", "test-node-2"} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10"),
+							corev1.ResourceMemory: resource.MustParse("100Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	newReplica := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID(name),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: "apps/v1",
+						Kind:       "ReplicaSet",
+						Name:       "test-rs",
+						UID:        "test-rs-uid",
+						Controller: pointer.Bool(true),
+					},
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("8"),
+								corev1.ResourceMemory: resource.MustParse("8Gi"),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name                       string
+		sameWorkloadAffinityWeight int64
+		want                       []string
+	}{
+		{
+			name: "replicas are spread to the least used node without affinity",
+			want: []string{"test-node-1", "test-node-2", "test-node-1", "test-node-2", "test-node-1", "test-node-2"},
+		},
+		{
+			name:                       "replicas are packed until the node gets loaded with affinity",
+			sameWorkloadAffinityWeight: 30,
+			want:                       []string{"test-node-1", "test-node-1", "test-node-1", "test-node-1", "test-node-2", "test-node-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				SameWorkloadAffinityWeight: tt.sameWorkloadAffinityWeight,
+			}, nodes, nodeMetrics, nil)
+
+			var got []string
+			for i := 0; i < 6; i++ {
+				pod := newReplica(fmt.Sprintf("test-pod-%d", i))
+				selected, maxScore := "", int64(-1)
+				for _, node := range nodes {
+					score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+					assert.True(t, status.IsSuccess())
+					if score > maxScore {
+						selected, maxScore = node.Name, score
+					}
+				}
+				assert.True(t, p.Reserve(context.TODO(), framework.NewCycleState(), pod, selected).IsSuccess())
+				nodeInfo, err := snapshot.Get(selected)
+				assert.NoError(t, err)
+				nodeInfo.AddPod(pod)
+				got = append(got, selected)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScoreWithRequestsUsageGap(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	var pods []*corev1.Pod
+	// both nodes use 30 cores, the pods on test-node-1 request what they use
+	// and the pods on test-node-2 request much more than they use.
+	for i, cpuRequest := range []string{"30", "80"} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      fmt.Sprintf("test-pod-%d", i+1),
+				UID:       types.UID(fmt.Sprintf("test-pod-%d", i+1)),
+			},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse(cpuRequest),
+								corev1.ResourceMemory: resource.MustParse("100Gi"),
+							},
+						},
+					},
+				},
+			},
+		})
+		usage := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("30"),
+			corev1.ResourceMemory: resource.MustParse("100Gi"),
+		}
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: usage,
+					},
+				},
+				PodsMetric: []*slov1alpha1.PodMetricInfo{
+					{
+						Namespace: "default",
+						Name:      fmt.Sprintf("test-pod-%d", i+1),
+						PodUsage: slov1alpha1.ResourceMap{
+							ResourceList: usage,
+						},
+					},
+				},
+			},
+		})
+	}
+	batchPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "batch-pod",
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(extension.PriorityBatchValueMin),
+		},
+	}
+	prodPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "prod-pod",
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(extension.PriorityProdValueMin),
+		},
+	}
+	tests := []struct {
+		name                   string
+		requestsUsageGapWeight int64
+		pod                    *corev1.Pod
+		wantScores             []int64
+	}{
+		{
+			name:       "nodes score the same without gap weight",
+			pod:        batchPod,
+			wantScores: []int64{74, 74},
+		},
+		{
+			name:                   "over-requesting node is preferred for batch pod",
+			requestsUsageGapWeight: 20,
+			pod:                    batchPod,
+			wantScores:             []int64{74, 79},
+		},
+		{
+			name:                   "gap weight is ignored for prod pod",
+			requestsUsageGapWeight: 20,
+			pod:                    prodPod,
+			wantScores:             []int64{74, 74},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the pods are assigned long before NodeMetric is reported, so their usages are trusted.
+			preTimeNowFn := timeNowFn
+			defer func() {
+				timeNowFn = preTimeNowFn
+			}()
+			timeNowFn = func() time.Time {
+				return time.Now().Add(-10 * time.Minute)
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				RequestsUsageGapWeight: tt.requestsUsageGapWeight,
+			}, nodes, nodeMetrics, pods)
+
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), tt.pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestScoreWithThresholdProximityPenalty(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for name, cpuUsage := range map[string]string{"test-node-light": "10", "test-node-near-threshold": "75"} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpuUsage),
+							corev1.ResourceMemory: resource.MustParse("100Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                      string
+		thresholdProximityPenalty int64
+		wantScores                map[string]int64
+	}{
+		{
+			name: "score without penalty",
+			wantScores: map[string]int64{
+				"test-node-light":          85,
+				"test-node-near-threshold": 51,
+			},
+		},
+		{
+			name:                      "near-threshold node is strongly deprioritized with penalty",
+			thresholdProximityPenalty: 40,
+			wantScores: map[string]int64{
+				"test-node-light":          77,
+				"test-node-near-threshold": 12,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:    80,
+					corev1.ResourceMemory: 95,
+				},
+				ThresholdProximityPenalty: tt.thresholdProximityPenalty,
+			}, nodes, nodeMetrics, nil)
+			for _, node := range nodes {
+				nodeInfo := framework.NewNodeInfo()
+				nodeInfo.SetNode(node)
+				status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+				assert.True(t, status.IsSuccess(), node.Name)
+
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, node.Name)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, tt.wantScores[node.Name], score, node.Name)
+			}
+		})
+	}
+}
+
+func TestReserveCheckThresholds(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("40"),
+						corev1.ResourceMemory: resource.MustParse("100Gi"),
+					},
+				},
+			},
+		},
+	}
+	newTestPod := func(name, cpu string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID(name),
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse(cpu),
+								corev1.ResourceMemory: resource.MustParse("4Gi"),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name                   string
+		reserveCheckThresholds *bool
+		racingPod              *corev1.Pod
+		wantStatus             *framework.Status
+	}{
+		{
+			name:      "reserve always succeeds without check",
+			racingPod: newTestPod("racing-pod", "30"),
+		},
+		{
+			name:                   "reserve succeeds with check if node is not filled",
+			reserveCheckThresholds: pointer.Bool(true),
+		},
+		{
+			name:                   "reserve fails with check if node is filled after filter",
+			reserveCheckThresholds: pointer.Bool(true),
+			racingPod:              newTestPod("racing-pod", "30"),
+			wantStatus:             newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ReserveCheckThresholds: tt.reserveCheckThresholds,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+			nodeInfo, err := snapshot.Get(node.Name)
+			assert.NoError(t, err)
+
+			pod := newTestPod("test-pod", "4")
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+			if tt.racingPod != nil {
+				p.podAssignCache.assign(node.Name, tt.racingPod)
+			}
+			status := p.Reserve(context.TODO(), cycleState, pod, node.Name)
+			assert.Equal(t, tt.wantStatus, status)
+			_, assigned := p.podAssignCache.shardOf(node.Name).podInfoItems[node.Name][pod.UID]
+			assert.Equal(t, status.IsSuccess(), assigned)
+		})
+	}
+}
+
+func TestScoreWithScoreScalingPercentage(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i, cpuUsage := range []string{"0", "48"} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpuUsage),
+							corev1.ResourceMemory: resource.MustParse("0"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                   string
+		scoreScalingPercentage int64
+		wantMaxScore           int64
+		wantScores             []int64
+	}{
+		{
+			name:         "score without scaling",
+			wantMaxScore: framework.MaxNodeScore,
+			wantScores:   []int64{99, 74},
+		},
+		{
+			name:                   "score is capped by scaling",
+			scoreScalingPercentage: 30,
+			wantMaxScore:           30,
+			wantScores:             []int64{29, 22},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreScalingPercentage: tt.scoreScalingPercentage,
+			}, nodes, nodeMetrics, nil)
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, node.Name)
+				assert.True(t, status.IsSuccess())
+				assert.LessOrEqual(t, score, tt.wantMaxScore)
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestFilterWithHugeMemoryNode(t *testing.T) {
+	tests := []struct {
+		name        string
+		allocatable string
+		memoryUsage string
+		wantStatus  *framework.Status
+	}{
+		{
+			name:        "multi-terabyte node under threshold",
+			allocatable: "16Ti",
+			memoryUsage: "12Ti",
+		},
+		{
+			name:        "multi-terabyte node exceeds threshold",
+			allocatable: "16Ti",
+			memoryUsage: "15Ti",
+			wantStatus:  newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceMemory}),
+		},
+		{
+			name:        "milli value of memory overflows int64",
+			allocatable: "20Pi",
+			memoryUsage: "8Pi",
+		},
+		{
+			name:        "milli value of memory overflows int64 and exceeds threshold",
+			allocatable: "20Pi",
+			memoryUsage: "19Pi",
+			wantStatus:  newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceMemory}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("448"),
+						corev1.ResourceMemory: resource.MustParse(tt.allocatable),
+					},
+				},
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{
+						Time: time.Now(),
+					},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("10"),
+								corev1.ResourceMemory: resource.MustParse(tt.memoryUsage),
+							},
+						},
+					},
+				},
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceMemory: 90,
+				},
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestEstimatedAssignedPodUsedWithReportOverlap(t *testing.T) {
+	updateTime := time.Now()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod-1",
+			UID:       "123456789",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: updateTime,
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{},
+		},
+	}
+	// the estimate of the pod is 3400m cpu, and the reported usage is 1000m cpu.
+	podMetrics := map[string]corev1.ResourceList{
+		"default/test-pod-1": {
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	tests := []struct {
+		name                         string
+		scaleEstimateByReportOverlap bool
+		assignedBeforeUpdate         time.Duration
+		wantEstimatedCPU             int64
+		wantEstimated                bool
+	}{
+		{
+			name:                         "assigned after the update is fully estimated",
+			scaleEstimateByReportOverlap: true,
+			assignedBeforeUpdate:         -10 * time.Second,
+			wantEstimatedCPU:             3400,
+			wantEstimated:                true,
+		},
+		{
+			name:                 "assigned within the report interval is fully estimated by default",
+			assignedBeforeUpdate: 15 * time.Second,
+			wantEstimatedCPU:     3400,
+			wantEstimated:        true,
+		},
+		{
+			name:                         "assigned shortly before the update",
+			scaleEstimateByReportOverlap: true,
+			assignedBeforeUpdate:         15 * time.Second,
+			wantEstimatedCPU:             1000 + 2400*3/4,
+			wantEstimated:                true,
+		},
+		{
+			name:                         "assigned early in the report interval",
+			scaleEstimateByReportOverlap: true,
+			assignedBeforeUpdate:         45 * time.Second,
+			wantEstimatedCPU:             1000 + 2400/4,
+			wantEstimated:                true,
+		},
+		{
+			name:                         "assigned before the report interval is not estimated",
+			scaleEstimateByReportOverlap: true,
+			assignedBeforeUpdate:         90 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScaleEstimateByReportOverlap: pointer.Bool(tt.scaleEstimateByReportOverlap),
+			}, nil, nil, nil)
+			preTimeNowFn := timeNowFn
+			defer func() {
+				timeNowFn = preTimeNowFn
+			}()
+			timeNowFn = func() time.Time {
+				return updateTime.Add(-tt.assignedBeforeUpdate)
+			}
+			p.podAssignCache.assign(pod.Spec.NodeName, pod)
+
+			estimatedUsed, estimatedPods := p.estimatedAssignedPodUsed(p.getArgs(), pod.Spec.NodeName, nodeMetric, podMetrics, false)
+			assert.Equal(t, tt.wantEstimatedCPU, estimatedUsed[corev1.ResourceCPU])
+			assert.Equal(t, tt.wantEstimated, estimatedPods.Has("default/test-pod-1"))
+		})
+	}
+}
+
+func TestEstimatedAssignedPodUsedWithMaxPriority(t *testing.T) {
+	newPod := func(name string, priority *int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID(name),
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node-1",
+				Priority: priority,
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("4"),
+								corev1.ResourceMemory: resource.MustParse("8Gi"),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	pods := []*corev1.Pod{
+		newPod("test-pod-low", pointer.Int32(extension.PriorityBatchValueMax)),
+		newPod("test-pod-high", pointer.Int32(extension.PriorityProdValueMax)),
+		newPod("test-pod-no-priority", nil),
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now().Add(-time.Minute),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{},
+		},
+	}
+	tests := []struct {
+		name                    string
+		estimatedPodMaxPriority *int32
+		wantEstimatedPods       []string
+	}{
+		{
+			name:              "all pods are estimated by default",
+			wantEstimatedPods: []string{"default/test-pod-high", "default/test-pod-low", "default/test-pod-no-priority"},
+		},
+		{
+			name:                    "high priority pod is excluded",
+			estimatedPodMaxPriority: pointer.Int32(extension.PriorityMidValueMax),
+			wantEstimatedPods:       []string{"default/test-pod-low", "default/test-pod-no-priority"},
+		},
+		{
+			name:                    "pod with the max priority is estimated",
+			estimatedPodMaxPriority: pointer.Int32(extension.PriorityProdValueMax),
+			wantEstimatedPods:       []string{"default/test-pod-high", "default/test-pod-low", "default/test-pod-no-priority"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				EstimatedPodMaxPriority: tt.estimatedPodMaxPriority,
+			}, nil, nil, nil)
+			for _, pod := range pods {
+				p.podAssignCache.assign(pod.Spec.NodeName, pod)
+			}
+
+			_, estimatedPods := p.estimatedAssignedPodUsed(p.getArgs(), "test-node-1", nodeMetric, nil, false)
+			assert.Equal(t, tt.wantEstimatedPods, estimatedPods.List())
+		})
+	}
+}
+
+func TestFilterWithNetworkThresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		bandwidth  string
+		networkRX  string
+		networkTX  string
+		wantStatus *framework.Status
+	}{
+		{
+			name:      "network under thresholds",
+			bandwidth: "10Gi",
+			networkRX: "5Gi",
+			networkTX: "5Gi",
+		},
+		{
+			name:       "network rx saturated",
+			bandwidth:  "10Gi",
+			networkRX:  "9500Mi",
+			networkTX:  "1Gi",
+			wantStatus: newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: extension.ResourceNetworkRX}),
+		},
+		{
+			name:       "network tx saturated",
+			bandwidth:  "10Gi",
+			networkRX:  "1Gi",
+			networkTX:  "9Gi",
+			wantStatus: newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: extension.ResourceNetworkTX}),
+		},
+		{
+			name:      "network usage not reported",
+			bandwidth: "10Gi",
+		},
+		{
+			name:      "network bandwidth not declared",
+			networkRX: "9500Mi",
+			networkTX: "9500Mi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("96"),
+						corev1.ResourceMemory: resource.MustParse("512Gi"),
+					},
+				},
+			}
+			if tt.bandwidth != "" {
+				node.Status.Allocatable[extension.ResourceNetworkRX] = resource.MustParse(tt.bandwidth)
+				node.Status.Allocatable[extension.ResourceNetworkTX] = resource.MustParse(tt.bandwidth)
+			}
+			nodeUsage := corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			}
+			if tt.networkRX != "" {
+				nodeUsage[extension.ResourceNetworkRX] = resource.MustParse(tt.networkRX)
+			}
+			if tt.networkTX != "" {
+				nodeUsage[extension.ResourceNetworkTX] = resource.MustParse(tt.networkTX)
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{
+						Time: time.Now(),
+					},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: slov1alpha1.ResourceMap{
+							ResourceList: nodeUsage,
+						},
+					},
+				},
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:          65,
+					corev1.ResourceMemory:       95,
+					extension.ResourceNetworkRX: 90,
+					extension.ResourceNetworkTX: 90,
+				},
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestScoreWithFreeCores(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i, cores := range []int64{16, 128} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewQuantity(cores, resource.DecimalSI),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(cores/2, resource.DecimalSI),
+							corev1.ResourceMemory: resource.MustParse("0"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                 string
+		freeCoresScoreWeight int64
+		wantScores           []int64
+	}{
+		{
+			name:       "close scores at the same CPU utilization",
+			wantScores: []int64{48, 49},
+		},
+		{
+			name:                 "larger node with more free cores scores higher",
+			freeCoresScoreWeight: 50,
+			wantScores:           []int64{36, 74},
+		},
+		{
+			name:                 "score by free cores only",
+			freeCoresScoreWeight: 100,
+			wantScores:           []int64{24, 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 1,
+				},
+				FreeCoresScoreWeight: tt.freeCoresScoreWeight,
+				MaxScoreFreeCores:    32,
+			}, nodes, nodeMetrics, nil)
+			var scores []int64
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestFreeCoresScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested int64
+		capacity  int64
+		want      int64
+	}{
+		{name: "no free cores", requested: 16000, capacity: 16000, want: 0},
+		{name: "overcommitted", requested: 20000, capacity: 16000, want: 0},
+		{name: "free cores in proportion", requested: 8000, capacity: 16000, want: 25},
+		{name: "free cores capped", requested: 0, capacity: 128000, want: framework.MaxNodeScore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, freeCoresScore(tt.requested, tt.capacity, 32))
+		})
+	}
+}
+
+func TestScoreWithEstimatedOverflowPolicy(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("3"),
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                    string
+		estimatedOverflowPolicy v1beta2.LoadAwareEstimatedOverflowPolicy
+		podCPU                  string
+		wantScore               int64
+	}{
+		{
+			name:      "overflow scores zero by default",
+			podCPU:    "2",
+			wantScore: 0,
+		},
+		{
+			name:                    "overflow scores zero",
+			estimatedOverflowPolicy: v1beta2.LoadAwareEstimatedOverflowScoreZero,
+			podCPU:                  "2",
+			wantScore:               0,
+		},
+		{
+			name:                    "overflow is clamped to fully used",
+			estimatedOverflowPolicy: v1beta2.LoadAwareEstimatedOverflowClampToAllocatable,
+			podCPU:                  "2",
+			wantScore:               fullyUsedScore,
+		},
+		{
+			name:                    "no overflow is scored as usual",
+			estimatedOverflowPolicy: v1beta2.LoadAwareEstimatedOverflowClampToAllocatable,
+			podCPU:                  "500m",
+			wantScore:               14,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 1,
+				},
+				EstimatedOverflowPolicy: tt.estimatedOverflowPolicy,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse(tt.podCPU),
+								},
+							},
+						},
+					},
+				},
+			}
+			score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestScoreWithReclaimableUsageWeight(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	var pods []*corev1.Pod
+	for i, priority := range []int32{extension.PriorityBatchValueMax, extension.PriorityProdValueMax} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		podName := fmt.Sprintf("test-pod-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("32"),
+					corev1.ResourceMemory: resource.MustParse("64Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("0"),
+						},
+					},
+				},
+				PodsMetric: []*slov1alpha1.PodMetricInfo{
+					{
+						Namespace: "default",
+						Name:      podName,
+						PodUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse("16"),
+							},
+						},
+					},
+				},
+			},
+		})
+		pods = append(pods, schedulertesting.MakePod().Namespace("default").Name(podName).Priority(priority).Obj())
+	}
+	tests := []struct {
+		name                   string
+		reclaimableUsageWeight *int64
+		priority               int32
+		wantScores             []int64
+	}{
+		{
+			name:       "reclaimable usage counted fully",
+			priority:   extension.PriorityProdValueMax,
+			wantScores: []int64{49, 49},
+		},
+		{
+			name:                   "reclaimable usage weighted for prod pod",
+			reclaimableUsageWeight: pointer.Int64(50),
+			priority:               extension.PriorityProdValueMax,
+			wantScores:             []int64{74, 49},
+		},
+		{
+			name:                   "reclaimable usage ignored for prod pod",
+			reclaimableUsageWeight: pointer.Int64(0),
+			priority:               extension.PriorityProdValueMax,
+			wantScores:             []int64{99, 49},
+		},
+		{
+			name:                   "reclaimable usage counted fully for batch pod",
+			reclaimableUsageWeight: pointer.Int64(50),
+			priority:               extension.PriorityBatchValueMax,
+			wantScores:             []int64{49, 49},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 1,
+				},
+				ReclaimableUsageWeight: tt.reclaimableUsageWeight,
+			}, nodes, nodeMetrics, pods)
+			pod := schedulertesting.MakePod().Namespace("default").Name("test-pod").Priority(tt.priority).Obj()
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestScoreWithUsageTrend(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i, averageCPUUsage := range []string{"50", "90"} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("70"),
+							corev1.ResourceMemory: resource.MustParse("0"),
+						},
+					},
+					AggregatedNodeUsages: []slov1alpha1.AggregatedUsage{
+						{
+							Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
+								slov1alpha1.AVG: {
+									ResourceList: corev1.ResourceList{
+										corev1.ResourceCPU: resource.MustParse("70"),
+									},
+								},
+							},
+							Duration: metav1.Duration{Duration: 5 * time.Minute},
+						},
+						{
+							Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
+								slov1alpha1.AVG: {
+									ResourceList: corev1.ResourceList{
+										corev1.ResourceCPU: resource.MustParse(averageCPUUsage),
+									},
+								},
+							},
+							Duration: metav1.Duration{Duration: 30 * time.Minute},
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name             string
+		usageTrendWeight int64
+		wantScores       []int64
+	}{
+		{
+			name:       "score without trend",
+			wantScores: []int64{29, 29},
+		},
+		{
+			name:             "increasing usage is penalized and decreasing usage is rewarded",
+			usageTrendWeight: 20,
+			wantScores:       []int64{25, 33},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 1,
+				},
+				UsageTrendWeight: tt.usageTrendWeight,
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestFilterWithGPUUsageThreshold(t *testing.T) {
+	newGPU := func(minor int32, utilization int64) schedulingv1alpha1.DeviceInfo {
+		return schedulingv1alpha1.DeviceInfo{
+			Minor: pointer.Int32(minor),
+			Type:  schedulingv1alpha1.GPU,
+			Resources: corev1.ResourceList{
+				extension.ResourceGPUCore: *resource.NewQuantity(utilization, resource.DecimalSI),
+			},
+		}
+	}
+	tests := []struct {
+		name              string
+		gpuUsageThreshold int64
+		gpuPod            bool
+		devices           []schedulingv1alpha1.DeviceInfo
+		wantStatus        *framework.Status
+	}{
+		{
+			name:       "not enabled",
+			gpuPod:     true,
+			devices:    []schedulingv1alpha1.DeviceInfo{newGPU(0, 100), newGPU(1, 100)},
+			wantStatus: nil,
+		},
+		{
+			name:              "gpu saturated node rejects gpu pod",
+			gpuUsageThreshold: 90,
+			gpuPod:            true,
+			devices:           []schedulingv1alpha1.DeviceInfo{newGPU(0, 100), newGPU(1, 95)},
+			wantStatus:        framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, extension.ResourceGPUCore)),
+		},
+		{
+			name:              "gpu saturated node admits non-gpu pod",
+			gpuUsageThreshold: 90,
+			devices:           []schedulingv1alpha1.DeviceInfo{newGPU(0, 100), newGPU(1, 95)},
+			wantStatus:        nil,
+		},
+		{
+			name:              "gpu under threshold on average",
+			gpuUsageThreshold: 90,
+			gpuPod:            true,
+			devices:           []schedulingv1alpha1.DeviceInfo{newGPU(0, 100), newGPU(1, 20)},
+			wantStatus:        nil,
+		},
+		{
+			name:              "gpu usage not reported",
+			gpuUsageThreshold: 90,
+			gpuPod:            true,
+			wantStatus:        nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:          resource.MustParse("96"),
+						corev1.ResourceMemory:       resource.MustParse("512Gi"),
+						extension.ResourceNvidiaGPU: resource.MustParse("2"),
+					},
+				},
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: node.Name,
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{
+						Time: time.Now(),
+					},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("10"),
+								corev1.ResourceMemory: resource.MustParse("10Gi"),
+							},
+							Devices: tt.devices,
+						},
+					},
+				},
+			}
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				GPUUsageThreshold: tt.gpuUsageThreshold,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+						},
+					},
+				},
+			}
+			if tt.gpuPod {
+				pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+					extension.ResourceNvidiaGPU: resource.MustParse("1"),
+				}
+			}
+			nodeInfo, err := snapshot.Get(node.Name)
+			assert.NoError(t, err)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), pod, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestSoftThreshold(t *testing.T) {
+	tests := []struct {
+		name             string
+		softThreshold    bool
+		wantFilterStatus map[string]*framework.Status
+		wantScores       map[string]int64
+		wantScoreReasons map[string]Reason
+	}{
+		{
+			name: "over-threshold node is rejected by default",
+			wantFilterStatus: map[string]*framework.Status{
+				"test-node-1": framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+				"test-node-2": nil,
+			},
+		},
+		{
+			name:          "over-threshold node is feasible but ranks last in SoftThreshold mode",
+			softThreshold: true,
+			wantFilterStatus: map[string]*framework.Status{
+				"test-node-1": nil,
+				"test-node-2": nil,
+			},
+			wantScores: map[string]int64{
+				"test-node-1": 0,
+				"test-node-2": 25,
+			},
+			wantScoreReasons: map[string]Reason{
+				"test-node-1": {Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nodes []*corev1.Node
+			var nodeMetrics []*slov1alpha1.NodeMetric
+			for nodeName, cpuUsage := range map[string]string{"test-node-1": "90", "test-node-2": "50"} {
+				nodes = append(nodes, &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+					},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100"),
+							corev1.ResourceMemory: resource.MustParse("100Gi"),
+						},
+					},
+				})
+				nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+					},
+					Status: slov1alpha1.NodeMetricStatus{
+						UpdateTime: &metav1.Time{
+							Time: time.Now(),
+						},
+						NodeMetric: &slov1alpha1.NodeMetricInfo{
+							NodeUsage: slov1alpha1.ResourceMap{
+								ResourceList: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse(cpuUsage),
+									corev1.ResourceMemory: resource.MustParse("0"),
+								},
+							},
+						},
+					},
+				})
+			}
+			// BestFit prefers the hotter node, so the over-threshold node ranks last only due to the penalty.
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoringStrategy: v1beta2.LoadAwareScoringStrategyBestFit,
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 65,
+				},
+				SoftThreshold: pointer.Bool(tt.softThreshold),
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+						},
+					},
+				},
+			}
+
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+			var feasibleNodes []*corev1.Node
+			for _, node := range nodes {
+				nodeInfo, err := snapshot.Get(node.Name)
+				assert.NoError(t, err)
+				status := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+				assert.Equal(t, tt.wantFilterStatus[node.Name], status, node.Name)
+				if status.IsSuccess() {
+					feasibleNodes = append(feasibleNodes, node)
+				}
+			}
+			if !tt.softThreshold {
+				return
+			}
+			assert.Empty(t, GetFilterReasons(cycleState))
+
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, feasibleNodes).IsSuccess())
+			scores := map[string]int64{}
+			for _, node := range feasibleNodes {
+				score, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores[node.Name] = score
+			}
+			assert.Equal(t, tt.wantScores, scores)
+			assert.Equal(t, tt.wantScoreReasons, GetScoreZeroReasons(cycleState))
+		})
+	}
+}
+
+func TestSkipLoadAwareSchedulingByAnnotation(t *testing.T) {
+	tests := []struct {
+		name             string
+		annotations      map[string]string
+		wantStatus       *framework.Status
+		wantScoreSkipped bool
+	}{
+		{
+			name:       "pod not opting out",
+			wantStatus: framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+		{
+			name:             "pod opting out bypasses both Filter and Score",
+			annotations:      map[string]string{extension.AnnotationSkipLoadAwareScheduling: "true"},
+			wantScoreSkipped: true,
+		},
+		{
+			name:        "pod opting out explicitly false",
+			annotations: map[string]string{extension.AnnotationSkipLoadAwareScheduling: "false"},
+			wantStatus:  framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+		{
+			name:        "invalid annotation is ignored",
+			annotations: map[string]string{extension.AnnotationSkipLoadAwareScheduling: "invalid"},
+			wantStatus:  framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100"),
+						corev1.ResourceMemory: resource.MustParse("100Gi"),
+					},
+				},
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: node.Name,
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{
+						Time: time.Now(),
+					},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("90"),
+								corev1.ResourceMemory: resource.MustParse("10Gi"),
+							},
+						},
+					},
+				},
+			}
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 65,
+				},
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Name:        "test-pod",
+					Annotations: tt.annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+						},
+					},
+				},
+			}
+
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+			nodeInfo, err := snapshot.Get(node.Name)
+			assert.NoError(t, err)
+			status := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+			if !status.IsSuccess() {
+				return
+			}
+
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, []*corev1.Node{node}).IsSuccess())
+			score, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+			assert.True(t, status.IsSuccess())
+			if tt.wantScoreSkipped {
+				assert.Equal(t, int64(0), score)
+				assert.Empty(t, GetScoreZeroReasons(cycleState))
+			}
+		})
+	}
+}
+
+func TestScoreWithSafetyBuffer(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i, cores := range []int64{10, 100} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewQuantity(cores, resource.DecimalSI),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(cores/2, resource.DecimalSI),
+							corev1.ResourceMemory: resource.MustParse("0"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                     string
+		scoreSafetyBufferPercent int64
+		wantScores               []int64
+	}{
+		{
+			name:       "the pod fits tightly on the small node",
+			wantScores: []int64{92, 54},
+		},
+		{
+			name:                     "the buffer exceeds the small node and prefers the large node",
+			scoreSafetyBufferPercent: 20,
+			wantScores:               []int64{0, 55},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ResourceWeights: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 1,
+				},
+				ScoringStrategy:          v1beta2.LoadAwareScoringStrategyBestFit,
+				ScoreSafetyBufferPercent: tt.scoreSafetyBufferPercent,
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse("5"),
+								},
+							},
+						},
+					},
+				},
+			}
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestScoreWithPodDensity(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i := 0; i < 2; i++ {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+					corev1.ResourcePods:   resource.MustParse("100"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("50"),
+							corev1.ResourceMemory: resource.MustParse("50Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name             string
+		podDensityWeight int64
+		wantScores       []int64
+	}{
+		{
+			name:       "equal scores at the same resource load",
+			wantScores: []int64{49, 49},
+		},
+		{
+			name:             "node hosting more pods scores lower",
+			podDensityWeight: 50,
+			wantScores:       []int64{36, 56},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				PodDensityWeight: tt.podDensityWeight,
+			}, nodes, nodeMetrics, nil)
+			// the pods are running and reported in the node usage,
+			// and the first node hosts 75 pods while the second hosts 35 pods at the same load.
+			for i, podCount := range []int{75, 35} {
+				nodeInfo, err := snapshot.Get(nodes[i].Name)
+				assert.NoError(t, err)
+				for j := 0; j < podCount; j++ {
+					nodeInfo.AddPod(&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "default",
+							Name:      fmt.Sprintf("pod-%d-%d", i, j),
+						},
+					})
+				}
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestPodDensityScore(t *testing.T) {
+	tests := []struct {
+		name             string
+		pods             int
+		allowedPodNumber int
+		want             int64
+	}{
+		{name: "no allowed pods", pods: 0, allowedPodNumber: 0, want: 0},
+		{name: "full after placing the pod", pods: 9, allowedPodNumber: 10, want: 0},
+		{name: "free slots in proportion", pods: 4, allowedPodNumber: 10, want: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.Allocatable = &framework.Resource{AllowedPodNumber: tt.allowedPodNumber}
+			for i := 0; i < tt.pods; i++ {
+				nodeInfo.AddPod(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}})
+			}
+			assert.Equal(t, tt.want, podDensityScore(nodeInfo))
+		})
+	}
+}
+
+func TestScoreWithDefaultResourceWeight(t *testing.T) {
+	resourceFoo := corev1.ResourceName("example.com/foo")
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i := 0; i < 2; i++ {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+					resourceFoo:           resource.MustParse("10"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now().Add(-time.Minute),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("50"),
+							corev1.ResourceMemory: resource.MustParse("50Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                  string
+		defaultResourceWeight int64
+		wantScores            []int64
+	}{
+		{
+			name:       "unweighted resource is ignored by default",
+			wantScores: []int64{49, 49},
+		},
+		{
+			name:                  "node saturated in the unweighted resource scores lower",
+			defaultResourceWeight: 1,
+			wantScores:            []int64{33, 59},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				DefaultResourceWeight: tt.defaultResourceWeight,
+			}, nodes, nodeMetrics, nil)
+			// the pod assigned to the first node after the NodeMetric updated requests most of the resource.
+			p.podAssignCache.assign(nodes[0].Name, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "assigned-pod",
+					UID:       "assigned-pod",
+				},
+				Spec: corev1.PodSpec{
+					NodeName: nodes[0].Name,
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									resourceFoo: resource.MustParse("8"),
+								},
+							},
+						},
+					},
+				},
+			})
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									resourceFoo: resource.MustParse("2"),
+								},
+							},
+						},
+					},
+				},
+			}
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestScoreWithMemoryPressureKnee(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	// the first node has the CPU headroom but is near OOM, and the second node is moderately used.
+	for i, usage := range []corev1.ResourceList{
+		{
+			corev1.ResourceCPU:    resource.MustParse("10"),
+			corev1.ResourceMemory: resource.MustParse("90Gi"),
+		},
+		{
+			corev1.ResourceCPU:    resource.MustParse("60"),
+			corev1.ResourceMemory: resource.MustParse("60Gi"),
+		},
+	} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: usage,
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name               string
+		memoryPressureKnee int64
+		wantScores         []int64
+	}{
+		{
+			name:       "node near OOM is preferred by the CPU headroom by default",
+			wantScores: []int64{49, 39},
+		},
+		{
+			name:               "node near OOM is avoided above the memory pressure knee",
+			memoryPressureKnee: 80,
+			wantScores:         []int64{22, 39},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				MemoryPressureKnee: tt.memoryPressureKnee,
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestEffectiveResourceWeight(t *testing.T) {
+	tests := []struct {
+		name              string
+		resourceName      corev1.ResourceName
+		knee              int64
+		memoryUtilization int64
+		want              int64
+	}{
+		{name: "memory pressure knee disabled", resourceName: corev1.ResourceMemory, knee: 0, memoryUtilization: 95, want: 2},
+		{name: "cpu weight is not affected", resourceName: corev1.ResourceCPU, knee: 80, memoryUtilization: 95, want: 2},
+		{name: "memory utilization at the knee", resourceName: corev1.ResourceMemory, knee: 80, memoryUtilization: 80, want: 2},
+		{name: "memory utilization half above the knee", resourceName: corev1.ResourceMemory, knee: 80, memoryUtilization: 90, want: 11},
+		{name: "memory fully utilized", resourceName: corev1.ResourceMemory, knee: 80, memoryUtilization: 100, want: 20},
+		{name: "memory overused", resourceName: corev1.ResourceMemory, knee: 80, memoryUtilization: 120, want: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &config.LoadAwareSchedulingArgs{MemoryPressureKnee: tt.knee}
+			detail := &nodeScoreDetail{memoryUtilization: tt.memoryUtilization}
+			assert.Equal(t, tt.want, effectiveResourceWeight(args, tt.resourceName, 2, detail))
+		})
+	}
+}
+
+func TestScoreWithPreferNominatedNode(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	// the second node where the preemption nominates the pod is more loaded than the first node.
+	for i, usage := range []string{"10", "60"} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(usage),
+							corev1.ResourceMemory: resource.MustParse(usage + "Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                string
+		preferNominatedNode *bool
+		nominatedNodeName   string
+		wantScores          []int64
+		wantReasons         map[string]Reason
+	}{
+		{
+			name:              "nominated node is scored by load by default",
+			nominatedNodeName: "test-node-2",
+			wantScores:        []int64{89, 39},
+			wantReasons:       map[string]Reason{},
+		},
+		{
+			name:                "pod without nominated node is scored by load",
+			preferNominatedNode: pointer.Bool(true),
+			wantScores:          []int64{89, 39},
+			wantReasons:         map[string]Reason{},
+		},
+		{
+			name:                "nominated node scores highest",
+			preferNominatedNode: pointer.Bool(true),
+			nominatedNodeName:   "test-node-2",
+			wantScores:          []int64{0, framework.MaxNodeScore},
+			wantReasons: map[string]Reason{
+				"test-node-1": {Code: ReasonCodeNotNominatedNode},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				PreferNominatedNode: tt.preferNominatedNode,
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Status: corev1.PodStatus{
+					NominatedNodeName: tt.nominatedNodeName,
+				},
+			}
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, nodes).IsSuccess())
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+			assert.Equal(t, tt.wantReasons, GetScoreZeroReasons(cycleState))
+		})
+	}
+}
+
+func TestScoreBatchPodByBatchResources(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+					extension.BatchCPU:    resource.MustParse("20000"),
+					extension.BatchMemory: resource.MustParse("20Gi"),
+				},
+			},
+		},
+	}
+	batchPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(extension.PriorityBatchValueMin),
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							extension.BatchCPU:    resource.MustParse("4000"),
+							extension.BatchMemory: resource.MustParse("4Gi"),
+						},
+						Requests: corev1.ResourceList{
+							extension.BatchCPU:    resource.MustParse("4000"),
+							extension.BatchMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	prodPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(extension.PriorityProdValueMax),
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                          string
+		scoreBatchPodByBatchResources *bool
+		pod                           *corev1.Pod
+		nodeUsage                     corev1.ResourceList
+		wantScore                     int64
+	}{
+		{
+			name:      "batch pod is scored against the node allocatable by default",
+			pod:       batchPod,
+			nodeUsage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("60"), corev1.ResourceMemory: resource.MustParse("60Gi")},
+			wantScore: 37,
+		},
+		{
+			name:                          "batch pod is scored against the batch usage and allocatable",
+			scoreBatchPodByBatchResources: pointer.Bool(true),
+			pod:                           batchPod,
+			nodeUsage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("60"),
+				corev1.ResourceMemory: resource.MustParse("60Gi"),
+				extension.BatchCPU:    resource.MustParse("8000"),
+				extension.BatchMemory: resource.MustParse("8Gi"),
+			},
+			wantScore: 45,
+		},
+		{
+			name:                          "batch pod falls back to the node usage if the batch usage is not reported",
+			scoreBatchPodByBatchResources: pointer.Bool(true),
+			pod:                           batchPod,
+			nodeUsage:                     corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10"), corev1.ResourceMemory: resource.MustParse("10Gi")},
+			wantScore:                     35,
+		},
+		{
+			name:                          "prod pod is scored against the node allocatable",
+			scoreBatchPodByBatchResources: pointer.Bool(true),
+			pod:                           prodPod,
+			nodeUsage:                     corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("60"), corev1.ResourceMemory: resource.MustParse("60Gi")},
+			wantScore:                     37,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeMetrics := []*slov1alpha1.NodeMetric{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node-1",
+					},
+					Status: slov1alpha1.NodeMetricStatus{
+						UpdateTime: &metav1.Time{
+							Time: time.Now(),
+						},
+						NodeMetric: &slov1alpha1.NodeMetricInfo{
+							NodeUsage: slov1alpha1.ResourceMap{
+								ResourceList: tt.nodeUsage,
+							},
+						},
+					},
+				},
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreBatchPodByBatchResources: tt.scoreBatchPodByBatchResources,
+			}, nodes, nodeMetrics, nil)
+			score, status := p.Score(context.TODO(), framework.NewCycleState(), tt.pod, "test-node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestScoreAccordingPriorityClassAllocatable(t *testing.T) {
+	newNode := func(name string, allocatable corev1.ResourceList) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: allocatable,
+			},
+		}
+	}
+	nodes := []*corev1.Node{
+		newNode("test-node-pools", corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100"),
+			corev1.ResourceMemory: resource.MustParse("100Gi"),
+			extension.BatchCPU:    resource.MustParse("50000"),
+			extension.BatchMemory: resource.MustParse("50Gi"),
+		}),
+		newNode("test-node-no-pools", corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100"),
+			corev1.ResourceMemory: resource.MustParse("100Gi"),
+		}),
+	}
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, node := range nodes {
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: node.Name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	batchPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-batch-pod",
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(extension.PriorityBatchValueMin),
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							extension.BatchCPU:    resource.MustParse("4000"),
+							extension.BatchMemory: resource.MustParse("4Gi"),
+						},
+						Requests: corev1.ResourceList{
+							extension.BatchCPU:    resource.MustParse("4000"),
+							extension.BatchMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	prodPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-prod-pod",
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(extension.PriorityProdValueMax),
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                                   string
+		scoreAccordingPriorityClassAllocatable *bool
+		pod                                    *corev1.Pod
+		wantScores                             []int64
+	}{
+		{
+			name:       "batch pod is scored against the node allocatable by default",
+			pod:        batchPod,
+			wantScores: []int64{87, 87},
+		},
+		{
+			name:                                   "batch pod is scored against the batch allocatable pool",
+			scoreAccordingPriorityClassAllocatable: pointer.Bool(true),
+			pod:                                    batchPod,
+			wantScores:                             []int64{74, 87},
+		},
+		{
+			name:                                   "prod pod is scored against the node allocatable",
+			scoreAccordingPriorityClassAllocatable: pointer.Bool(true),
+			pod:                                    prodPod,
+			wantScores:                             []int64{87, 87},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreAccordingPriorityClassAllocatable: tt.scoreAccordingPriorityClassAllocatable,
+			}, nodes, nodeMetrics, nil)
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), tt.pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestScoreAccordingAvailable(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i := 0; i < 2; i++ {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("20"),
+							corev1.ResourceMemory: resource.MustParse("20Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                    string
+		scoreAccordingAvailable *bool
+		wantScores              []int64
+	}{
+		{
+			name:       "equal scores at the same load by default",
+			wantScores: []int64{77, 77},
+		},
+		{
+			name:                    "node with more committed requests scores lower",
+			scoreAccordingAvailable: pointer.Bool(true),
+			wantScores:              []int64{42, 77},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreAccordingAvailable: tt.scoreAccordingAvailable,
+			}, nodes, nodeMetrics, nil)
+			// the first node has 60% of the allocatable committed to the requests of a running pod.
+			nodeInfo, err := snapshot.Get(nodes[0].Name)
+			assert.NoError(t, err)
+			nodeInfo.AddPod(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "committed-pod",
+				},
+				Spec: corev1.PodSpec{
+					NodeName: nodes[0].Name,
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("60"),
+									corev1.ResourceMemory: resource.MustParse("60Gi"),
+								},
+							},
+						},
+					},
+				},
+			})
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("4"),
+									corev1.ResourceMemory: resource.MustParse("4Gi"),
+								},
+							},
+						},
+					},
+				},
+			}
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestLoadAwareSchedulingScorerRounding(t *testing.T) {
+	tests := []struct {
+		name            string
+		resourceWeights map[corev1.ResourceName]int64
+		estimatedUsed   map[corev1.ResourceName]int64
+		wantScore       int64
+	}{
+		{
+			name: "three resources with equal weights",
+			resourceWeights: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              1,
+				corev1.ResourceMemory:           1,
+				corev1.ResourceEphemeralStorage: 1,
+			},
+			estimatedUsed: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              10,
+				corev1.ResourceMemory:           20,
+				corev1.ResourceEphemeralStorage: 55,
+			},
+			// (90 + 80 + 45) / 3 = 71.67
+			wantScore: 72,
+		},
+		{
+			name: "three resources with a doubled weight",
+			resourceWeights: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              1,
+				corev1.ResourceMemory:           1,
+				corev1.ResourceEphemeralStorage: 2,
+			},
+			estimatedUsed: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              10,
+				corev1.ResourceMemory:           20,
+				corev1.ResourceEphemeralStorage: 55,
+			},
+			// (90 + 80 + 45*2) / 4 = 65
+			wantScore: 65,
+		},
+		{
+			name: "four resources with different weights",
+			resourceWeights: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              1,
+				corev1.ResourceMemory:           2,
+				corev1.ResourceEphemeralStorage: 1,
+				corev1.ResourcePods:             3,
+			},
+			estimatedUsed: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              33,
+				corev1.ResourceMemory:           10,
+				corev1.ResourceEphemeralStorage: 55,
+				corev1.ResourcePods:             7,
+			},
+			// (67 + 90*2 + 45 + 93*3) / 7 = 81.57
+			wantScore: 82,
+		},
+		{
+			name: "four resources rounding down",
+			resourceWeights: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              1,
+				corev1.ResourceMemory:           1,
+				corev1.ResourceEphemeralStorage: 1,
+				corev1.ResourcePods:             1,
+			},
+			estimatedUsed: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:              10,
+				corev1.ResourceMemory:           20,
+				corev1.ResourceEphemeralStorage: 55,
+				corev1.ResourcePods:             7,
+			},
+			// (90 + 80 + 45 + 93) / 4 = 77
+			wantScore: 77,
+		},
+		{
+			name:            "no weighted resources",
+			resourceWeights: map[corev1.ResourceName]int64{},
+			estimatedUsed:   map[corev1.ResourceName]int64{},
+			wantScore:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &config.LoadAwareSchedulingArgs{ResourceWeights: tt.resourceWeights}
+			detail := &nodeScoreDetail{
+				podEstimatedUsed: map[corev1.ResourceName]int64{},
+				estimatedUsed:    tt.estimatedUsed,
+				allocatable: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU:              100,
+					corev1.ResourceMemory:           100,
+					corev1.ResourceEphemeralStorage: 100,
+					corev1.ResourcePods:             110,
+				},
+			}
+			assert.Equal(t, tt.wantScore, loadAwareSchedulingScorer(args, detail))
+		})
+	}
+}
+
+func TestFilterAndScoreUsageType(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		},
+	}
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "prod-pod",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node-1",
+				Priority: pointer.Int32(extension.PriorityProdValueMax),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "batch-pod",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node-1",
+				Priority: pointer.Int32(extension.PriorityBatchValueMax),
+			},
+		},
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Spec: slov1alpha1.NodeMetricSpec{
+				CollectPolicy: &slov1alpha1.NodeMetricCollectPolicy{
+					ReportIntervalSeconds: pointer.Int64(60),
+				},
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("70"),
+							corev1.ResourceMemory: resource.MustParse("40Gi"),
+						},
+					},
+				},
+				PodsMetric: []*slov1alpha1.PodMetricInfo{
+					{
+						Namespace: "default",
+						Name:      "prod-pod",
+						PodUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("20"),
+								corev1.ResourceMemory: resource.MustParse("10Gi"),
+							},
+						},
+					},
+					{
+						Namespace: "default",
+						Name:      "batch-pod",
+						PodUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("50"),
+								corev1.ResourceMemory: resource.MustParse("30Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name            string
+		filterUsageType v1beta2.LoadAwareUsageType
+		scoreUsageType  v1beta2.LoadAwareUsageType
+		wantFilter      *framework.Status
+		wantScore       int64
+	}{
+		{
+			name:       "filter by prod usage and score by total usage by default",
+			wantFilter: nil,
+			wantScore:  44,
+		},
+		{
+			name:            "filter by total usage",
+			filterUsageType: v1beta2.LoadAwareUsageTypeTotal,
+			wantFilter:      framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU)),
+			wantScore:       44,
+		},
+		{
+			name:            "filter by prod usage and score by total usage",
+			filterUsageType: v1beta2.LoadAwareUsageTypeProd,
+			scoreUsageType:  v1beta2.LoadAwareUsageTypeTotal,
+			wantFilter:      nil,
+			wantScore:       44,
+		},
+		{
+			name:            "filter and score by prod usage",
+			filterUsageType: v1beta2.LoadAwareUsageTypeProd,
+			scoreUsageType:  v1beta2.LoadAwareUsageTypeProd,
+			wantFilter:      nil,
+			wantScore:       84,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ProdUsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 50,
+				},
+				FilterUsageType: tt.filterUsageType,
+				ScoreUsageType:  tt.scoreUsageType,
+			}, nodes, nodeMetrics, pods)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+				Spec: corev1.PodSpec{
+					Priority: pointer.Int32(extension.PriorityProdValueMax),
+				},
+			}
+			nodeInfo, err := snapshot.Get("test-node-1")
+			assert.NoError(t, err)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), pod, nodeInfo)
+			assert.True(t, tt.wantFilter.Equal(status), "want status: %s, but got %s", tt.wantFilter.Message(), status.Message())
+
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, nodes).IsSuccess())
+			score, status := p.Score(context.TODO(), cycleState, pod, "test-node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestValidateUsageType(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     v1beta2.LoadAwareSchedulingArgs
+		wantErrs []string
+	}{
+		{
+			name: "mixed usage types",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				FilterUsageType: v1beta2.LoadAwareUsageTypeProd,
+				ScoreUsageType:  v1beta2.LoadAwareUsageTypeTotal,
+			},
+		},
+		{
+			name: "unsupported usage types",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				FilterUsageType: "Batch",
+				ScoreUsageType:  "Batch",
+			},
+			wantErrs: []string{"filterUsageType", "scoreUsageType"},
+		},
+		{
+			name: "score by total usage conflicts with scoreAccordingProdUsage",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				ScoreUsageType:          v1beta2.LoadAwareUsageTypeTotal,
+				ScoreAccordingProdUsage: pointer.Bool(true),
+			},
+			wantErrs: []string{"scoreUsageType Total conflicts with scoreAccordingProdUsage"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&tt.args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&tt.args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+			if len(tt.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, wantErr := range tt.wantErrs {
+				assert.Contains(t, err.Error(), wantErr)
+			}
+		})
+	}
+}
+
+func TestScoreResourceBase(t *testing.T) {
+	// the node reserves 10% for the kube-reserved and system-reserved resources,
+	// and the system processes out of the pods use 10 CPUs and 10Gi memory.
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("90"),
+					corev1.ResourceMemory: resource.MustParse("90Gi"),
+				},
+			},
+		},
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("30"),
+							corev1.ResourceMemory: resource.MustParse("40Gi"),
+						},
+					},
+				},
+				PodsMetric: []*slov1alpha1.PodMetricInfo{
+					{
+						Namespace: "default",
+						Name:      "running-pod",
+						PodUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("20"),
+								corev1.ResourceMemory: resource.MustParse("30Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name              string
+		scoreResourceBase v1beta2.LoadAwareResourceBase
+		wantScore         int64
+	}{
+		{
+			name:      "node usage against allocatable by default",
+			wantScore: 57,
+		},
+		{
+			name:              "usage with the system usage excluded against allocatable",
+			scoreResourceBase: v1beta2.LoadAwareResourceBaseAllocatable,
+			wantScore:         69,
+		},
+		{
+			name:              "node usage with the system usage counted against capacity",
+			scoreResourceBase: v1beta2.LoadAwareResourceBaseCapacity,
+			wantScore:         62,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreResourceBase: tt.scoreResourceBase,
+			}, nodes, nodeMetrics, nil)
+			score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, "test-node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestValidateScoreResourceBase(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     v1beta2.LoadAwareSchedulingArgs
+		wantErrs []string
+	}{
+		{
+			name: "score against capacity",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				ScoreResourceBase: v1beta2.LoadAwareResourceBaseCapacity,
+			},
+		},
+		{
+			name: "unsupported resource base",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				ScoreResourceBase: "Requested",
+			},
+			wantErrs: []string{"scoreResourceBase"},
+		},
+		{
+			name: "score against capacity conflicts with scoreAccordingNodeReservation",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				ScoreResourceBase:             v1beta2.LoadAwareResourceBaseCapacity,
+				ScoreAccordingNodeReservation: pointer.Bool(true),
+			},
+			wantErrs: []string{"scoreResourceBase Capacity conflicts with scoreAccordingNodeReservation"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&tt.args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&tt.args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+			if len(tt.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, wantErr := range tt.wantErrs {
+				assert.Contains(t, err.Error(), wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSidecarOverheads(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     v1beta2.LoadAwareSchedulingArgs
+		wantErrs []string
+	}{
+		{
+			name: "valid sidecar overheads",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				SidecarOverheads: []v1beta2.LoadAwareSidecarOverhead{
+					{
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"istio-injection": "enabled"},
+						},
+						ContainerName: "istio-proxy",
+						Overhead: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("500m"),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid sidecar overheads",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				SidecarOverheads: []v1beta2.LoadAwareSidecarOverhead{
+					{
+						Overhead: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("-500m"),
+						},
+					},
+					{
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: "istio-injection", Operator: "Unknown"},
+							},
+						},
+					},
+				},
+			},
+			wantErrs: []string{
+				"sidecarOverheads[0].namespaceSelector",
+				"sidecarOverheads[0].overhead[cpu]",
+				"sidecarOverheads[1].namespaceSelector",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&tt.args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&tt.args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+			if len(tt.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, wantErr := range tt.wantErrs {
+				assert.Contains(t, err.Error(), wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateThresholdRelaxation(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     v1beta2.LoadAwareSchedulingArgs
+		wantErrs []string
+	}{
+		{
+			name: "bounded relaxation",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				ThresholdRelaxationPercentPerAttempt: 5,
+				MaxThresholdRelaxationPercent:        20,
+			},
+		},
+		{
+			name: "relaxation without the bound",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				ThresholdRelaxationPercentPerAttempt: 5,
+			},
+			wantErrs: []string{"maxThresholdRelaxationPercent is required when thresholdRelaxationPercentPerAttempt is set"},
+		},
+		{
+			name: "relaxation out of range",
+			args: v1beta2.LoadAwareSchedulingArgs{
+				ThresholdRelaxationPercentPerAttempt: -5,
+				MaxThresholdRelaxationPercent:        120,
+			},
+			wantErrs: []string{"thresholdRelaxationPercentPerAttempt", "maxThresholdRelaxationPercent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&tt.args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&tt.args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+			err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+			if len(tt.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, wantErr := range tt.wantErrs {
+				assert.Contains(t, err.Error(), wantErr)
+			}
+		})
+	}
+}
+
+func TestScoreWithCompressibility(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i := 0; i < 2; i++ {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("50"),
+							corev1.ResourceMemory: resource.MustParse("50Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                  string
+		compressibilityWeight int64
+		wantScores            []int64
+	}{
+		{
+			name:       "equal scores at the same resource load",
+			wantScores: []int64{49, 49},
+		},
+		{
+			name:                  "node hosting burstable pods scores higher",
+			compressibilityWeight: 20,
+			wantScores:            []int64{49, 64},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				CompressibilityWeight: tt.compressibilityWeight,
+			}, nodes, nodeMetrics, nil)
+			// the first node hosts the Guaranteed pods while the second hosts the Burstable pods
+			// limited to 4 times of the requests at the same load.
+			for i, cpuLimit := range []string{"4", "16"} {
+				nodeInfo, err := snapshot.Get(nodes[i].Name)
+				assert.NoError(t, err)
+				for j := 0; j < 4; j++ {
+					nodeInfo.AddPod(&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "default",
+							Name:      fmt.Sprintf("pod-%d-%d", i, j),
+						},
+						Spec: corev1.PodSpec{
+							NodeName: nodes[i].Name,
+							Containers: []corev1.Container{
+								{
+									Name: "main",
+									Resources: corev1.ResourceRequirements{
+										Requests: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("4"),
+											corev1.ResourceMemory: resource.MustParse("4Gi"),
+										},
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse(cpuLimit),
+											corev1.ResourceMemory: resource.MustParse("4Gi"),
+										},
+									},
+								},
+							},
+						},
+					})
+				}
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			var scores []int64
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				scores = append(scores, score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
+
+func TestCompressibilityScore(t *testing.T) {
+	newPod := func(cpuRequest, cpuLimit string) *corev1.Pod {
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "main",
+					},
+				},
+			},
+		}
+		if cpuRequest != "" {
+			pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpuRequest)}
+		}
+		if cpuLimit != "" {
+			pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpuLimit)}
+		}
+		return pod
+	}
+	tests := []struct {
+		name string
+		pods []*corev1.Pod
+		want int64
+	}{
+		{
+			name: "no pods",
+			want: 0,
+		},
+		{
+			name: "guaranteed pods",
+			pods: []*corev1.Pod{newPod("4", "4"), newPod("2", "2")},
+			want: 0,
+		},
+		{
+			name: "pods without limits are fully compressible",
+			pods: []*corev1.Pod{newPod("4", ""), newPod("", "")},
+			want: 100,
+		},
+		{
+			name: "mixed pods",
+			pods: []*corev1.Pod{newPod("4", "4"), newPod("1", "4"), newPod("2", "")},
+			want: 58,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo(tt.pods...)
+			assert.Equal(t, tt.want, compressibilityScore(nodeInfo, 100))
+		})
+	}
+}
+
+func TestExemptNode(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, name := range []string{"test-node-overloaded", "test-node-exempt"} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("90"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	nodes[1].Labels = map[string]string{"example.com/load-aware-exempt": "true"}
+
+	tests := []struct {
+		name               string
+		exemptNodeLabelKey string
+		wantStatuses       map[string]*framework.Status
+		wantScores         map[string]int64
+	}{
+		{
+			name: "exempt label is ignored without the key",
+			wantStatuses: map[string]*framework.Status{
+				"test-node-overloaded": newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+				"test-node-exempt":     newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+			},
+			wantScores: map[string]int64{"test-node-overloaded": 52, "test-node-exempt": 52},
+		},
+		{
+			name:               "exempt node bypasses the load",
+			exemptNodeLabelKey: "example.com/load-aware-exempt",
+			wantStatuses: map[string]*framework.Status{
+				"test-node-overloaded": newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+				"test-node-exempt":     nil,
+			},
+			wantScores: map[string]int64{"test-node-overloaded": 52, "test-node-exempt": framework.MaxNodeScore / 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ExemptNodeLabelKey:     tt.exemptNodeLabelKey,
+				ReserveCheckThresholds: pointer.Bool(true),
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+					UID:       "test-pod",
+				},
+			}
+			for _, node := range nodes {
+				nodeInfo := framework.NewNodeInfo()
+				nodeInfo.SetNode(node)
+				status := p.Filter(context.TODO(), framework.NewCycleState(), pod, nodeInfo)
+				assert.Equal(t, tt.wantStatuses[node.Name], status, node.Name)
+
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, tt.wantScores[node.Name], score, node.Name)
+			}
+			if tt.exemptNodeLabelKey != "" {
+				assert.True(t, p.Reserve(context.TODO(), framework.NewCycleState(), pod, "test-node-exempt").IsSuccess())
+				assert.False(t, p.Reserve(context.TODO(), framework.NewCycleState(), pod, "test-node-overloaded").IsSuccess())
+			}
+		})
+	}
+}
+
+func TestValidateExemptNodeLabelKey(t *testing.T) {
+	v1beta2args := &v1beta2.LoadAwareSchedulingArgs{
+		ExemptNodeLabelKey: "invalid key",
+	}
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+	err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exemptNodeLabelKey")
+}
+
+func TestEstimatedAssignedPodUsedWithFutureUpdateTime(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod-1",
+			UID:       "123456789",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	// the clock of the node is 5 minutes ahead, and the NodeMetric reported just now includes the pod.
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now().Add(5 * time.Minute),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{},
+		},
+	}
+	podMetrics := map[string]corev1.ResourceList{
+		"default/test-pod-1": {
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nil, nil, nil)
+	preTimeNowFn := timeNowFn
+	defer func() {
+		timeNowFn = preTimeNowFn
+	}()
+	timeNowFn = func() time.Time {
+		return time.Now().Add(-10 * time.Second)
+	}
+	p.podAssignCache.assign(pod.Spec.NodeName, pod)
+
+	// the pod assigned within the report interval before the update treated as now is still estimated,
+	// rather than regarded as assigned long before the update in the future.
+	estimatedUsed, estimatedPods := p.estimatedAssignedPodUsed(p.getArgs(), pod.Spec.NodeName, nodeMetric, podMetrics, false)
+	assert.Equal(t, int64(3400), estimatedUsed[corev1.ResourceCPU])
+	assert.True(t, estimatedPods.Has("default/test-pod-1"))
+	assert.False(t, isNodeMetricExpired(nodeMetric, 180))
+}
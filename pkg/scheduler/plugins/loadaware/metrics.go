@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+const (
+	// LoadAwareSchedulingSubsystem - subsystem name used by LoadAwareScheduling plugin
+	LoadAwareSchedulingSubsystem = "loadaware"
+)
+
+// The phases timed by PhaseDuration.
+const (
+	phaseFilter                  = "filter"
+	phaseScore                   = "score"
+	phaseEstimateAssignedPodUsed = "estimate_assigned_pod_used"
+)
+
+var (
+	CustomThresholdParseErrors = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      LoadAwareSchedulingSubsystem,
+			Name:           "custom_threshold_parse_errors_total",
+			Help:           "Number of errors parsing the custom usage thresholds annotation of nodes, counted once per change of the annotation",
+			StabilityLevel: metrics.ALPHA,
+		})
+
+	NodeMetricAge = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      LoadAwareSchedulingSubsystem,
+			Name:           "nodemetric_age_seconds",
+			Help:           "Seconds since the last update of the NodeMetric read by Filter or Score, by the node name",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"node"})
+
+	FilterRejections = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      LoadAwareSchedulingSubsystem,
+			Name:           "filter_rejections_total",
+			Help:           "Number of nodes rejected by Filter, by the reason code",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"reason"})
+
+	PhaseDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      LoadAwareSchedulingSubsystem,
+			Name:           "phase_duration_seconds",
+			Help:           "Duration in seconds of each invocation of the phases of LoadAwareScheduling, by the phase",
+			Buckets:        metrics.ExponentialBuckets(0.00001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"phase"})
+
+	metricsList = []metrics.Registerable{
+		CustomThresholdParseErrors,
+		NodeMetricAge,
+		FilterRejections,
+		PhaseDuration,
+	}
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the metrics of LoadAwareScheduling plugin.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		for _, metric := range metricsList {
+			legacyregistry.MustRegister(metric)
+		}
+	})
+}
+
+// registerCustomThresholdParseErrorsEventHandler counts the malformed custom usage thresholds annotations
+// when the nodes are added or the annotation is changed, rather than every time the annotation is read.
+func registerCustomThresholdParseErrorsEventHandler(sharedInformerFactory informers.SharedInformerFactory) {
+	nodeInformer := sharedInformerFactory.Core().V1().Nodes().Informer()
+	nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if node, ok := obj.(*corev1.Node); ok {
+				countCustomThresholdParseError(node)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, oldOK := oldObj.(*corev1.Node)
+			newNode, newOK := newObj.(*corev1.Node)
+			if !oldOK || !newOK {
+				return
+			}
+			if oldNode.Annotations[extension.AnnotationCustomUsageThresholds] != newNode.Annotations[extension.AnnotationCustomUsageThresholds] {
+				countCustomThresholdParseError(newNode)
+			}
+		},
+	})
+}
+
+func countCustomThresholdParseError(node *corev1.Node) {
+	if _, err := extension.GetCustomUsageThresholds(node); err != nil {
+		klog.ErrorS(err, "Failed to parse the custom usage thresholds of node", "node", node.Name)
+		CustomThresholdParseErrors.Inc()
+	}
+}
+
+// recordNodeMetricAge records the age of the NodeMetric read by the plugin,
+// so only the nodes being scheduled against are recorded.
+func recordNodeMetricAge(nodeMetric *slov1alpha1.NodeMetric) {
+	if nodeMetric.Status.UpdateTime == nil {
+		return
+	}
+	NodeMetricAge.WithLabelValues(nodeMetric.Name).Set(time.Since(getNodeMetricUpdateTime(nodeMetric)).Seconds())
+}
+
+// recordPhaseDuration records the duration of the phase since the start time.
+func recordPhaseDuration(phase string, start time.Time) {
+	PhaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+const (
+	// metricsServerNodeMetricsPath is the path of the NodeMetrics served by metrics-server.
+	metricsServerNodeMetricsPath = "/apis/metrics.k8s.io/v1beta1/nodes"
+	// metricsServerSyncInterval is the interval between listing the NodeMetrics from metrics-server.
+	metricsServerSyncInterval = 15 * time.Second
+	metricsServerTimeout      = 5 * time.Second
+)
+
+// NodeMetricProvider provides the NodeMetrics of the nodes not reported by koordlet.
+type NodeMetricProvider interface {
+	// GetNodeMetric returns the NodeMetric of the node, or the NotFound error if the node has no metrics.
+	GetNodeMetric(nodeName string) (*slov1alpha1.NodeMetric, error)
+}
+
+// getNodeMetric returns the NodeMetric of the node. If FallbackToMetricsServer is enabled,
+// the nodes without NodeMetric fall back to the NodeMetric built from metrics-server.
+func (p *Plugin) getNodeMetric(args *config.LoadAwareSchedulingArgs, nodeName string) (*slov1alpha1.NodeMetric, error) {
+	nodeMetric, err := p.getReportedNodeMetric(args, nodeName)
+	if apierrors.IsNotFound(err) && args.FallbackToMetricsServer && p.fallbackNodeMetricProvider != nil {
+		return p.fallbackNodeMetricProvider.GetNodeMetric(nodeName)
+	}
+	return nodeMetric, err
+}
+
+// getNodeMetricWithTimeout returns the NodeMetric of the node like getNodeMetric if NodeMetricTimeout is not exceeded.
+// Both the NodeMetric lister and the fallback provider read the caches synced in background, so the reading is done
+// in place, and context.DeadlineExceeded is returned if the context is done before the reading or the reading
+// takes longer than NodeMetricTimeout, e.g. under lock contention.
+func (p *Plugin) getNodeMetricWithTimeout(ctx context.Context, args *config.LoadAwareSchedulingArgs, nodeName string) (*slov1alpha1.NodeMetric, error) {
+	if args.NodeMetricTimeout.Duration <= 0 {
+		return p.getNodeMetric(args, nodeName)
+	}
+	if ctx.Err() != nil {
+		return nil, context.DeadlineExceeded
+	}
+	start := time.Now()
+	nodeMetric, err := p.getNodeMetric(args, nodeName)
+	if err == nil && time.Since(start) > args.NodeMetricTimeout.Duration {
+		return nil, context.DeadlineExceeded
+	}
+	return nodeMetric, err
+}
+
+// metricsServerNodeMetrics is the subset of the NodeMetrics in metrics.k8s.io/v1beta1 used by the plugin.
+type metricsServerNodeMetrics struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Timestamp         metav1.Time         `json:"timestamp"`
+	Window            metav1.Duration     `json:"window"`
+	Usage             corev1.ResourceList `json:"usage"`
+}
+
+type metricsServerNodeMetricsList struct {
+	Items []metricsServerNodeMetrics `json:"items"`
+}
+
+// metricsServerProvider provides the NodeMetrics built from the node usage of metrics-server.
+// The NodeMetrics are listed once per metricsServerSyncInterval in background, so that getting them in
+// the scheduling cycle never waits for metrics-server. The stale ones are kept if metrics-server fails,
+// which are filtered out by their UpdateTime as the expired NodeMetrics.
+type metricsServerProvider struct {
+	client      rest.Interface
+	lock        sync.RWMutex
+	nodeMetrics map[string]*slov1alpha1.NodeMetric
+}
+
+func newMetricsServerProvider(client rest.Interface) *metricsServerProvider {
+	return &metricsServerProvider{
+		client:      client,
+		nodeMetrics: map[string]*slov1alpha1.NodeMetric{},
+	}
+}
+
+// Run lists the NodeMetrics from metrics-server every metricsServerSyncInterval until stopCh is closed.
+func (m *metricsServerProvider) Run(stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := m.sync(); err != nil {
+			klog.V(4).InfoS("Failed to list NodeMetrics from metrics-server", "err", err)
+		}
+	}, metricsServerSyncInterval, stopCh)
+}
+
+func (m *metricsServerProvider) GetNodeMetric(nodeName string) (*slov1alpha1.NodeMetric, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	nodeMetric, ok := m.nodeMetrics[nodeName]
+	if !ok {
+		return nil, apierrors.NewNotFound(slov1alpha1.Resource("nodemetric"), nodeName)
+	}
+	return nodeMetric, nil
+}
+
+func (m *metricsServerProvider) sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsServerTimeout)
+	defer cancel()
+	data, err := m.client.Get().AbsPath(metricsServerNodeMetricsPath).Do(ctx).Raw()
+	if err != nil {
+		return err
+	}
+	list := &metricsServerNodeMetricsList{}
+	if err := json.Unmarshal(data, list); err != nil {
+		return err
+	}
+	nodeMetrics := make(map[string]*slov1alpha1.NodeMetric, len(list.Items))
+	for i := range list.Items {
+		nodeMetrics[list.Items[i].Name] = convertMetricsServerNodeMetrics(&list.Items[i])
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.nodeMetrics = nodeMetrics
+	return nil
+}
+
+// convertMetricsServerNodeMetrics converts the NodeMetrics of metrics-server to the NodeMetric
+// updated at the end of the metrics window and reported once per window.
+func convertMetricsServerNodeMetrics(metrics *metricsServerNodeMetrics) *slov1alpha1.NodeMetric {
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: metrics.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: metrics.Timestamp.DeepCopy(),
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: metrics.Usage.DeepCopy(),
+				},
+			},
+		},
+	}
+	if reportIntervalSeconds := int64(metrics.Window.Seconds()); reportIntervalSeconds > 0 {
+		nodeMetric.Spec.CollectPolicy = &slov1alpha1.NodeMetricCollectPolicy{
+			ReportIntervalSeconds: &reportIntervalSeconds,
+		}
+	}
+	return nodeMetric
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	restfake "k8s.io/client-go/rest/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+type fakeNodeMetricProvider map[string]*slov1alpha1.NodeMetric
+
+func (f fakeNodeMetricProvider) GetNodeMetric(nodeName string) (*slov1alpha1.NodeMetric, error) {
+	nodeMetric, ok := f[nodeName]
+	if !ok {
+		return nil, apierrors.NewNotFound(slov1alpha1.Resource("nodemetric"), nodeName)
+	}
+	return nodeMetric, nil
+}
+
+// slowNodeMetricProvider simulates the stalls of the metric source.
+type slowNodeMetricProvider struct {
+	NodeMetricProvider
+	delay time.Duration
+}
+
+func (s slowNodeMetricProvider) GetNodeMetric(nodeName string) (*slov1alpha1.NodeMetric, error) {
+	time.Sleep(s.delay)
+	return s.NodeMetricProvider.GetNodeMetric(nodeName)
+}
+
+func TestMetricsServerProvider(t *testing.T) {
+	var requests int
+	client := &restfake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			requests++
+			assert.Equal(t, metricsServerNodeMetricsPath, req.URL.Path)
+			body := `{"kind":"NodeMetricsList","apiVersion":"metrics.k8s.io/v1beta1","items":[` +
+				`{"metadata":{"name":"test-node-1"},"timestamp":"2022-10-01T00:00:00Z","window":"20s","usage":{"cpu":"30","memory":"40Gi"}}]}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+			}, nil
+		}),
+	}
+	provider := newMetricsServerProvider(client)
+
+	// GetNodeMetric never lists the NodeMetrics itself.
+	_, err := provider.GetNodeMetric("test-node-1")
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Equal(t, 0, requests)
+
+	assert.NoError(t, provider.sync())
+	nodeMetric, err := provider.GetNodeMetric("test-node-1")
+	assert.NoError(t, err)
+	expected := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Spec: slov1alpha1.NodeMetricSpec{
+			CollectPolicy: &slov1alpha1.NodeMetricCollectPolicy{
+				ReportIntervalSeconds: pointer.Int64(20),
+			},
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{Time: time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("30"),
+						corev1.ResourceMemory: resource.MustParse("40Gi"),
+					},
+				},
+			},
+		},
+	}
+	assert.True(t, expected.Status.UpdateTime.Equal(nodeMetric.Status.UpdateTime))
+	expected.Status.UpdateTime = nodeMetric.Status.UpdateTime
+	assert.Equal(t, expected, nodeMetric)
+
+	_, err = provider.GetNodeMetric("test-node-2")
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Equal(t, 1, requests, "the NodeMetrics should only be listed by sync")
+
+	// Run lists the NodeMetrics in background.
+	provider = newMetricsServerProvider(client)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go provider.Run(stopCh)
+	assert.Eventually(t, func() bool {
+		_, err := provider.GetNodeMetric("test-node-1")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestScoreWithFallbackToMetricsServer(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		},
+	}
+	provider := fakeNodeMetricProvider{
+		"test-node-1": convertMetricsServerNodeMetrics(&metricsServerNodeMetrics{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Timestamp: metav1.Now(),
+			Window:    metav1.Duration{Duration: 20 * time.Second},
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("30"),
+				corev1.ResourceMemory: resource.MustParse("40Gi"),
+			},
+		}),
+	}
+	tests := []struct {
+		name                    string
+		fallbackToMetricsServer *bool
+		wantScore               int64
+		wantReasons             map[string]Reason
+	}{
+		{
+			name:        "the node without NodeMetric is skipped by default",
+			wantScore:   0,
+			wantReasons: map[string]Reason{"test-node-1": {Code: ReasonCodeNodeMetricNotFound}},
+		},
+		{
+			name:                    "score by the node usage of metrics-server",
+			fallbackToMetricsServer: pointer.Bool(true),
+			wantScore:               64,
+			wantReasons:             map[string]Reason{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				FallbackToMetricsServer: tt.fallbackToMetricsServer,
+			}, nodes, nil, nil)
+			p.fallbackNodeMetricProvider = provider
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, nodes).IsSuccess())
+			score, status := p.Score(context.TODO(), cycleState, pod, "test-node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+			assert.Equal(t, tt.wantReasons, GetScoreZeroReasons(cycleState))
+		})
+	}
+}
+
+func TestScoreWithNodeMetricTimeout(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		},
+	}
+	provider := slowNodeMetricProvider{
+		NodeMetricProvider: fakeNodeMetricProvider{
+			"test-node-1": convertMetricsServerNodeMetrics(&metricsServerNodeMetrics{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Timestamp: metav1.Now(),
+				Window:    metav1.Duration{Duration: 20 * time.Second},
+				Usage: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("30"),
+					corev1.ResourceMemory: resource.MustParse("40Gi"),
+				},
+			}),
+		},
+		delay: 200 * time.Millisecond,
+	}
+	tests := []struct {
+		name              string
+		nodeMetricTimeout *metav1.Duration
+		wantScore         int64
+		wantReasons       map[string]Reason
+	}{
+		{
+			name:        "wait for the slow metric source by default",
+			wantScore:   64,
+			wantReasons: map[string]Reason{},
+		},
+		{
+			name:              "the metric source responds within the timeout",
+			nodeMetricTimeout: &metav1.Duration{Duration: 10 * time.Second},
+			wantScore:         64,
+			wantReasons:       map[string]Reason{},
+		},
+		{
+			name:              "skip the node if the metric source times out",
+			nodeMetricTimeout: &metav1.Duration{Duration: 10 * time.Millisecond},
+			wantScore:         0,
+			wantReasons:       map[string]Reason{"test-node-1": {Code: ReasonCodeNodeMetricTimeout}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				FallbackToMetricsServer: pointer.Bool(true),
+				NodeMetricTimeout:       tt.nodeMetricTimeout,
+			}, nodes, nil, nil)
+			p.fallbackNodeMetricProvider = provider
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, nodes).IsSuccess())
+			score, status := p.Score(context.TODO(), cycleState, pod, "test-node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+			assert.Equal(t, tt.wantReasons, GetScoreZeroReasons(cycleState))
+		})
+	}
+}
+
+func TestGetNodeMetricWithTimeoutDoneContext(t *testing.T) {
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		NodeMetricTimeout: &metav1.Duration{Duration: 10 * time.Second},
+	}, nil, []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+		},
+	}, nil)
+	nodeMetric, err := p.getNodeMetricWithTimeout(context.TODO(), p.getArgs().LoadAwareSchedulingArgs, "test-node-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-node-1", nodeMetric.Name)
+
+	// the NodeMetric is not read once the scheduling cycle is done.
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	nodeMetric, err = p.getNodeMetricWithTimeout(ctx, p.getArgs().LoadAwareSchedulingArgs, "test-node-1")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, nodeMetric)
+}
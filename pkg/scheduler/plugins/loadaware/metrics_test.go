@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestCustomThresholdParseErrorsMetric(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-malformed-thresholds",
+			Annotations: map[string]string{
+				extension.AnnotationCustomUsageThresholds: "{malformed",
+			},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("70"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+			},
+		},
+	}
+	parseErrors, err := testutil.GetCounterMetricValue(CustomThresholdParseErrors)
+	assert.NoError(t, err)
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+	// the malformed annotation of the added node is counted once.
+	assert.Eventually(t, func() bool {
+		value, err := testutil.GetCounterMetricValue(CustomThresholdParseErrors)
+		return err == nil && value == parseErrors+1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	rejections, err := testutil.GetCounterMetricValue(FilterRejections.WithLabelValues(string(ReasonCodeUsageExceedThreshold)))
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+		// fall back to the usage thresholds in args
+		assert.Equal(t, newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}), status)
+	}
+
+	// reading the annotation in Filter counts no errors.
+	value, err := testutil.GetCounterMetricValue(CustomThresholdParseErrors)
+	assert.NoError(t, err)
+	assert.Equal(t, parseErrors+1, value)
+	value, err = testutil.GetCounterMetricValue(FilterRejections.WithLabelValues(string(ReasonCodeUsageExceedThreshold)))
+	assert.NoError(t, err)
+	assert.Equal(t, rejections+2, value)
+
+	// the changed malformed annotation is counted again.
+	changedNode := node.DeepCopy()
+	changedNode.Annotations[extension.AnnotationCustomUsageThresholds] = "{still malformed"
+	_, err = p.handle.ClientSet().CoreV1().Nodes().Update(context.TODO(), changedNode, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		value, err := testutil.GetCounterMetricValue(CustomThresholdParseErrors)
+		return err == nil && value == parseErrors+2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNodeMetricAgeMetric(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-nodemetric-age",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now().Add(-30 * time.Second),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+			},
+		},
+	}
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+	assert.True(t, status.IsSuccess())
+	value, err := testutil.GetGaugeMetricValue(NodeMetricAge.WithLabelValues(node.Name))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, value, float64(30))
+	assert.Less(t, value, float64(60))
+
+	NodeMetricAge.Reset()
+	_, status = p.Score(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, node.Name)
+	assert.True(t, status.IsSuccess())
+	value, err = testutil.GetGaugeMetricValue(NodeMetricAge.WithLabelValues(node.Name))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, value, float64(30))
+	assert.Less(t, value, float64(60))
+}
+
+func TestPhaseDurationMetric(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-phase-duration",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+			},
+		},
+	}
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+	PhaseDuration.Reset()
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+	assert.True(t, status.IsSuccess())
+	_, status = p.Score(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, node.Name)
+	assert.True(t, status.IsSuccess())
+
+	for _, phase := range []string{phaseFilter, phaseScore, phaseEstimateAssignedPodUsed} {
+		count, err := testutil.GetHistogramMetricCount(PhaseDuration.WithLabelValues(phase))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), count, phase)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+)
+
+var (
+	nodeUsageThresholdRatioDesc = metrics.NewDesc(
+		metrics.BuildFQName("", LoadAwareSchedulingSubsystem, "node_usage_threshold_ratio"),
+		"Ratio of the node usage to the usage threshold of the weighted resources as Filter applies them, "+
+			"by the node name and the resource name. The node is filtered out if the ratio exceeds 1",
+		[]string{"node", "resource"}, nil, metrics.ALPHA, "")
+
+	clusterHeadroomDesc = metrics.NewDesc(
+		metrics.BuildFQName("", LoadAwareSchedulingSubsystem, "cluster_headroom"),
+		"Sum of the usage below the usage thresholds of the nodes, in milli-cores for CPU and in units for the others, "+
+			"by the resource name",
+		[]string{"resource"}, nil, metrics.ALPHA, "")
+
+	// NodeLoadCollector summarizes the load of the nodes from the viewpoint of the plugin created last on scrape.
+	NodeLoadCollector = &nodeLoadCollector{}
+
+	nodeLoadCollectorRegistered sync.Once
+)
+
+// nodeLoadCollector emits the node loads cached by the plugin rather than recording on scheduling,
+// so that all nodes are covered and the series of the deleted nodes are gone with them. Only the weighted
+// resources are collected to bound the cardinality to the number of nodes.
+type nodeLoadCollector struct {
+	metrics.BaseStableCollector
+
+	lock   sync.RWMutex
+	plugin *Plugin
+}
+
+// registerNodeLoadCollector registers NodeLoadCollector once and collects from the plugin.
+func registerNodeLoadCollector(p *Plugin) {
+	NodeLoadCollector.setPlugin(p)
+	nodeLoadCollectorRegistered.Do(func() {
+		legacyregistry.CustomMustRegister(NodeLoadCollector)
+	})
+}
+
+func (c *nodeLoadCollector) setPlugin(p *Plugin) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.plugin = p
+}
+
+func (c *nodeLoadCollector) getPlugin() *Plugin {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.plugin
+}
+
+func (c *nodeLoadCollector) DescribeWithStability(ch chan<- *metrics.Desc) {
+	ch <- nodeUsageThresholdRatioDesc
+	ch <- clusterHeadroomDesc
+}
+
+func (c *nodeLoadCollector) CollectWithStability(ch chan<- metrics.Metric) {
+	p := c.getPlugin()
+	if p == nil || p.nodeLoads == nil {
+		return
+	}
+	args := p.getArgs()
+	headroom := map[corev1.ResourceName]int64{}
+	p.nodeLoads.lock.RLock()
+	defer p.nodeLoads.lock.RUnlock()
+	for nodeName, load := range p.nodeLoads.nodes {
+		if args.NodeMetricExpirationSeconds != nil && *args.NodeMetricExpirationSeconds > 0 &&
+			time.Since(load.updateTime) >= time.Duration(*args.NodeMetricExpirationSeconds)*time.Second {
+			continue
+		}
+		for resourceName, usage := range load.resources {
+			ch <- metrics.NewLazyConstMetric(nodeUsageThresholdRatioDesc, metrics.GaugeValue,
+				float64(usage.used)/float64(usage.limit), nodeName, string(resourceName))
+			// the nodes over the thresholds have no headroom rather than the negative one.
+			used := usage.used
+			if used > usage.limit {
+				used = usage.limit
+			}
+			headroom[resourceName] += usage.limit - used
+		}
+	}
+	for resourceName, value := range headroom {
+		ch <- metrics.NewLazyConstMetric(clusterHeadroomDesc, metrics.GaugeValue, float64(value), string(resourceName))
+	}
+}
+
+// nodeLoads caches the loads of the nodes computed on the NodeMetric and Node events,
+// so that the scrape only reads the cached loads rather than computing them for all nodes.
+// The loads follow the args in effect since the next event of the node, e.g. the next report of the NodeMetric.
+type nodeLoads struct {
+	lock  sync.RWMutex
+	nodes map[string]*nodeLoad
+}
+
+// nodeLoad is the usage and the usage threshold of the weighted resources of a node.
+type nodeLoad struct {
+	// updateTime is the UpdateTime of the NodeMetric, which expires the load on scrape.
+	updateTime time.Time
+	resources  map[corev1.ResourceName]resourceLoad
+}
+
+// resourceLoad is the usage and the usage threshold in milli-cores for CPU and in units for the others.
+// The limit is always positive.
+type resourceLoad struct {
+	used  int64
+	limit int64
+}
+
+func newNodeLoads() *nodeLoads {
+	return &nodeLoads{
+		nodes: map[string]*nodeLoad{},
+	}
+}
+
+func (l *nodeLoads) set(nodeName string, load *nodeLoad) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if load == nil {
+		delete(l.nodes, nodeName)
+		return
+	}
+	l.nodes[nodeName] = load
+}
+
+// registerNodeLoadEventHandler updates the node loads of the plugin on the NodeMetric events, and on the Node events
+// since the allocatable and the custom usage thresholds of the node are taken and the Node may be synced later.
+func registerNodeLoadEventHandler(sharedInformerFactory informers.SharedInformerFactory,
+	koordSharedInformerFactory koordinatorinformers.SharedInformerFactory, p *Plugin) {
+	update := func(obj interface{}) {
+		switch t := obj.(type) {
+		case *corev1.Node:
+			p.updateNodeLoad(t.Name)
+		case *slov1alpha1.NodeMetric:
+			p.updateNodeLoad(t.Name)
+		case cache.DeletedFinalStateUnknown:
+			p.updateNodeLoad(t.Key)
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: update,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			update(newObj)
+		},
+		DeleteFunc: update,
+	}
+	sharedInformerFactory.Core().V1().Nodes().Informer().AddEventHandler(handler)
+	koordSharedInformerFactory.Slo().V1alpha1().NodeMetrics().Informer().AddEventHandler(handler)
+}
+
+// updateNodeLoad computes the load of the node from the listers, which are updated before the event handlers
+// are called, and drops the load if the Node or the NodeMetric is gone.
+func (p *Plugin) updateNodeLoad(nodeName string) {
+	node, err := p.nodeLister.Get(nodeName)
+	if err != nil {
+		p.nodeLoads.set(nodeName, nil)
+		return
+	}
+	nodeMetric, err := p.nodeMetricLister.Get(nodeName)
+	if err != nil {
+		p.nodeLoads.set(nodeName, nil)
+		return
+	}
+	p.nodeLoads.set(nodeName, p.computeNodeLoad(node, nodeMetric))
+}
+
+// computeNodeLoad returns the load of the weighted resources with the usage thresholds as Filter applies them,
+// or nil if the NodeMetric has no usage.
+func (p *Plugin) computeNodeLoad(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric) *nodeLoad {
+	if nodeMetric.Status.NodeMetric == nil || nodeMetric.Status.UpdateTime == nil {
+		return nil
+	}
+	args := p.getArgs()
+	filterProfile := p.generateUsageThresholdsFilterProfile(node, args.LoadAwareSchedulingArgs)
+	nodeUsage := getFilterNodeUsage(args, p.podLister, nodeMetric, filterProfile)
+	if nodeUsage == nil {
+		return nil
+	}
+	usageThresholds, _ := getFilterUsageThresholds(filterProfile)
+	load := &nodeLoad{
+		updateTime: getNodeMetricUpdateTime(nodeMetric),
+		resources:  map[corev1.ResourceName]resourceLoad{},
+	}
+	for resourceName := range args.ResourceWeights {
+		threshold, ok := usageThresholds[resourceName]
+		if !ok || threshold <= 0 {
+			continue
+		}
+		allocatable := getResourceValue(resourceName, node.Status.Allocatable[resourceName])
+		limit := allocatable * threshold / 100
+		// the limit is rounded down to zero if the allocatable is tiny, which has no meaningful ratio.
+		if limit <= 0 {
+			continue
+		}
+		load.resources[resourceName] = resourceLoad{
+			used:  getResourceValue(resourceName, nodeUsage.ResourceList[resourceName]),
+			limit: limit,
+		}
+	}
+	return load
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+)
+
+func TestNodeLoadCollectorDescribe(t *testing.T) {
+	collector := &nodeLoadCollector{}
+	ch := make(chan *metrics.Desc, 2)
+	collector.DescribeWithStability(ch)
+	close(ch)
+	var descs []*metrics.Desc
+	for desc := range ch {
+		descs = append(descs, desc)
+	}
+	assert.Equal(t, []*metrics.Desc{nodeUsageThresholdRatioDesc, clusterHeadroomDesc}, descs)
+}
+
+func TestNodeLoadCollectorCollect(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i, usage := range []struct {
+		cpu    string
+		memory string
+	}{
+		{cpu: "25", memory: "40Gi"},
+		{cpu: "60", memory: "16Gi"},
+	} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(usage.cpu),
+							corev1.ResourceMemory: resource.MustParse(usage.memory),
+						},
+					},
+				},
+			},
+		})
+	}
+	// the node without NodeMetric and the expired NodeMetric are not collected.
+	nodes = append(nodes, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-without-nodemetric",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-expired",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	})
+	// the node whose usage thresholds are rounded down to zero is not collected.
+	nodes = append(nodes, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-tiny",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1m"),
+				corev1.ResourceMemory: resource.MustParse("1"),
+			},
+		},
+	})
+	nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-tiny",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1m"),
+						corev1.ResourceMemory: resource.MustParse("1"),
+					},
+				},
+			},
+		},
+	}, &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-expired",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now().Add(-time.Hour),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+			},
+		},
+	})
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		UsageThresholds: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU:    50,
+			corev1.ResourceMemory: 80,
+		},
+	}, nodes, nodeMetrics, nil)
+	// the loads are computed by the event handlers, which may run after the informers are synced.
+	waitForNodeLoads(t, p, 4)
+
+	// the second node is over the CPU threshold and has no CPU headroom.
+	expected := `
+# HELP loadaware_cluster_headroom [ALPHA] Sum of the usage below the usage thresholds of the nodes, in milli-cores for CPU and in units for the others, by the resource name
+# TYPE loadaware_cluster_headroom gauge
+loadaware_cluster_headroom{resource="cpu"} 25000
+loadaware_cluster_headroom{resource="memory"} 111669149696
+# HELP loadaware_node_usage_threshold_ratio [ALPHA] Ratio of the node usage to the usage threshold of the weighted resources as Filter applies them, by the node name and the resource name. The node is filtered out if the ratio exceeds 1
+# TYPE loadaware_node_usage_threshold_ratio gauge
+loadaware_node_usage_threshold_ratio{node="test-node-1",resource="cpu"} 0.5
+loadaware_node_usage_threshold_ratio{node="test-node-1",resource="memory"} 0.5
+loadaware_node_usage_threshold_ratio{node="test-node-2",resource="cpu"} 1.2
+loadaware_node_usage_threshold_ratio{node="test-node-2",resource="memory"} 0.2
+`
+	err := testutil.CustomCollectAndCompare(&nodeLoadCollector{plugin: p}, strings.NewReader(expected),
+		"loadaware_cluster_headroom", "loadaware_node_usage_threshold_ratio")
+	assert.NoError(t, err)
+
+	// the load of the node is dropped with its NodeMetric.
+	extendedHandle := p.handle.(frameworkext.ExtendedHandle)
+	err = extendedHandle.KoordinatorClientSet().SloV1alpha1().NodeMetrics().Delete(context.TODO(), "test-node-2", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	waitForNodeLoads(t, p, 3)
+	expected = `
+# HELP loadaware_cluster_headroom [ALPHA] Sum of the usage below the usage thresholds of the nodes, in milli-cores for CPU and in units for the others, by the resource name
+# TYPE loadaware_cluster_headroom gauge
+loadaware_cluster_headroom{resource="cpu"} 25000
+loadaware_cluster_headroom{resource="memory"} 42949672960
+# HELP loadaware_node_usage_threshold_ratio [ALPHA] Ratio of the node usage to the usage threshold of the weighted resources as Filter applies them, by the node name and the resource name. The node is filtered out if the ratio exceeds 1
+# TYPE loadaware_node_usage_threshold_ratio gauge
+loadaware_node_usage_threshold_ratio{node="test-node-1",resource="cpu"} 0.5
+loadaware_node_usage_threshold_ratio{node="test-node-1",resource="memory"} 0.5
+`
+	err = testutil.CustomCollectAndCompare(&nodeLoadCollector{plugin: p}, strings.NewReader(expected),
+		"loadaware_cluster_headroom", "loadaware_node_usage_threshold_ratio")
+	assert.NoError(t, err)
+
+	// nothing is collected before the plugin is created.
+	err = testutil.CustomCollectAndCompare(&nodeLoadCollector{}, strings.NewReader(""),
+		"loadaware_cluster_headroom", "loadaware_node_usage_threshold_ratio")
+	assert.NoError(t, err)
+}
+
+func waitForNodeLoads(t *testing.T, p *Plugin, count int) {
+	assert.Eventually(t, func() bool {
+		p.nodeLoads.lock.RLock()
+		defer p.nodeLoads.lock.RUnlock()
+		return len(p.nodeLoads.nodes) == count
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+// nodeMetricShardIndexPrefix is the prefix of the name of the index of the NodeMetrics by the shard label value.
+const nodeMetricShardIndexPrefix = "loadaware.nodeMetricShard/"
+
+func nodeMetricShardIndexName(labelKey string) string {
+	return nodeMetricShardIndexPrefix + labelKey
+}
+
+// addNodeMetricShardIndexer indexes the NodeMetrics by the value of the shard label, i.e. the node name.
+func addNodeMetricShardIndexer(nodeMetricInformer cache.SharedIndexInformer, labelKey string) error {
+	indexName := nodeMetricShardIndexName(labelKey)
+	if nodeMetricInformer.GetIndexer().GetIndexers()[indexName] != nil {
+		return nil
+	}
+	err := nodeMetricInformer.AddIndexers(cache.Indexers{indexName: func(obj interface{}) ([]string, error) {
+		nodeMetric, ok := obj.(*slov1alpha1.NodeMetric)
+		if !ok {
+			return []string{}, nil
+		}
+		if nodeName := nodeMetric.Labels[labelKey]; nodeName != "" {
+			return []string{nodeName}, nil
+		}
+		return []string{}, nil
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to add NodeMetric shard indexer, err: %s", err)
+	}
+	return nil
+}
+
+// getReportedNodeMetric returns the NodeMetric reported for the node. If NodeMetricShardLabelKey is set,
+// the NodeMetrics labeled with the node name are merged, and the NotFound error is returned if there are none.
+func (p *Plugin) getReportedNodeMetric(args *config.LoadAwareSchedulingArgs, nodeName string) (*slov1alpha1.NodeMetric, error) {
+	if args.NodeMetricShardLabelKey == "" {
+		return p.nodeMetricLister.Get(nodeName)
+	}
+	shards, err := p.listNodeMetricShards(args.NodeMetricShardLabelKey, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		return nil, apierrors.NewNotFound(slov1alpha1.Resource("nodemetric"), nodeName)
+	}
+	return mergeNodeMetricShards(nodeName, shards), nil
+}
+
+// listNodeMetricShards returns the NodeMetrics labeled with the node name. The index is only added for the label key
+// in the args configured in KubeSchedulerConfiguration, so the NodeMetrics are listed if the dynamic args change the key.
+func (p *Plugin) listNodeMetricShards(labelKey, nodeName string) ([]*slov1alpha1.NodeMetric, error) {
+	indexName := nodeMetricShardIndexName(labelKey)
+	if p.nodeMetricIndexer == nil || p.nodeMetricIndexer.GetIndexers()[indexName] == nil {
+		return p.nodeMetricLister.List(labels.SelectorFromSet(labels.Set{labelKey: nodeName}))
+	}
+	objs, err := p.nodeMetricIndexer.ByIndex(indexName, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	shards := make([]*slov1alpha1.NodeMetric, 0, len(objs))
+	for _, obj := range objs {
+		if nodeMetric, ok := obj.(*slov1alpha1.NodeMetric); ok {
+			shards = append(shards, nodeMetric)
+		}
+	}
+	return shards, nil
+}
+
+// mergeNodeMetricShards merges the NodeMetrics of the node into one named after the node.
+// The usages of a resource reported by multiple shards are taken from the most recently updated shard,
+// and the UpdateTime is the oldest of the shards, so that the merged one expires once any shard expires.
+func mergeNodeMetricShards(nodeName string, shards []*slov1alpha1.NodeMetric) *slov1alpha1.NodeMetric {
+	shards = append([]*slov1alpha1.NodeMetric(nil), shards...)
+	sort.SliceStable(shards, func(i, j int) bool {
+		ti, tj := shards[i].Status.UpdateTime, shards[j].Status.UpdateTime
+		if ti == nil || tj == nil {
+			if (ti == nil) != (tj == nil) {
+				return tj == nil
+			}
+			return shards[i].Name < shards[j].Name
+		}
+		if !ti.Equal(tj) {
+			return tj.Before(ti)
+		}
+		return shards[i].Name < shards[j].Name
+	})
+
+	merged := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+	}
+	podMetrics := map[string]*slov1alpha1.PodMetricInfo{}
+	for i, shard := range shards {
+		if merged.Spec.CollectPolicy == nil && shard.Spec.CollectPolicy != nil {
+			merged.Spec = *shard.Spec.DeepCopy()
+		}
+		updateTime := shard.Status.UpdateTime
+		if i == 0 || updateTime == nil || (merged.Status.UpdateTime != nil && updateTime.Before(merged.Status.UpdateTime)) {
+			merged.Status.UpdateTime = updateTime.DeepCopy()
+		}
+		if shard.Status.NodeMetric != nil {
+			if merged.Status.NodeMetric == nil {
+				merged.Status.NodeMetric = &slov1alpha1.NodeMetricInfo{}
+			}
+			mergeResourceMap(&merged.Status.NodeMetric.NodeUsage, &shard.Status.NodeMetric.NodeUsage)
+			merged.Status.NodeMetric.AggregatedNodeUsages = mergeAggregatedUsages(merged.Status.NodeMetric.AggregatedNodeUsages, shard.Status.NodeMetric.AggregatedNodeUsages)
+		}
+		for _, podMetric := range shard.Status.PodsMetric {
+			if podMetric == nil {
+				continue
+			}
+			podName := getPodNamespacedName(podMetric.Namespace, podMetric.Name)
+			mergedPodMetric, ok := podMetrics[podName]
+			if !ok {
+				mergedPodMetric = &slov1alpha1.PodMetricInfo{Namespace: podMetric.Namespace, Name: podMetric.Name}
+				podMetrics[podName] = mergedPodMetric
+				merged.Status.PodsMetric = append(merged.Status.PodsMetric, mergedPodMetric)
+			}
+			mergeResourceMap(&mergedPodMetric.PodUsage, &podMetric.PodUsage)
+		}
+	}
+	return merged
+}
+
+// mergeResourceMap adds the resources of the shard missing in the merged one.
+func mergeResourceMap(merged, shard *slov1alpha1.ResourceMap) {
+	for resourceName, quantity := range shard.ResourceList {
+		if _, ok := merged.ResourceList[resourceName]; ok {
+			continue
+		}
+		if merged.ResourceList == nil {
+			merged.ResourceList = corev1.ResourceList{}
+		}
+		merged.ResourceList[resourceName] = quantity.DeepCopy()
+	}
+}
+
+// mergeAggregatedUsages merges the aggregated usages of the shard into the ones of the same duration,
+// and the sample count is the least of the shards.
+func mergeAggregatedUsages(merged, shard []slov1alpha1.AggregatedUsage) []slov1alpha1.AggregatedUsage {
+	for _, usage := range shard {
+		index := -1
+		for i := range merged {
+			if merged[i].Duration.Duration == usage.Duration.Duration {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			merged = append(merged, *usage.DeepCopy())
+			continue
+		}
+		if usage.SampleCount < merged[index].SampleCount {
+			merged[index].SampleCount = usage.SampleCount
+		}
+		for aggregationType, resourceMap := range usage.Usage {
+			if merged[index].Usage == nil {
+				merged[index].Usage = map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{}
+			}
+			mergedResourceMap := merged[index].Usage[aggregationType]
+			mergeResourceMap(&mergedResourceMap, &resourceMap)
+			merged[index].Usage[aggregationType] = mergedResourceMap
+		}
+	}
+	return merged
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+const testNodeMetricShardLabelKey = "node-metric-shard"
+
+func newTestNodeMetricShards(nodeName string, now time.Time) []*slov1alpha1.NodeMetric {
+	return []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   nodeName + "-memory",
+				Labels: map[string]string{testNodeMetricShardLabelKey: nodeName},
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{Time: now.Add(-30 * time.Second)},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							// the stale cpu usage is overridden by the more recent shard
+							corev1.ResourceCPU:    resource.MustParse("90"),
+							corev1.ResourceMemory: resource.MustParse("50Gi"),
+						},
+					},
+					AggregatedNodeUsages: []slov1alpha1.AggregatedUsage{
+						{
+							Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
+								slov1alpha1.P95: {ResourceList: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("60Gi")}},
+							},
+							Duration:    metav1.Duration{Duration: 5 * time.Minute},
+							SampleCount: 5,
+						},
+					},
+				},
+				PodsMetric: []*slov1alpha1.PodMetricInfo{
+					{
+						Namespace: "default",
+						Name:      "test-pod-1",
+						PodUsage:  slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("10Gi")}},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   nodeName + "-cpu",
+				Labels: map[string]string{testNodeMetricShardLabelKey: nodeName},
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{Time: now},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("50"),
+						},
+					},
+					AggregatedNodeUsages: []slov1alpha1.AggregatedUsage{
+						{
+							Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
+								slov1alpha1.P95: {ResourceList: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("60")}},
+							},
+							Duration:    metav1.Duration{Duration: 5 * time.Minute},
+							SampleCount: 10,
+						},
+					},
+				},
+				PodsMetric: []*slov1alpha1.PodMetricInfo{
+					{
+						Namespace: "default",
+						Name:      "test-pod-1",
+						PodUsage:  slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMergeNodeMetricShards(t *testing.T) {
+	now := time.Now()
+	merged := mergeNodeMetricShards("test-node-1", newTestNodeMetricShards("test-node-1", now))
+
+	assert.Equal(t, "test-node-1", merged.Name)
+	assert.True(t, merged.Status.UpdateTime.Time.Equal(now.Add(-30*time.Second)))
+	nodeUsage := merged.Status.NodeMetric.NodeUsage.ResourceList
+	assert.Len(t, nodeUsage, 2)
+	assert.Equal(t, "50", nodeUsage.Cpu().String())
+	assert.Equal(t, "50Gi", nodeUsage.Memory().String())
+
+	assert.Len(t, merged.Status.NodeMetric.AggregatedNodeUsages, 1)
+	aggregated := merged.Status.NodeMetric.AggregatedNodeUsages[0]
+	assert.Equal(t, 5*time.Minute, aggregated.Duration.Duration)
+	assert.Equal(t, int64(5), aggregated.SampleCount)
+	aggregatedUsage := aggregated.Usage[slov1alpha1.P95].ResourceList
+	assert.Equal(t, "60", aggregatedUsage.Cpu().String())
+	assert.Equal(t, "60Gi", aggregatedUsage.Memory().String())
+
+	assert.Len(t, merged.Status.PodsMetric, 1)
+	podUsage := merged.Status.PodsMetric[0].PodUsage.ResourceList
+	assert.Equal(t, "10", podUsage.Cpu().String())
+	assert.Equal(t, "10Gi", podUsage.Memory().String())
+}
+
+func TestScoreWithNodeMetricShards(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                    string
+		nodeMetricShardLabelKey string
+		wantScore               int64
+		wantReasons             map[string]Reason
+	}{
+		{
+			name:        "the sharded NodeMetrics are not read by default",
+			wantScore:   0,
+			wantReasons: map[string]Reason{"test-node-1": {Code: ReasonCodeNodeMetricNotFound}},
+		},
+		{
+			name:                    "score by the merged NodeMetrics",
+			nodeMetricShardLabelKey: testNodeMetricShardLabelKey,
+			wantScore:               49,
+			wantReasons:             map[string]Reason{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				NodeMetricShardLabelKey: tt.nodeMetricShardLabelKey,
+			}, nodes, newTestNodeMetricShards("test-node-1", time.Now()), nil)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, nodes).IsSuccess())
+			score, status := p.Score(context.TODO(), cycleState, pod, "test-node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+			assert.Equal(t, tt.wantReasons, GetScoreZeroReasons(cycleState))
+		})
+	}
+}
+
+func TestListNodeMetricShards(t *testing.T) {
+	shards := append(newTestNodeMetricShards("test-node-1", time.Now()), newTestNodeMetricShards("test-node-2", time.Now())...)
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		NodeMetricShardLabelKey: testNodeMetricShardLabelKey,
+	}, nil, shards, nil)
+	assert.Contains(t, p.nodeMetricIndexer.GetIndexers(), nodeMetricShardIndexName(testNodeMetricShardLabelKey))
+
+	got, err := p.listNodeMetricShards(testNodeMetricShardLabelKey, "test-node-1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, shards[:2], got)
+	got, err = p.listNodeMetricShards(testNodeMetricShardLabelKey, "test-node-3")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	// the label key changed by the dynamic args is not indexed and the NodeMetrics are listed.
+	got, err = p.listNodeMetricShards("other-shard-label", "test-node-1")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+	if p.getArgs().NodePoolLabelKey == "" {
+		return nil
+	}
+	return p
+}
+
+// NormalizeScore normalizes the scores of the nodes in the pool targeted by the Pod, so that the least
+// loaded node in the pool gets the highest score no matter how busy the pool is, and the nodes out of
+// the pool score 0. The scores are kept if the Pod targets no pool or opts out of the load-aware scheduling.
+func (p *Plugin) NormalizeScore(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, scores framework.NodeScoreList) *framework.Status {
+	args := p.getArgs()
+	pool := getTargetNodePool(pod, args.NodePoolLabelKey)
+	if pool == "" || isLoadAwareSchedulingSkipped(pod) {
+		return nil
+	}
+
+	var maxPoolScore int64
+	inPool := make([]bool, len(scores))
+	for i := range scores {
+		nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(scores[i].Name)
+		if err != nil || nodeInfo.Node() == nil {
+			continue
+		}
+		inPool[i] = nodeInfo.Node().Labels[args.NodePoolLabelKey] == pool
+		if inPool[i] && scores[i].Score > maxPoolScore {
+			maxPoolScore = scores[i].Score
+		}
+	}
+
+	maxScore := int64(framework.MaxNodeScore)
+	if args.ScoreScalingPercentage > 0 {
+		maxScore = maxScore * args.ScoreScalingPercentage / 100
+	}
+	for i := range scores {
+		if !inPool[i] || maxPoolScore == 0 {
+			scores[i].Score = 0
+			continue
+		}
+		scores[i].Score = scores[i].Score * maxScore / maxPoolScore
+	}
+	return nil
+}
+
+// getTargetNodePool returns the pool targeted by the nodeSelector of the Pod, or the label of the Pod
+// with the key if the nodeSelector does not target any pool.
+func getTargetNodePool(pod *corev1.Pod, nodePoolLabelKey string) string {
+	if nodePoolLabelKey == "" {
+		return ""
+	}
+	if pool := pod.Spec.NodeSelector[nodePoolLabelKey]; pool != "" {
+		return pool
+	}
+	return pod.Labels[nodePoolLabelKey]
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestScoreWithinNodePool(t *testing.T) {
+	const poolLabelKey = "node-pool"
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	// the nodes in pool busy are much more loaded than the nodes in pool idle
+	for i, state := range []struct {
+		pool     string
+		cpuUsage string
+	}{
+		{pool: "busy", cpuUsage: "48"},
+		{pool: "busy", cpuUsage: "60"},
+		{pool: "idle", cpuUsage: "0"},
+		{pool: "idle", cpuUsage: "10"},
+	} {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   nodeName,
+				Labels: map[string]string{poolLabelKey: state.pool},
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(state.cpuUsage),
+							corev1.ResourceMemory: resource.MustParse("0"),
+						},
+					},
+				},
+			},
+		})
+	}
+	tests := []struct {
+		name                   string
+		nodePoolLabelKey       string
+		scoreScalingPercentage int64
+		pod                    *corev1.Pod
+		wantScores             []int64
+	}{
+		{
+			name: "scored globally without node pool label key",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{poolLabelKey: "busy"},
+				},
+			},
+			wantScores: []int64{74, 68, 99, 94},
+		},
+		{
+			name:             "scored within the pool of nodeSelector",
+			nodePoolLabelKey: poolLabelKey,
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{poolLabelKey: "busy"},
+				},
+			},
+			wantScores: []int64{100, 91, 0, 0},
+		},
+		{
+			name:             "scored within the pool of the pod label",
+			nodePoolLabelKey: poolLabelKey,
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{poolLabelKey: "idle"},
+				},
+			},
+			wantScores: []int64{0, 0, 100, 94},
+		},
+		{
+			name:                   "normalized score is capped by scaling",
+			nodePoolLabelKey:       poolLabelKey,
+			scoreScalingPercentage: 50,
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{poolLabelKey: "busy"},
+				},
+			},
+			wantScores: []int64{50, 45, 0, 0},
+		},
+		{
+			name:             "scored globally if the pod targets no pool",
+			nodePoolLabelKey: poolLabelKey,
+			pod:              &corev1.Pod{},
+			wantScores:       []int64{74, 68, 99, 94},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				NodePoolLabelKey:       tt.nodePoolLabelKey,
+				ScoreScalingPercentage: tt.scoreScalingPercentage,
+			}, nodes, nodeMetrics, nil)
+			state := framework.NewCycleState()
+			var nodeScores framework.NodeScoreList
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), state, tt.pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				nodeScores = append(nodeScores, framework.NodeScore{Name: node.Name, Score: score})
+			}
+			if extensions := p.ScoreExtensions(); extensions != nil {
+				status := extensions.NormalizeScore(context.TODO(), state, tt.pod, nodeScores)
+				assert.True(t, status.IsSuccess())
+			}
+			var scores []int64
+			for _, nodeScore := range nodeScores {
+				scores = append(scores, nodeScore.Score)
+			}
+			assert.Equal(t, tt.wantScores, scores)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/services"
+)
+
+var _ services.APIServiceProvider = &Plugin{}
+
+func (p *Plugin) RegisterEndpoints(group *gin.RouterGroup) {
+	group.GET("/explain/:namespace/:name", func(c *gin.Context) {
+		pod, err := p.podLister.Pods(c.Param("namespace")).Get(c.Param("name"))
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			if errors.IsNotFound(err) {
+				statusCode = http.StatusNotFound
+			}
+			services.ResponseErrorMessage(c, statusCode, err.Error())
+			return
+		}
+		explanations, err := p.Explain(c.Request.Context(), pod)
+		if err != nil {
+			services.ResponseErrorMessage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, explanations)
+	})
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestEndpointsExplain(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for _, v := range []struct {
+		name     string
+		cpuUsage string
+	}{{"test-node-1", "70"}, {"test-node-2", "32"}} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: v.name,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: v.name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(v.cpuUsage),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nodes, nodeMetrics, []*corev1.Pod{pod})
+
+	engine := gin.Default()
+	p.RegisterEndpoints(engine.Group("/"))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/explain/default/test-pod", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	var explanations []NodeExplanation
+	err := json.NewDecoder(w.Result().Body).Decode(&explanations)
+	assert.NoError(t, err)
+	assert.Len(t, explanations, 2)
+
+	// test-node-1 is rejected by the CPU usage threshold.
+	assert.Equal(t, NodeExplanation{
+		NodeName: "test-node-1",
+		Reason:   Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}.Message(),
+	}, explanations[0])
+
+	// test-node-2 is scored with the estimated used of the pod and the node usage.
+	explanation := explanations[1]
+	assert.Equal(t, "test-node-2", explanation.NodeName)
+	assert.True(t, explanation.Feasible)
+	score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, "test-node-2")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, score, explanation.Score)
+	assert.Equal(t, int64(73), explanation.Score)
+	assert.Len(t, explanation.Resources, 2)
+	assert.Equal(t, ResourceScoreExplanation{
+		ResourceName:  corev1.ResourceCPU,
+		Weight:        1,
+		EstimatedUsed: 45600,
+		Allocatable:   96000,
+		Score:         52,
+	}, explanation.Resources[0])
+	assert.Equal(t, corev1.ResourceMemory, explanation.Resources[1].ResourceName)
+	assert.Equal(t, int64(93), explanation.Resources[1].Score)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/explain/default/not-found-pod", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestExplainLikeScore(t *testing.T) {
+	// test-node-0 has the most requested and is out of the top 1 node.
+	nodes, nodeMetrics, pods := newTopKTestObjects(2, func(i int) int64 {
+		return []int64{30, 0}[i]
+	})
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		ScoreTopKNodes: 1,
+		PriorityClassResourceWeights: map[extension.PriorityClass]map[corev1.ResourceName]int64{
+			extension.PriorityProd: {
+				corev1.ResourceCPU:    2,
+				corev1.ResourceMemory: 1,
+			},
+		},
+	}, nodes, nodeMetrics, pods)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(extension.PriorityProdValueMax),
+		},
+	}
+
+	explanations, err := p.Explain(context.TODO(), pod)
+	assert.NoError(t, err)
+	assert.Len(t, explanations, 2)
+
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreScore(context.TODO(), cycleState, pod, nodes).IsSuccess())
+	for i, node := range nodes {
+		score, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+		assert.True(t, status.IsSuccess())
+		assert.Equal(t, node.Name, explanations[i].NodeName)
+		assert.True(t, explanations[i].Feasible)
+		assert.Equal(t, score, explanations[i].Score)
+	}
+
+	// test-node-0 is out of the top 1 node and scores in the middle without the breakdown.
+	assert.Equal(t, int64(framework.MaxNodeScore/2), explanations[0].Score)
+	assert.Empty(t, explanations[0].Resources)
+
+	// test-node-1 is scored with the weights of the priority class of the pod.
+	assert.Empty(t, explanations[1].Reason)
+	assert.Len(t, explanations[1].Resources, 2)
+	assert.Equal(t, corev1.ResourceCPU, explanations[1].Resources[0].ResourceName)
+	assert.Equal(t, int64(2), explanations[1].Resources[0].Weight)
+	assert.Equal(t, corev1.ResourceMemory, explanations[1].Resources[1].ResourceName)
+	assert.Equal(t, int64(1), explanations[1].Resources[1].Weight)
+}
+
+func TestExplainAssignedPodWithoutMetrics(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node-explain-0"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("32"),
+					corev1.ResourceMemory: resource.MustParse("64Gi"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node-explain-1"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("32"),
+					corev1.ResourceMemory: resource.MustParse("64Gi"),
+				},
+			},
+		},
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: nodes[0].Name},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{Time: time.Now().Add(-10 * time.Second)},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+		{
+			// test-node-explain-1 exceeds the usage thresholds and is rejected.
+			ObjectMeta: metav1.ObjectMeta{Name: nodes[1].Name},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{Time: time.Now().Add(-10 * time.Second)},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("30"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	unassignedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod-uid",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	assignedPod := unassignedPod.DeepCopy()
+	assignedPod.Spec.NodeName = nodes[0].Name
+
+	// the explanation of the assigned pod equals to the one before the pod is assigned.
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nodes, nodeMetrics, nil)
+	want, err := p.Explain(context.TODO(), unassignedPod)
+	assert.NoError(t, err)
+
+	p, _ = newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nodes, nodeMetrics, []*corev1.Pod{assignedPod})
+	rejections, err := testutil.GetCounterMetricValue(FilterRejections.WithLabelValues(string(ReasonCodeUsageExceedThreshold)))
+	assert.NoError(t, err)
+	filterCount, err := testutil.GetHistogramMetricCount(PhaseDuration.WithLabelValues(phaseFilter))
+	assert.NoError(t, err)
+	estimateCount, err := testutil.GetHistogramMetricCount(PhaseDuration.WithLabelValues(phaseEstimateAssignedPodUsed))
+	assert.NoError(t, err)
+	NodeMetricAge.Reset()
+
+	got, err := p.Explain(context.TODO(), assignedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.True(t, got[0].Feasible)
+	assert.False(t, got[1].Feasible)
+	assert.Equal(t, Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}.Message(), got[1].Reason)
+
+	// the explanation records no metrics.
+	value, err := testutil.GetCounterMetricValue(FilterRejections.WithLabelValues(string(ReasonCodeUsageExceedThreshold)))
+	assert.NoError(t, err)
+	assert.Equal(t, rejections, value)
+	count, err := testutil.GetHistogramMetricCount(PhaseDuration.WithLabelValues(phaseFilter))
+	assert.NoError(t, err)
+	assert.Equal(t, filterCount, count)
+	count, err = testutil.GetHistogramMetricCount(PhaseDuration.WithLabelValues(phaseEstimateAssignedPodUsed))
+	assert.NoError(t, err)
+	assert.Equal(t, estimateCount, count)
+	ages, err := testutil.GetGaugeMetricValue(NodeMetricAge.WithLabelValues(nodes[0].Name))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), ages)
+}
@@ -17,68 +17,161 @@ limitations under the License.
 package loadaware
 
 import (
+	"errors"
+	"hash/fnv"
 	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware/estimator"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 var (
 	timeNowFn = time.Now
+
+	errEstimateFailed = errors.New("failed to estimate pod")
 )
 
+// podAssignCacheShards is the number of the shards of podAssignCache. The nodes are hashed into the shards,
+// so that scoring a node does not contend with reserving the Pods on the nodes of the other shards.
+const podAssignCacheShards = 32
+
 // podAssignCache stores the Pod information that has been successfully scheduled or is about to be bound
 type podAssignCache struct {
+	shards [podAssignCacheShards]*podAssignCacheShard
+}
+
+type podAssignCacheShard struct {
 	lock sync.RWMutex
-	// podInfoItems stores podAssignInfo according to each node.
+	// podInfoItems stores podAssignInfo according to each node of the shard.
 	// podAssignInfo is indexed using the Pod's types.UID
 	podInfoItems map[string]map[types.UID]*podAssignInfo
+	// estimator estimates the Pods once they are assigned, nil if the estimates are not cached.
+	estimator estimator.Estimator
 }
 
 type podAssignInfo struct {
 	timestamp time.Time
 	pod       *corev1.Pod
+	// estimated is the estimated used of the Pod by estimatedBy, nil if the estimation fails.
+	estimated   map[corev1.ResourceName]int64
+	estimatedBy estimator.Estimator
 }
 
 func newPodAssignCache() *podAssignCache {
-	return &podAssignCache{
-		podInfoItems: map[string]map[types.UID]*podAssignInfo{},
+	p := &podAssignCache{}
+	for i := range p.shards {
+		p.shards[i] = &podAssignCacheShard{
+			podInfoItems: map[string]map[types.UID]*podAssignInfo{},
+		}
 	}
+	return p
+}
+
+// shardOf returns the shard storing the assigned Pods of the node.
+func (p *podAssignCache) shardOf(nodeName string) *podAssignCacheShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(nodeName))
+	return p.shards[h.Sum32()%podAssignCacheShards]
 }
 
 func (p *podAssignCache) assign(nodeName string, pod *corev1.Pod) {
 	if nodeName == "" || util.IsPodTerminated(pod) {
 		return
 	}
-	p.lock.Lock()
-	defer p.lock.Unlock()
-	m := p.podInfoItems[nodeName]
+	shard := p.shardOf(nodeName)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+	m := shard.podInfoItems[nodeName]
 	if m == nil {
 		m = make(map[types.UID]*podAssignInfo)
-		p.podInfoItems[nodeName] = m
+		shard.podInfoItems[nodeName] = m
 	}
-	m[pod.UID] = &podAssignInfo{
+	assignInfo := &podAssignInfo{
 		timestamp: timeNowFn(),
 		pod:       pod,
 	}
+	assignInfo.estimate(shard.estimator)
+	m[pod.UID] = assignInfo
+}
+
+// setEstimator replaces the estimator when the args change and re-estimates all the assigned Pods.
+func (p *podAssignCache) setEstimator(e estimator.Estimator) {
+	for _, shard := range p.shards {
+		shard.lock.Lock()
+		shard.estimator = e
+		for _, m := range shard.podInfoItems {
+			for _, assignInfo := range m {
+				assignInfo.estimate(e)
+			}
+		}
+		shard.lock.Unlock()
+	}
+}
+
+func (info *podAssignInfo) estimate(e estimator.Estimator) {
+	info.estimated, info.estimatedBy = nil, e
+	if e == nil {
+		return
+	}
+	if estimated, err := e.Estimate(info.pod); err == nil {
+		info.estimated = estimated
+	}
+}
+
+// getEstimated returns the cached estimate if it is estimated by the estimator in effect,
+// otherwise estimates the Pod again.
+func (info *podAssignInfo) getEstimated(e estimator.Estimator) (map[corev1.ResourceName]int64, error) {
+	if info.estimatedBy == e && info.estimatedBy != nil {
+		if info.estimated == nil {
+			return nil, errEstimateFailed
+		}
+		return info.estimated, nil
+	}
+	return e.Estimate(info.pod)
 }
 
 func (p *podAssignCache) unAssign(nodeName string, pod *corev1.Pod) {
 	if nodeName == "" {
 		return
 	}
-	p.lock.Lock()
-	defer p.lock.Unlock()
-	delete(p.podInfoItems[nodeName], pod.UID)
-	if len(p.podInfoItems[nodeName]) == 0 {
-		delete(p.podInfoItems, nodeName)
+	shard := p.shardOf(nodeName)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+	delete(shard.podInfoItems[nodeName], pod.UID)
+	if len(shard.podInfoItems[nodeName]) == 0 {
+		delete(shard.podInfoItems, nodeName)
 	}
 }
 
+// deleteNode drops the assigned Pods of the node.
+func (p *podAssignCache) deleteNode(nodeName string) {
+	shard := p.shardOf(nodeName)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+	delete(shard.podInfoItems, nodeName)
+}
+
+func (info *podAssignInfo) deepCopy() podAssignInfo {
+	out := podAssignInfo{
+		timestamp:   info.timestamp,
+		pod:         info.pod.DeepCopy(),
+		estimatedBy: info.estimatedBy,
+	}
+	if info.estimated != nil {
+		out.estimated = make(map[corev1.ResourceName]int64, len(info.estimated))
+		for resourceName, value := range info.estimated {
+			out.estimated[resourceName] = value
+		}
+	}
+	return out
+}
+
 func (p *podAssignCache) OnAdd(obj interface{}) {
 	pod, ok := obj.(*corev1.Pod)
 	if !ok {
@@ -115,3 +208,32 @@ func (p *podAssignCache) OnDelete(obj interface{}) {
 	}
 	p.unAssign(pod.Spec.NodeName, pod)
 }
+
+// registerNodeEventHandler drops the assigned Pods of the deleted nodes, which complements the Pod events
+// because the Pods on a deleted node may never be unassigned if their delete events are missed.
+// The NodeMetricAge and the logged custom usage thresholds conflicts of the deleted nodes are dropped as well.
+func registerNodeEventHandler(sharedInformerFactory informers.SharedInformerFactory, assignCache *podAssignCache) {
+	nodeInformer := sharedInformerFactory.Core().V1().Nodes().Informer()
+	nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: assignCache.onNodeDelete,
+	})
+}
+
+func (p *podAssignCache) onNodeDelete(obj interface{}) {
+	var node *corev1.Node
+	switch t := obj.(type) {
+	case *corev1.Node:
+		node = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		node, ok = t.Obj.(*corev1.Node)
+		if !ok {
+			return
+		}
+	default:
+		return
+	}
+	p.deleteNode(node.Name)
+	NodeMetricAge.DeleteLabelValues(node.Name)
+	customUsageThresholdsConflicts.Delete(node.Name)
+}
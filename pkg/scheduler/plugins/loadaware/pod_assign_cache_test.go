@@ -17,15 +17,86 @@ limitations under the License.
 package loadaware
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware/estimator"
 )
 
+// snapshot returns a deep copy of the assigned Pods of each node, sorted by the assign time,
+// so that the tests read the cache without holding the lock.
+func (p *podAssignCache) snapshot() map[string][]podAssignInfo {
+	snapshot := map[string][]podAssignInfo{}
+	for _, shard := range p.shards {
+		shard.lock.RLock()
+		for nodeName, m := range shard.podInfoItems {
+			infos := make([]podAssignInfo, 0, len(m))
+			for _, assignInfo := range m {
+				infos = append(infos, assignInfo.deepCopy())
+			}
+			snapshot[nodeName] = infos
+		}
+		shard.lock.RUnlock()
+	}
+	for _, infos := range snapshot {
+		sort.Slice(infos, func(i, j int) bool {
+			if !infos[i].timestamp.Equal(infos[j].timestamp) {
+				return infos[i].timestamp.Before(infos[j].timestamp)
+			}
+			return infos[i].pod.UID < infos[j].pod.UID
+		})
+	}
+	return snapshot
+}
+
+// podCount returns the number of the Pods assigned to the node.
+func (p *podAssignCache) podCount(nodeName string) int {
+	shard := p.shardOf(nodeName)
+	shard.lock.RLock()
+	defer shard.lock.RUnlock()
+	return len(shard.podInfoItems[nodeName])
+}
+
+// newPodAssignCacheForTest returns the cache storing the assigned Pods of the nodes.
+func newPodAssignCacheForTest(podInfoItems map[string]map[types.UID]*podAssignInfo) *podAssignCache {
+	p := newPodAssignCache()
+	for nodeName, m := range podInfoItems {
+		p.shardOf(nodeName).podInfoItems[nodeName] = m
+	}
+	return p
+}
+
+// podInfoItemsOf returns the assigned Pods in all the shards of the cache.
+func podInfoItemsOf(p *podAssignCache) map[string]map[types.UID]*podAssignInfo {
+	podInfoItems := map[string]map[types.UID]*podAssignInfo{}
+	for _, shard := range p.shards {
+		for nodeName, m := range shard.podInfoItems {
+			podInfoItems[nodeName] = m
+		}
+	}
+	return podInfoItems
+}
+
 var fakeTimeNowFn = func() time.Time {
 	t := time.Time{}
 	t.Add(100 * time.Second)
@@ -101,7 +172,7 @@ func TestPodAssignCache_OnAdd(t *testing.T) {
 			timeNowFn = fakeTimeNowFn
 			assignCache := newPodAssignCache()
 			assignCache.OnAdd(tt.pod)
-			assert.Equal(t, tt.wantCache, assignCache.podInfoItems)
+			assert.Equal(t, tt.wantCache, podInfoItemsOf(assignCache))
 		})
 	}
 }
@@ -133,28 +204,26 @@ func TestPodAssignCache_OnUpdate(t *testing.T) {
 					Phase: corev1.PodFailed,
 				},
 			},
-			assignCache: &podAssignCache{
-				podInfoItems: map[string]map[types.UID]*podAssignInfo{
-					"test-node": {
-						"123456789": &podAssignInfo{
-							pod: &corev1.Pod{
-								ObjectMeta: metav1.ObjectMeta{
-									UID:       "123456789",
-									Namespace: "default",
-									Name:      "test",
-								},
-								Spec: corev1.PodSpec{
-									NodeName: "test-node",
-								},
-								Status: corev1.PodStatus{
-									Phase: corev1.PodRunning,
-								},
+			assignCache: newPodAssignCacheForTest(map[string]map[types.UID]*podAssignInfo{
+				"test-node": {
+					"123456789": &podAssignInfo{
+						pod: &corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								UID:       "123456789",
+								Namespace: "default",
+								Name:      "test",
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node",
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodRunning,
 							},
-							timestamp: fakeTimeNowFn(),
 						},
+						timestamp: fakeTimeNowFn(),
 					},
 				},
-			},
+			}),
 			wantCache: map[string]map[types.UID]*podAssignInfo{},
 		},
 		{
@@ -206,7 +275,7 @@ func TestPodAssignCache_OnUpdate(t *testing.T) {
 				assignCache = newPodAssignCache()
 			}
 			assignCache.OnUpdate(nil, tt.pod)
-			assert.Equal(t, tt.wantCache, assignCache.podInfoItems)
+			assert.Equal(t, tt.wantCache, podInfoItemsOf(assignCache))
 		})
 	}
 }
@@ -225,29 +294,421 @@ func TestPodAssignCache_OnDelete(t *testing.T) {
 			Phase: corev1.PodFailed,
 		},
 	}
-	assignCache := &podAssignCache{
-		podInfoItems: map[string]map[types.UID]*podAssignInfo{
-			"test-node": {
-				"123456789": &podAssignInfo{
-					pod: &corev1.Pod{
-						ObjectMeta: metav1.ObjectMeta{
-							UID:       "123456789",
-							Namespace: "default",
-							Name:      "test",
-						},
-						Spec: corev1.PodSpec{
-							NodeName: "test-node",
+	assignCache := newPodAssignCacheForTest(map[string]map[types.UID]*podAssignInfo{
+		"test-node": {
+			"123456789": &podAssignInfo{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						UID:       "123456789",
+						Namespace: "default",
+						Name:      "test",
+					},
+					Spec: corev1.PodSpec{
+						NodeName: "test-node",
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				},
+				timestamp: fakeTimeNowFn(),
+			},
+		},
+	})
+	assignCache.OnDelete(pod)
+	wantCache := map[string]map[types.UID]*podAssignInfo{}
+	assert.Equal(t, wantCache, podInfoItemsOf(assignCache))
+}
+
+type countingEstimator struct {
+	estimator.Estimator
+	lock  sync.Mutex
+	count int
+}
+
+func (e *countingEstimator) Estimate(pod *corev1.Pod) (map[corev1.ResourceName]int64, error) {
+	// the shards of podAssignCache estimate the Pods concurrently
+	e.lock.Lock()
+	e.count++
+	e.lock.Unlock()
+	return e.Estimator.Estimate(pod)
+}
+
+func newCountingEstimator(t *testing.T) *countingEstimator {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+	var args config.LoadAwareSchedulingArgs
+	assert.NoError(t, v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &args, nil))
+	e, err := estimator.NewDefaultEstimator(&args, nil)
+	assert.NoError(t, err)
+	return &countingEstimator{Estimator: e}
+}
+
+func TestPodAssignCacheEstimate(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test",
+			UID:       "123456789",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node",
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
 						},
-						Status: corev1.PodStatus{
-							Phase: corev1.PodRunning,
+					},
+				},
+			},
+		},
+	}
+	wantEstimated := map[corev1.ResourceName]int64{
+		corev1.ResourceCPU:    3400,
+		corev1.ResourceMemory: 6012954214,
+	}
+
+	oldEstimator, newEstimator := newCountingEstimator(t), newCountingEstimator(t)
+	assignCache := newPodAssignCache()
+	assignCache.setEstimator(oldEstimator)
+	assignCache.OnAdd(pod)
+	assert.Equal(t, 1, oldEstimator.count)
+
+	// the estimate is cached at assign time
+	assignInfo := assignCache.shardOf("test-node").podInfoItems["test-node"][pod.UID]
+	for i := 0; i < 3; i++ {
+		estimated, err := assignInfo.getEstimated(oldEstimator)
+		assert.NoError(t, err)
+		assert.Equal(t, wantEstimated, estimated)
+	}
+	assert.Equal(t, 1, oldEstimator.count)
+
+	// the pods are re-estimated when the args change
+	assignCache.setEstimator(newEstimator)
+	assert.Equal(t, 1, newEstimator.count)
+	estimated, err := assignInfo.getEstimated(newEstimator)
+	assert.NoError(t, err)
+	assert.Equal(t, wantEstimated, estimated)
+	assert.Equal(t, 1, newEstimator.count)
+
+	// the stale estimate is never used by the other estimator
+	_, err = assignInfo.getEstimated(oldEstimator)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, oldEstimator.count)
+}
+
+func BenchmarkScoreWithAssignedPods(b *testing.B) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+			},
+		},
+	}
+	var pods []*corev1.Pod
+	for i := 0; i < 100; i++ {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      fmt.Sprintf("test-pod-%d", i),
+				UID:       types.UID(fmt.Sprintf("test-pod-%d", i)),
+			},
+			Spec: corev1.PodSpec{
+				NodeName: node.Name,
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("100m"),
+								corev1.ResourceMemory: resource.MustParse("100Mi"),
+							},
 						},
 					},
-					timestamp: fakeTimeNowFn(),
 				},
 			},
+		})
+	}
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			p, _ := newPluginForTest(b, &v1beta2.LoadAwareSchedulingArgs{}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, pods)
+			if !cached {
+				p.podAssignCache.setEstimator(nil)
+			}
+			pod := &corev1.Pod{}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+			}
+		})
+	}
+}
+
+func TestPodAssignCacheSnapshot(t *testing.T) {
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID(name),
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node",
+			},
+		}
+	}
+	now := time.Now()
+	timeNowFn = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	defer func() {
+		timeNowFn = time.Now
+	}()
+
+	assignCache := newPodAssignCache()
+	assignCache.setEstimator(newCountingEstimator(t))
+	assignCache.assign("test-node", newPod("pod-2"))
+	assignCache.assign("test-node", newPod("pod-1"))
+	assert.Equal(t, 2, assignCache.podCount("test-node"))
+	assert.Equal(t, 0, assignCache.podCount("unknown-node"))
+
+	snapshot := assignCache.snapshot()
+	assert.Len(t, snapshot, 1)
+	assert.Len(t, snapshot["test-node"], 2)
+	// sorted by the assign time
+	assert.Equal(t, "pod-2", snapshot["test-node"][0].pod.Name)
+	assert.Equal(t, "pod-1", snapshot["test-node"][1].pod.Name)
+
+	// the snapshot is not affected by the changes of the cache and vice versa
+	snapshot["test-node"][0].pod.Name = "modified"
+	snapshot["test-node"][0].estimated[corev1.ResourceCPU] = -1
+	assignCache.unAssign("test-node", newPod("pod-1"))
+	assert.Len(t, snapshot["test-node"], 2)
+	assert.Equal(t, 1, assignCache.podCount("test-node"))
+	assignInfo := assignCache.shardOf("test-node").podInfoItems["test-node"]["pod-2"]
+	assert.Equal(t, "pod-2", assignInfo.pod.Name)
+	assert.NotEqual(t, int64(-1), assignInfo.estimated[corev1.ResourceCPU])
+}
+
+func TestPodAssignCacheSnapshotConcurrently(t *testing.T) {
+	assignCache := newPodAssignCache()
+	assignCache.setEstimator(newCountingEstimator(t))
+	const workers, podsPerWorker = 4, 100
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			nodeName := fmt.Sprintf("test-node-%d", w)
+			for i := 0; i < podsPerWorker; i++ {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      fmt.Sprintf("pod-%d-%d", w, i),
+						UID:       types.UID(fmt.Sprintf("pod-%d-%d", w, i)),
+					},
+				}
+				assignCache.assign(nodeName, pod)
+				if i%2 == 1 {
+					assignCache.unAssign(nodeName, pod)
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		for nodeName, infos := range assignCache.snapshot() {
+			assert.LessOrEqual(t, len(infos), podsPerWorker/2+1, nodeName)
+			assignCache.podCount(nodeName)
+		}
+	}
+
+	snapshot := assignCache.snapshot()
+	assert.Len(t, snapshot, workers)
+	for w := 0; w < workers; w++ {
+		nodeName := fmt.Sprintf("test-node-%d", w)
+		assert.Len(t, snapshot[nodeName], podsPerWorker/2)
+		assert.Equal(t, podsPerWorker/2, assignCache.podCount(nodeName))
+	}
+}
+
+func TestPodAssignCacheOnNodeDelete(t *testing.T) {
+	newAssignCache := func() *podAssignCache {
+		assignCache := newPodAssignCache()
+		for _, nodeName := range []string{"test-node-1", "test-node-2"} {
+			assignCache.assign(nodeName, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:       types.UID(nodeName + "-pod"),
+					Namespace: "default",
+					Name:      nodeName + "-pod",
+				},
+				Spec: corev1.PodSpec{
+					NodeName: nodeName,
+				},
+			})
+		}
+		return assignCache
+	}
+	deletedNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"}}
+	tests := []struct {
+		name      string
+		obj       interface{}
+		wantNodes []string
+	}{
+		{
+			name:      "delete node",
+			obj:       deletedNode,
+			wantNodes: []string{"test-node-2"},
+		},
+		{
+			name:      "delete node in DeletedFinalStateUnknown",
+			obj:       cache.DeletedFinalStateUnknown{Key: deletedNode.Name, Obj: deletedNode},
+			wantNodes: []string{"test-node-2"},
+		},
+		{
+			name:      "ignore unknown object",
+			obj:       cache.DeletedFinalStateUnknown{Key: deletedNode.Name, Obj: &corev1.Pod{}},
+			wantNodes: []string{"test-node-1", "test-node-2"},
 		},
 	}
-	assignCache.OnDelete(pod)
-	wantCache := map[string]map[types.UID]*podAssignInfo{}
-	assert.Equal(t, wantCache, assignCache.podInfoItems)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterMetrics()
+			NodeMetricAge.Reset()
+			for _, nodeName := range []string{"test-node-1", "test-node-2"} {
+				NodeMetricAge.WithLabelValues(nodeName).Set(30)
+			}
+			assignCache := newAssignCache()
+			assignCache.onNodeDelete(tt.obj)
+			var nodes []string
+			for nodeName := range assignCache.snapshot() {
+				nodes = append(nodes, nodeName)
+			}
+			assert.ElementsMatch(t, tt.wantNodes, nodes)
+			// the NodeMetricAge is dropped with the node, and it is recreated as 0 on read.
+			for _, nodeName := range []string{"test-node-1", "test-node-2"} {
+				age, err := testutil.GetGaugeMetricValue(NodeMetricAge.WithLabelValues(nodeName))
+				assert.NoError(t, err)
+				if sets.NewString(tt.wantNodes...).Has(nodeName) {
+					assert.Equal(t, float64(30), age)
+				} else {
+					assert.Equal(t, float64(0), age)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterNodeEventHandler(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	cs := kubefake.NewSimpleClientset(node)
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	assignCache := newPodAssignCache()
+	registerNodeEventHandler(informerFactory, assignCache)
+	informerFactory.Start(context.TODO().Done())
+	informerFactory.WaitForCacheSync(context.TODO().Done())
+
+	assignCache.assign(node.Name, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node.Name,
+		},
+	})
+	assert.Equal(t, 1, assignCache.podCount(node.Name))
+
+	assert.NoError(t, cs.CoreV1().Nodes().Delete(context.TODO(), node.Name, metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		return assignCache.podCount(node.Name) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// BenchmarkPodAssignCacheParallel reserves and scores in parallel, the nodes spread over the shards
+// contend less than a single node whose Pods are all in the same shard.
+func BenchmarkPodAssignCacheParallel(b *testing.B) {
+	for _, nodeCount := range []int{1, 1000} {
+		b.Run(fmt.Sprintf("%d nodes", nodeCount), func(b *testing.B) {
+			assignCache := newPodAssignCache()
+			var nodeNames []string
+			for i := 0; i < nodeCount; i++ {
+				nodeName := fmt.Sprintf("test-node-%d", i)
+				nodeNames = append(nodeNames, nodeName)
+				for j := 0; j < 10; j++ {
+					assignCache.assign(nodeName, &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							UID:       types.UID(fmt.Sprintf("%s-pod-%d", nodeName, j)),
+							Namespace: "default",
+							Name:      fmt.Sprintf("%s-pod-%d", nodeName, j),
+						},
+					})
+				}
+			}
+			pods := make([]*corev1.Pod, 1024)
+			for i := range pods {
+				pods[i] = &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						UID:       types.UID(fmt.Sprintf("pod-%d", i)),
+						Namespace: "default",
+						Name:      fmt.Sprintf("pod-%d", i),
+					},
+				}
+			}
+			var counter int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := atomic.AddInt64(&counter, 1) * 7919
+				for pb.Next() {
+					i++
+					nodeName := nodeNames[i%int64(len(nodeNames))]
+					pod := pods[i%int64(len(pods))]
+					// Reserve and Unreserve write the cache while Score reads it.
+					assignCache.assign(nodeName, pod)
+					for j := 0; j < 4; j++ {
+						shard := assignCache.shardOf(nodeName)
+						shard.lock.RLock()
+						for range shard.podInfoItems[nodeName] {
+						}
+						shard.lock.RUnlock()
+					}
+					assignCache.unAssign(nodeName, pod)
+				}
+			})
+		})
+	}
 }
@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+const (
+	filterStateKey = "Filter" + Name
+)
+
+// FilterDiagnosis summarizes the reasons why Filter rejects the nodes in a scheduling cycle.
+type FilterDiagnosis string
+
+const (
+	// FilterDiagnosisNone means no node is rejected by Filter.
+	FilterDiagnosisNone FilterDiagnosis = ""
+	// FilterDiagnosisAllNodeMetricExpired means all the rejected nodes have expired or missing NodeMetric.
+	// The usage thresholds should not be relaxed because the metrics are just stale.
+	FilterDiagnosisAllNodeMetricExpired FilterDiagnosis = "AllNodeMetricExpired"
+	// FilterDiagnosisAllUsageExceedThreshold means all the rejected nodes are overloaded.
+	FilterDiagnosisAllUsageExceedThreshold FilterDiagnosis = "AllUsageExceedThreshold"
+	// FilterDiagnosisMixed means some rejected nodes have expired or missing NodeMetric and the others are overloaded.
+	FilterDiagnosisMixed FilterDiagnosis = "Mixed"
+)
+
+// filterState records the reasons of the nodes rejected by Filter, which run in parallel,
+// and the reasons of the nodes exceeding the usage thresholds but admitted in SoftThreshold mode.
+type filterState struct {
+	lock         sync.Mutex
+	reasons      map[string]Reason
+	softBreaches map[string]Reason
+}
+
+func (s *filterState) Clone() framework.StateData {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	reasons := make(map[string]Reason, len(s.reasons))
+	for nodeName, reason := range s.reasons {
+		reasons[nodeName] = reason
+	}
+	softBreaches := make(map[string]Reason, len(s.softBreaches))
+	for nodeName, reason := range s.softBreaches {
+		softBreaches[nodeName] = reason
+	}
+	return &filterState{reasons: reasons, softBreaches: softBreaches}
+}
+
+func getFilterState(cycleState *framework.CycleState) *filterState {
+	v, err := cycleState.Read(filterStateKey)
+	if err != nil {
+		return nil
+	}
+	s, _ := v.(*filterState)
+	return s
+}
+
+func recordFilterReason(cycleState *framework.CycleState, nodeName string, reason Reason) {
+	s := getFilterState(cycleState)
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.reasons[nodeName] = reason
+}
+
+func recordSoftThresholdBreach(cycleState *framework.CycleState, nodeName string, reason Reason) {
+	s := getFilterState(cycleState)
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.softBreaches[nodeName] = reason
+}
+
+// getSoftThresholdBreach returns the reason if the node exceeds the usage thresholds but is admitted
+// by Filter in SoftThreshold mode.
+func getSoftThresholdBreach(cycleState *framework.CycleState, nodeName string) (Reason, bool) {
+	s := getFilterState(cycleState)
+	if s == nil {
+		return Reason{}, false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	reason, ok := s.softBreaches[nodeName]
+	return reason, ok
+}
+
+// isThresholdReason returns true if the node is rejected because the usage exceeds the thresholds.
+func isThresholdReason(code ReasonCode) bool {
+	return code == ReasonCodeUsageExceedThreshold ||
+		code == ReasonCodeAggregatedUsageExceedThreshold ||
+		code == ReasonCodeCombinedUsageExceedThreshold
+}
+
+// GetFilterReasons returns the reasons of the nodes rejected by Filter in the scheduling cycle, keyed by node name.
+// It returns nil if PreFilter is not enabled.
+func GetFilterReasons(cycleState *framework.CycleState) map[string]Reason {
+	s := getFilterState(cycleState)
+	if s == nil {
+		return nil
+	}
+	return s.Clone().(*filterState).reasons
+}
+
+// DiagnoseFilterReasons tells whether the nodes are rejected due to expired NodeMetric or overload.
+func DiagnoseFilterReasons(reasons map[string]Reason) FilterDiagnosis {
+	var expired, overloaded int
+	for _, reason := range reasons {
+		if reason.Code == ReasonCodeNodeMetricExpired || reason.Code == ReasonCodeNodeMetricNotFound {
+			expired++
+		} else {
+			overloaded++
+		}
+	}
+	switch {
+	case expired > 0 && overloaded > 0:
+		return FilterDiagnosisMixed
+	case expired > 0:
+		return FilterDiagnosisAllNodeMetricExpired
+	case overloaded > 0:
+		return FilterDiagnosisAllUsageExceedThreshold
+	default:
+		return FilterDiagnosisNone
+	}
+}
+
+// PreFilter prepares the state to record the reasons of the nodes rejected by Filter.
+// It also requeues the Pod while the NodeMetrics of all nodes are expired if AllNodeMetricsExpiredRequeueSeconds is set,
+// because the framework does not support PreEnqueue plugins yet.
+func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) *framework.Status {
+	cycleState.Write(filterStateKey, &filterState{reasons: map[string]Reason{}, softBreaches: map[string]Reason{}})
+	if isLoadAwareSchedulingSkipped(pod) {
+		return nil
+	}
+	args := p.getArgs()
+	if args.AllNodeMetricsExpiredRequeueSeconds > 0 && args.NodeMetricExpirationSeconds != nil && *args.NodeMetricExpirationSeconds > 0 {
+		if p.waitForNodeMetricsRecovery(args.LoadAwareSchedulingArgs) {
+			klog.V(4).InfoS("LoadAwareScheduling requeues the pod because all nodeMetrics are expired", "pod", klog.KObj(pod))
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonAllNodeMetricsExpired)
+		}
+	}
+	return nil
+}
+
+// waitForNodeMetricsRecovery returns true if the NodeMetrics of all nodes are expired and
+// AllNodeMetricsExpiredRequeueSeconds has not elapsed since the last NodeMetric expired.
+// It returns false if there are no NodeMetrics at all, e.g. koordlet is not deployed.
+// The NodeMetrics are all expired if and only if the latest updated one is expired.
+func (p *Plugin) waitForNodeMetricsRecovery(args *config.LoadAwareSchedulingArgs) bool {
+	lastUpdateTime := p.latestNodeMetricUpdateTime.get()
+	if lastUpdateTime.IsZero() {
+		return false
+	}
+	allExpiredTime := lastUpdateTime.Add(time.Duration(*args.NodeMetricExpirationSeconds) * time.Second)
+	sinceAllExpired := time.Since(allExpiredTime)
+	return sinceAllExpired >= 0 && sinceAllExpired < time.Duration(args.AllNodeMetricsExpiredRequeueSeconds)*time.Second
+}
+
+func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// PostFilter never makes the Pod schedulable, it explains whether the nodes are rejected
+// due to expired NodeMetric or overload, the two cases need different responses.
+func (p *Plugin) PostFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, _ framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	reasons := GetFilterReasons(cycleState)
+	var message string
+	switch DiagnoseFilterReasons(reasons) {
+	case FilterDiagnosisAllNodeMetricExpired:
+		message = fmt.Sprintf("all %d node(s) rejected by %s have expired or missing nodeMetric", len(reasons), Name)
+	case FilterDiagnosisAllUsageExceedThreshold:
+		message = fmt.Sprintf("all %d node(s) rejected by %s exceed usage threshold", len(reasons), Name)
+		p.recordUnschedulableAttempt(pod)
+	case FilterDiagnosisMixed:
+		message = fmt.Sprintf("%d node(s) rejected by %s due to expired nodeMetric or usage exceed threshold", len(reasons), Name)
+		p.recordUnschedulableAttempt(pod)
+	default:
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	klog.V(4).InfoS("LoadAwareScheduling PostFilter diagnosis", "pod", klog.KObj(pod), "diagnosis", message)
+	return nil, framework.NewStatus(framework.Unschedulable, message)
+}
+
+// recordUnschedulableAttempt counts the attempt of the Pod unschedulable due to the usage thresholds
+// if ThresholdRelaxationPercentPerAttempt is set, so that the thresholds are relaxed for it in the next attempts.
+func (p *Plugin) recordUnschedulableAttempt(pod *corev1.Pod) {
+	if p.getArgs().ThresholdRelaxationPercentPerAttempt > 0 {
+		p.unschedulableAttempts.increase(pod.UID)
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestPostFilterDiagnosis(t *testing.T) {
+	type testNodeState struct {
+		updateTime time.Time
+		cpuUsage   string
+	}
+	expiredNode := testNodeState{updateTime: time.Now().Add(-180 * time.Second), cpuUsage: "10"}
+	overloadedNode := testNodeState{updateTime: time.Now(), cpuUsage: "70"}
+	tests := []struct {
+		name          string
+		nodeStates    []testNodeState
+		wantReasons   map[string]Reason
+		wantDiagnosis FilterDiagnosis
+		wantMessage   string
+	}{
+		{
+			name:       "all nodes expired",
+			nodeStates: []testNodeState{expiredNode, expiredNode},
+			wantReasons: map[string]Reason{
+				"test-node-1": {Code: ReasonCodeNodeMetricExpired},
+				"test-node-2": {Code: ReasonCodeNodeMetricExpired},
+			},
+			wantDiagnosis: FilterDiagnosisAllNodeMetricExpired,
+			wantMessage:   "all 2 node(s) rejected by LoadAwareScheduling have expired or missing nodeMetric",
+		},
+		{
+			name:       "all nodes overloaded",
+			nodeStates: []testNodeState{overloadedNode, overloadedNode},
+			wantReasons: map[string]Reason{
+				"test-node-1": {Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU},
+				"test-node-2": {Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU},
+			},
+			wantDiagnosis: FilterDiagnosisAllUsageExceedThreshold,
+			wantMessage:   "all 2 node(s) rejected by LoadAwareScheduling exceed usage threshold",
+		},
+		{
+			name:       "nodes expired or overloaded",
+			nodeStates: []testNodeState{expiredNode, overloadedNode},
+			wantReasons: map[string]Reason{
+				"test-node-1": {Code: ReasonCodeNodeMetricExpired},
+				"test-node-2": {Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU},
+			},
+			wantDiagnosis: FilterDiagnosisMixed,
+			wantMessage:   "2 node(s) rejected by LoadAwareScheduling due to expired nodeMetric or usage exceed threshold",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nodes []*corev1.Node
+			var nodeMetrics []*slov1alpha1.NodeMetric
+			for i, nodeState := range tt.nodeStates {
+				nodeName := fmt.Sprintf("test-node-%d", i+1)
+				nodes = append(nodes, &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+					},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("96"),
+							corev1.ResourceMemory: resource.MustParse("512Gi"),
+						},
+					},
+				})
+				nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+					},
+					Status: slov1alpha1.NodeMetricStatus{
+						UpdateTime: &metav1.Time{
+							Time: nodeState.updateTime,
+						},
+						NodeMetric: &slov1alpha1.NodeMetricInfo{
+							NodeUsage: slov1alpha1.ResourceMap{
+								ResourceList: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse(nodeState.cpuUsage),
+									corev1.ResourceMemory: resource.MustParse("100Gi"),
+								},
+							},
+						},
+					},
+				})
+			}
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nodes, nodeMetrics, nil)
+
+			cycleState := framework.NewCycleState()
+			pod := &corev1.Pod{}
+			assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+			filteredNodeStatusMap := framework.NodeToStatusMap{}
+			for _, node := range nodes {
+				nodeInfo, err := snapshot.Get(node.Name)
+				assert.NoError(t, err)
+				status := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+				assert.False(t, status.IsSuccess())
+				filteredNodeStatusMap[node.Name] = status
+			}
+
+			reasons := GetFilterReasons(cycleState)
+			assert.Equal(t, tt.wantReasons, reasons)
+			assert.Equal(t, tt.wantDiagnosis, DiagnoseFilterReasons(reasons))
+
+			result, status := p.PostFilter(context.TODO(), cycleState, pod, filteredNodeStatusMap)
+			assert.Nil(t, result)
+			assert.Equal(t, framework.Unschedulable, status.Code())
+			assert.Equal(t, tt.wantMessage, status.Message())
+		})
+	}
+}
+
+func TestPostFilterWithoutPreFilter(t *testing.T) {
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nil, nil, nil)
+	cycleState := framework.NewCycleState()
+	assert.Nil(t, GetFilterReasons(cycleState))
+	result, status := p.PostFilter(context.TODO(), cycleState, &corev1.Pod{}, nil)
+	assert.Nil(t, result)
+	assert.Equal(t, framework.Unschedulable, status.Code())
+}
+
+func TestPreFilterWithAllNodeMetricsExpired(t *testing.T) {
+	tests := []struct {
+		name                                string
+		allNodeMetricsExpiredRequeueSeconds int64
+		updateTimes                         []time.Duration
+		wantCode                            framework.Code
+	}{
+		{
+			name:        "not enabled",
+			updateTimes: []time.Duration{200 * time.Second, 200 * time.Second},
+			wantCode:    framework.Success,
+		},
+		{
+			name:                                "requeue while all nodeMetrics expired",
+			allNodeMetricsExpiredRequeueSeconds: 60,
+			updateTimes:                         []time.Duration{200 * time.Second, 300 * time.Second},
+			wantCode:                            framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name:                                "proceed after the requeue timeout",
+			allNodeMetricsExpiredRequeueSeconds: 60,
+			updateTimes:                         []time.Duration{300 * time.Second, 400 * time.Second},
+			wantCode:                            framework.Success,
+		},
+		{
+			name:                                "proceed if any nodeMetric recovers",
+			allNodeMetricsExpiredRequeueSeconds: 60,
+			updateTimes:                         []time.Duration{0, 200 * time.Second},
+			wantCode:                            framework.Success,
+		},
+		{
+			name:                                "proceed without nodeMetrics",
+			allNodeMetricsExpiredRequeueSeconds: 60,
+			wantCode:                            framework.Success,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nodes []*corev1.Node
+			var nodeMetrics []*slov1alpha1.NodeMetric
+			for i, updateTime := range tt.updateTimes {
+				nodeName := fmt.Sprintf("test-node-%d", i)
+				nodes = append(nodes, &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+					},
+				})
+				nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+					},
+					Status: slov1alpha1.NodeMetricStatus{
+						UpdateTime: &metav1.Time{
+							Time: time.Now().Add(-updateTime),
+						},
+					},
+				})
+			}
+			expirationSeconds := int64(180)
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				NodeMetricExpirationSeconds:         &expirationSeconds,
+				AllNodeMetricsExpiredRequeueSeconds: tt.allNodeMetricsExpiredRequeueSeconds,
+			}, nodes, nodeMetrics, nil)
+			// the latest UpdateTime is tracked by the event handlers, which may run after the informers are synced.
+			if len(nodeMetrics) > 0 {
+				assert.Eventually(t, func() bool {
+					return !p.latestNodeMetricUpdateTime.get().IsZero()
+				}, 5*time.Second, 10*time.Millisecond)
+			}
+			status := p.PreFilter(context.TODO(), framework.NewCycleState(), &corev1.Pod{})
+			assert.Equal(t, tt.wantCode, status.Code())
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	stateKey = Name
+)
+
+type stateData struct {
+	// skipScore is true if the Pod is not scored, and all nodes score 0.
+	skipScore bool
+	// topKNodes is the nodes to be fully scored, and the other nodes score MaxNodeScore / 2.
+	topKNodes sets.String
+	// sampledNodes is the nodes to be fully scored, and the other nodes score MaxNodeScore / 2.
+	sampledNodes sets.String
+}
+
+func (s *stateData) Clone() framework.StateData {
+	return s
+}
+
+func getStateData(cycleState *framework.CycleState) *stateData {
+	v, err := cycleState.Read(stateKey)
+	if err != nil {
+		return nil
+	}
+	s, _ := v.(*stateData)
+	return s
+}
+
+// PreScore selects the ScoreTopKNodes nodes with the least requested utilization to be fully scored,
+// which only reads the snapshot rather than NodeMetric, or the ScoreSampleNodes nodes sampled at random.
+// It also prepares the state to record the reasons of the nodes scored 0 and the score breakdowns.
+// The Pod of the priority class without weights in PriorityClassResourceWeights skips scoring. The state is
+// recorded rather than returning Skip, which fails the scheduling cycle if returned by PreScore in this framework.
+func (p *Plugin) PreScore(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodes []*corev1.Node) *framework.Status {
+	cycleState.Write(scoreStateKey, newScoreState())
+	args := p.getArgs()
+	if _, ok := getPriorityClassResourceWeights(args.LoadAwareSchedulingArgs, pod); !ok {
+		cycleState.Write(stateKey, &stateData{skipScore: true})
+		return nil
+	}
+	if args.ScoreSampleNodes > 0 && int64(len(nodes)) > args.ScoreSampleNodes {
+		cycleState.Write(stateKey, &stateData{sampledNodes: sampleNodes(pod, nodes, args.ScoreSampleNodes)})
+		return nil
+	}
+	if args.ScoreTopKNodes <= 0 || int64(len(nodes)) <= args.ScoreTopKNodes {
+		return nil
+	}
+
+	utilizations := make([]int64, len(nodes))
+	p.handle.Parallelizer().Until(ctx, len(nodes), func(piece int) {
+		nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodes[piece].Name)
+		if err != nil || nodeInfo.Node() == nil {
+			utilizations[piece] = math.MaxInt64
+			return
+		}
+		utilizations[piece] = requestedUtilization(nodeInfo, args.ResourceWeights)
+	})
+
+	indexes := make([]int, len(nodes))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		a, b := indexes[i], indexes[j]
+		if utilizations[a] != utilizations[b] {
+			return utilizations[a] < utilizations[b]
+		}
+		return nodes[a].Name < nodes[b].Name
+	})
+	topKNodes := sets.NewString()
+	for _, index := range indexes[:args.ScoreTopKNodes] {
+		topKNodes.Insert(nodes[index].Name)
+	}
+	cycleState.Write(stateKey, &stateData{topKNodes: topKNodes})
+	return nil
+}
+
+// sampleNodes returns the sampleSize nodes sampled at random. The nodes are sorted by name and shuffled
+// by the random source seeded by the UID of the Pod, so that the sample of the Pod is reproducible.
+func sampleNodes(pod *corev1.Pod, nodes []*corev1.Node, sampleSize int64) sets.String {
+	nodeNames := make([]string, len(nodes))
+	for i, node := range nodes {
+		nodeNames[i] = node.Name
+	}
+	sort.Strings(nodeNames)
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(pod.UID))
+	r := rand.New(rand.NewSource(int64(hash.Sum64())))
+	r.Shuffle(len(nodeNames), func(i, j int) {
+		nodeNames[i], nodeNames[j] = nodeNames[j], nodeNames[i]
+	})
+	return sets.NewString(nodeNames[:sampleSize]...)
+}
+
+// requestedUtilization returns the weighted average percentage of the requested to the allocatable,
+// the resources that are not allocatable on the node are regarded as fully requested.
+func requestedUtilization(nodeInfo *framework.NodeInfo, resourceWeights map[corev1.ResourceName]int64) int64 {
+	var utilization, weightSum int64
+	for resourceName, weight := range resourceWeights {
+		requested := getNodeInfoResourceValue(resourceName, nodeInfo.Requested)
+		allocatable := getNodeInfoResourceValue(resourceName, nodeInfo.Allocatable)
+		resourceUtilization := int64(100)
+		if allocatable > 0 {
+			resourceUtilization = requested * 100 / allocatable
+		}
+		utilization += resourceUtilization * weight
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 0
+	}
+	return utilization / weightSum
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+)
+
+func newTopKTestObjects(nodeCount int, requestedCPU func(i int) int64) ([]*corev1.Node, []*slov1alpha1.NodeMetric, []*corev1.Pod) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	var pods []*corev1.Pod
+	for i := 0; i < nodeCount; i++ {
+		nodeName := fmt.Sprintf("test-node-%d", i)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("32Gi"),
+						},
+					},
+				},
+			},
+		})
+		if cpu := requestedCPU(i); cpu > 0 {
+			pods = append(pods, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      fmt.Sprintf("test-pod-%d", i),
+				},
+				Spec: corev1.PodSpec{
+					NodeName: nodeName,
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: *resource.NewQuantity(cpu, resource.DecimalSI),
+								},
+							},
+						},
+					},
+				},
+			})
+		}
+	}
+	return nodes, nodeMetrics, pods
+}
+
+func TestScoreTopKNodes(t *testing.T) {
+	// test-node-0 has the most requested and test-node-2 has the least requested
+	nodes, nodeMetrics, pods := newTopKTestObjects(3, func(i int) int64 {
+		return []int64{30, 10, 0}[i]
+	})
+	tests := []struct {
+		name           string
+		scoreTopKNodes int64
+		// wantSkipped are the nodes not fully scored, which score MaxNodeScore / 2.
+		wantSkipped []string
+	}{
+		{
+			name:           "all nodes are scored by default",
+			scoreTopKNodes: 0,
+		},
+		{
+			name:           "all nodes are scored if nodes are not more than K",
+			scoreTopKNodes: 3,
+		},
+		{
+			name:           "only top K nodes are scored",
+			scoreTopKNodes: 2,
+			wantSkipped:    []string{"test-node-0"},
+		},
+		{
+			name:           "only the least requested node is scored",
+			scoreTopKNodes: 1,
+			wantSkipped:    []string{"test-node-0", "test-node-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreTopKNodes: tt.scoreTopKNodes,
+			}, nodes, nodeMetrics, pods)
+
+			cycleState := framework.NewCycleState()
+			pod := &corev1.Pod{}
+			status := p.PreScore(context.TODO(), cycleState, pod, nodes)
+			assert.True(t, status.IsSuccess())
+
+			var gotSkipped []string
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				if score == framework.MaxNodeScore/2 {
+					gotSkipped = append(gotSkipped, node.Name)
+				} else {
+					assert.Greater(t, score, int64(framework.MaxNodeScore/2))
+				}
+			}
+			assert.Equal(t, tt.wantSkipped, gotSkipped)
+		})
+	}
+}
+
+func TestSampleNodes(t *testing.T) {
+	nodes, _, _ := newTopKTestObjects(10, func(i int) int64 { return 0 })
+	nodeNames := sets.NewString()
+	reversedNodes := make([]*corev1.Node, len(nodes))
+	for i, node := range nodes {
+		nodeNames.Insert(node.Name)
+		reversedNodes[len(nodes)-1-i] = node
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "test-pod"}}
+
+	sampled := sampleNodes(pod, nodes, 3)
+	assert.Equal(t, 3, sampled.Len())
+	assert.True(t, nodeNames.IsSuperset(sampled))
+	// the sample is reproducible for the Pod regardless of the order of the nodes.
+	assert.Equal(t, sampled, sampleNodes(pod, nodes, 3))
+	assert.Equal(t, sampled, sampleNodes(pod, reversedNodes, 3))
+
+	// the Pods of different UIDs are sampled differently.
+	differentSamples := false
+	for i := 0; i < 10; i++ {
+		otherPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(fmt.Sprintf("test-pod-%d", i))}}
+		if !sampleNodes(otherPod, nodes, 3).Equal(sampled) {
+			differentSamples = true
+			break
+		}
+	}
+	assert.True(t, differentSamples)
+}
+
+func TestScoreSampleNodes(t *testing.T) {
+	nodes, nodeMetrics, _ := newTopKTestObjects(5, func(i int) int64 { return 0 })
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "test-pod"}}
+	tests := []struct {
+		name             string
+		scoreSampleNodes int64
+		wantScored       int
+	}{
+		{
+			name:       "all nodes are scored by default",
+			wantScored: 5,
+		},
+		{
+			name:             "all nodes are scored if nodes are not more than the sample size",
+			scoreSampleNodes: 5,
+			wantScored:       5,
+		},
+		{
+			name:             "only the sampled nodes are scored",
+			scoreSampleNodes: 2,
+			wantScored:       2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreSampleNodes: tt.scoreSampleNodes,
+			}, nodes, nodeMetrics, nil)
+
+			cycleState := framework.NewCycleState()
+			status := p.PreScore(context.TODO(), cycleState, pod, nodes)
+			assert.True(t, status.IsSuccess())
+
+			var scored []string
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				if score != framework.MaxNodeScore/2 {
+					scored = append(scored, node.Name)
+				}
+			}
+			assert.Len(t, scored, tt.wantScored)
+			if tt.wantScored < len(nodes) {
+				assert.ElementsMatch(t, sampleNodes(pod, nodes, tt.scoreSampleNodes).List(), scored)
+			}
+		})
+	}
+}
+
+func TestValidateScoreSampleNodes(t *testing.T) {
+	v1beta2args := &v1beta2.LoadAwareSchedulingArgs{
+		ScoreTopKNodes:   2,
+		ScoreSampleNodes: 2,
+	}
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+	err = validation.ValidateLoadAwareSchedulingArgs(&loadAwareSchedulingArgs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "scoreSampleNodes and scoreTopKNodes are mutually exclusive")
+}
+
+func TestPreScoreSkipsPriorityClassWithoutWeights(t *testing.T) {
+	nodes, nodeMetrics, _ := newTopKTestObjects(2, func(i int) int64 { return 0 })
+	newPod := func(priority int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test-pod",
+			},
+			Spec: corev1.PodSpec{
+				Priority: pointer.Int32(priority),
+			},
+		}
+	}
+	tests := []struct {
+		name          string
+		pod           *corev1.Pod
+		wantSkipScore bool
+	}{
+		{
+			name: "prod pod is scored with the prod weights",
+			pod:  newPod(extension.PriorityProdValueMax),
+		},
+		{
+			name: "pod without priority class is scored with the prod weights",
+			pod:  &corev1.Pod{},
+		},
+		{
+			name:          "mid pod skips scoring without the mid weights",
+			pod:           newPod(extension.PriorityMidValueMax),
+			wantSkipScore: true,
+		},
+		{
+			name:          "batch pod skips scoring with the empty batch weights",
+			pod:           newPod(extension.PriorityBatchValueMax),
+			wantSkipScore: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				PriorityClassResourceWeights: map[extension.PriorityClass]map[corev1.ResourceName]int64{
+					extension.PriorityProd: {
+						corev1.ResourceCPU:    1,
+						corev1.ResourceMemory: 1,
+					},
+					extension.PriorityBatch: {},
+				},
+			}, nodes, nodeMetrics, nil)
+
+			cycleState := framework.NewCycleState()
+			status := p.PreScore(context.TODO(), cycleState, tt.pod, nodes)
+			assert.True(t, status.IsSuccess())
+			s := getStateData(cycleState)
+			assert.Equal(t, tt.wantSkipScore, s != nil && s.skipScore)
+
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), cycleState, tt.pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				if tt.wantSkipScore {
+					assert.Equal(t, int64(0), score)
+				} else {
+					assert.Greater(t, score, int64(0))
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkScoreTopKNodes(b *testing.B) {
+	nodes, nodeMetrics, _ := newTopKTestObjects(5000, func(i int) int64 { return 0 })
+	for _, scoreTopKNodes := range []int64{0, 100} {
+		b.Run(fmt.Sprintf("scoreTopKNodes=%d", scoreTopKNodes), func(b *testing.B) {
+			p, _ := newPluginForTest(b, &v1beta2.LoadAwareSchedulingArgs{
+				ScoreTopKNodes: scoreTopKNodes,
+			}, nodes, nodeMetrics, nil)
+			pod := &corev1.Pod{}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cycleState := framework.NewCycleState()
+				p.PreScore(context.TODO(), cycleState, pod, nodes)
+				for _, node := range nodes {
+					p.Score(context.TODO(), cycleState, pod, node.Name)
+				}
+			}
+		})
+	}
+}
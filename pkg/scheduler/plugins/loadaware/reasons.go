@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// ReasonCode is the machine-readable code of the reason why Filter rejects a node or Score scores a node 0.
+type ReasonCode string
+
+const (
+	ReasonCodeNodeMetricExpired              ReasonCode = "NodeMetricExpired"
+	ReasonCodeNodeMetricNotFound             ReasonCode = "NodeMetricNotFound"
+	ReasonCodeUsageExceedThreshold           ReasonCode = "UsageExceedThreshold"
+	ReasonCodeAggregatedUsageExceedThreshold ReasonCode = "AggregatedUsageExceedThreshold"
+	ReasonCodeCombinedUsageExceedThreshold   ReasonCode = "CombinedUsageExceedThreshold"
+)
+
+// The codes only used by Score.
+const (
+	ReasonCodeNodeNotFound                    ReasonCode = "NodeNotFound"
+	ReasonCodeEstimateFailed                  ReasonCode = "EstimateFailed"
+	ReasonCodeCriticalResourceExhausted       ReasonCode = "CriticalResourceExhausted"
+	ReasonCodeEstimatedUsageExceedAllocatable ReasonCode = "EstimatedUsageExceedAllocatable"
+	ReasonCodeNotNominatedNode                ReasonCode = "NotNominatedNode"
+	ReasonCodeNodeMetricTimeout               ReasonCode = "NodeMetricTimeout"
+)
+
+// reasonMessageFormats defines the human-readable message of each ReasonCode.
+var reasonMessageFormats = map[ReasonCode]string{
+	ReasonCodeNodeMetricExpired:              ErrReasonNodeMetricExpired,
+	ReasonCodeNodeMetricNotFound:             ErrReasonNodeMetricNotFound,
+	ReasonCodeCombinedUsageExceedThreshold:   ErrReasonCombinedUsageExceedThreshold,
+	ReasonCodeAggregatedUsageExceedThreshold: ErrReasonAggregatedUsageExceedThreshold,
+	ReasonCodeUsageExceedThreshold:           ErrReasonUsageExceedThreshold,
+}
+
+// Reason describes why Filter rejects a node or Score scores a node 0.
+type Reason struct {
+	Code ReasonCode
+	// ResourceName is the resource that causes the rejection or the zero score,
+	// empty if the reason is not related to resources.
+	ResourceName corev1.ResourceName
+}
+
+// Message returns the human-readable message of the reason.
+func (r Reason) Message() string {
+	format, ok := reasonMessageFormats[r.Code]
+	if !ok {
+		return string(r.Code)
+	}
+	if strings.Contains(format, "%s") {
+		return fmt.Sprintf(format, r.ResourceName)
+	}
+	return format
+}
+
+// newUnschedulableStatus returns the status with the message of the reason. The consumers match the reasons
+// recorded in the cycle state by node, e.g. GetFilterReasons, rather than parsing the messages of the statuses.
+func newUnschedulableStatus(reason Reason) *framework.Status {
+	return framework.NewStatus(framework.Unschedulable, reason.Message())
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestReasonMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		reason      Reason
+		wantMessage string
+	}{
+		{
+			name:        "nodeMetric expired",
+			reason:      Reason{Code: ReasonCodeNodeMetricExpired},
+			wantMessage: ErrReasonNodeMetricExpired,
+		},
+		{
+			name:        "nodeMetric not found",
+			reason:      Reason{Code: ReasonCodeNodeMetricNotFound},
+			wantMessage: ErrReasonNodeMetricNotFound,
+		},
+		{
+			name:        "cpu usage exceed threshold",
+			reason:      Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU},
+			wantMessage: fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU),
+		},
+		{
+			name:        "memory aggregated usage exceed threshold",
+			reason:      Reason{Code: ReasonCodeAggregatedUsageExceedThreshold, ResourceName: corev1.ResourceMemory},
+			wantMessage: fmt.Sprintf(ErrReasonAggregatedUsageExceedThreshold, corev1.ResourceMemory),
+		},
+		{
+			name:        "combined usage exceed threshold",
+			reason:      Reason{Code: ReasonCodeCombinedUsageExceedThreshold},
+			wantMessage: ErrReasonCombinedUsageExceedThreshold,
+		},
+		{
+			name:        "code without message format",
+			reason:      Reason{Code: ReasonCodeNodeNotFound},
+			wantMessage: string(ReasonCodeNodeNotFound),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantMessage, tt.reason.Message())
+			status := newUnschedulableStatus(tt.reason)
+			assert.Equal(t, framework.Unschedulable, status.Code())
+			assert.Equal(t, tt.wantMessage, status.Message())
+		})
+	}
+}
+
+func TestFilterReasonCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		aggregated *v1beta2.LoadAwareSchedulingAggregatedArgs
+		updateTime time.Time
+		wantReason Reason
+	}{
+		{
+			name:       "filter expired nodeMetric",
+			updateTime: time.Now().Add(-180 * time.Second),
+			wantReason: Reason{Code: ReasonCodeNodeMetricExpired},
+		},
+		{
+			name:       "filter exceed cpu usage",
+			updateTime: time.Now(),
+			wantReason: Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU},
+		},
+		{
+			name: "filter exceed p95 cpu usage",
+			aggregated: &v1beta2.LoadAwareSchedulingAggregatedArgs{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 60,
+				},
+				UsageAggregationType: slov1alpha1.P95,
+			},
+			updateTime: time.Now(),
+			wantReason: Reason{Code: ReasonCodeAggregatedUsageExceedThreshold, ResourceName: corev1.ResourceCPU},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.Aggregated = tt.aggregated
+			nodes := []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node-1",
+					},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("96"),
+							corev1.ResourceMemory: resource.MustParse("512Gi"),
+						},
+					},
+				},
+			}
+			usage := slov1alpha1.ResourceMap{
+				ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("70"),
+					corev1.ResourceMemory: resource.MustParse("256Gi"),
+				},
+			}
+			nodeMetrics := []*slov1alpha1.NodeMetric{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node-1",
+					},
+					Status: slov1alpha1.NodeMetricStatus{
+						UpdateTime: &metav1.Time{
+							Time: tt.updateTime,
+						},
+						NodeMetric: &slov1alpha1.NodeMetricInfo{
+							NodeUsage: usage,
+							AggregatedNodeUsages: []slov1alpha1.AggregatedUsage{
+								{
+									Usage: map[slov1alpha1.AggregationType]slov1alpha1.ResourceMap{
+										slov1alpha1.P95: usage,
+									},
+									Duration: metav1.Duration{Duration: 5 * time.Minute},
+								},
+							},
+						},
+					},
+				},
+			}
+			p, snapshot := newPluginForTest(t, &v1beta2args, nodes, nodeMetrics, nil)
+			nodeInfo, err := snapshot.Get("test-node-1")
+			assert.NoError(t, err)
+
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreFilter(context.TODO(), cycleState, &corev1.Pod{}).IsSuccess())
+			status := p.Filter(context.TODO(), cycleState, &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, newUnschedulableStatus(tt.wantReason), status)
+			assert.Equal(t, map[string]Reason{"test-node-1": tt.wantReason}, GetFilterReasons(cycleState))
+		})
+	}
+}
+
+func TestFilterReasonsIdenticalAcrossNodes(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	// every node exceeds the thresholds of both cpu and memory by different usages.
+	for i := 0; i < 10; i++ {
+		nodeName := fmt.Sprintf("test-node-%d", i+1)
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(int64(90+i), resource.DecimalSI),
+							corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dGi", 99-i)),
+						},
+					},
+				},
+			},
+		})
+	}
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		UsageThresholds: map[corev1.ResourceName]int64{
+			corev1.ResourceCPU:    65,
+			corev1.ResourceMemory: 85,
+		},
+	}, nodes, nodeMetrics, nil)
+
+	messages := map[string]int{}
+	for round := 0; round < 10; round++ {
+		for _, node := range nodes {
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, framework.Unschedulable, status.Code())
+			messages[status.Message()]++
+		}
+	}
+	assert.Equal(t, map[string]int{fmt.Sprintf(ErrReasonUsageExceedThreshold, corev1.ResourceCPU): 100}, messages)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/indexer"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+// estimatedReservationUsed returns the estimated usage of the active Reservations on the node as the virtual Pods
+// assigned to the node. The resources allocated to the owner Pods are excluded, because the owner Pods are
+// estimated as the assigned Pods or reported in NodeMetric.
+func (p *Plugin) estimatedReservationUsed(args *loadAwareArgs, nodeName string, filterProdPod bool) map[corev1.ResourceName]int64 {
+	if p.reservationIndexer == nil {
+		return nil
+	}
+	objs, err := p.reservationIndexer.ByIndex(indexer.ReservationStatusNodeNameIndex, nodeName)
+	if err != nil {
+		klog.V(5).ErrorS(err, "failed to list Reservations on node", "node", nodeName)
+		return nil
+	}
+	estimatedUsed := make(map[corev1.ResourceName]int64)
+	for _, obj := range objs {
+		r, ok := obj.(*schedulingv1alpha1.Reservation)
+		if !ok || !reservationutil.IsReservationActive(r) {
+			continue
+		}
+		reservePod := reservationutil.NewReservePod(r)
+		if filterProdPod && extension.GetPriorityClass(reservePod) != extension.PriorityProd {
+			continue
+		}
+		estimated, err := args.estimator.Estimate(reservePod)
+		if err != nil {
+			continue
+		}
+		for resourceName, value := range estimatedUsedByRequests(args.ResourceWeights, estimated, reservePod) {
+			estimated[resourceName] = value
+		}
+		for resourceName, value := range estimated {
+			if quantity, ok := r.Status.Allocated[resourceName]; ok {
+				value -= getResourceValue(resourceName, quantity)
+			}
+			if value > 0 {
+				estimatedUsed[resourceName] += value
+			}
+		}
+	}
+	return estimatedUsed
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestScoreWithReservations(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("20"),
+						corev1.ResourceMemory: resource.MustParse("20Gi"),
+					},
+				},
+			},
+		},
+	}
+	newReservation := func(phase schedulingv1alpha1.ReservationPhase, nodeName string, allocated corev1.ResourceList) *schedulingv1alpha1.Reservation {
+		return &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-reservation",
+				UID:  "test-reservation",
+			},
+			Spec: schedulingv1alpha1.ReservationSpec{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "main",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("40"),
+										corev1.ResourceMemory: resource.MustParse("40Gi"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase:     phase,
+				NodeName:  nodeName,
+				Allocated: allocated,
+			},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		estimateReservations *bool
+		reservation          *schedulingv1alpha1.Reservation
+		wantScore            int64
+	}{
+		{
+			name:      "no reservation",
+			wantScore: 79,
+		},
+		{
+			name:        "reservation ignored if not enabled",
+			reservation: newReservation(schedulingv1alpha1.ReservationAvailable, "test-node-1", nil),
+			wantScore:   79,
+		},
+		{
+			name:                 "available reservation lowers the score",
+			estimateReservations: pointer.Bool(true),
+			reservation:          newReservation(schedulingv1alpha1.ReservationAvailable, "test-node-1", nil),
+			wantScore:            48,
+		},
+		{
+			name:                 "part allocated to the owner pods is excluded",
+			estimateReservations: pointer.Bool(true),
+			reservation: newReservation(schedulingv1alpha1.ReservationAvailable, "test-node-1", corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("20"),
+				corev1.ResourceMemory: resource.MustParse("20Gi"),
+			}),
+			wantScore: 68,
+		},
+		{
+			name:                 "pending reservation is not counted",
+			estimateReservations: pointer.Bool(true),
+			reservation:          newReservation(schedulingv1alpha1.ReservationPending, "", nil),
+			wantScore:            79,
+		},
+		{
+			name:                 "succeeded reservation is not counted",
+			estimateReservations: pointer.Bool(true),
+			reservation:          newReservation(schedulingv1alpha1.ReservationSucceeded, "test-node-1", nil),
+			wantScore:            79,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				EstimateReservations: tt.estimateReservations,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+			if tt.reservation != nil && p.reservationIndexer != nil {
+				assert.NoError(t, p.reservationIndexer.Add(tt.reservation))
+			}
+			score, status := p.Score(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, node.Name)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
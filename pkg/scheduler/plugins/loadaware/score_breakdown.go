@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// newScoreBreakdown builds the breakdown of the score of a node, which only includes
+// the weighted resources to keep the annotation small.
+func newScoreBreakdown(args *config.LoadAwareSchedulingArgs, score int64, detail *nodeScoreDetail) *extension.LoadAwareScoreBreakdown {
+	breakdown := &extension.LoadAwareScoreBreakdown{
+		Score:     score,
+		Resources: make(map[corev1.ResourceName]extension.LoadAwareResourceScore, len(args.ResourceWeights)),
+	}
+	for resourceName := range args.ResourceWeights {
+		scorer := resourceScorerFor(args, resourceName)
+		breakdown.Resources[resourceName] = extension.LoadAwareResourceScore{
+			Used:        detail.estimatedUsed[resourceName],
+			Allocatable: detail.allocatable[resourceName],
+			Score:       scorer(detail.podEstimatedUsed[resourceName], detail.estimatedUsed[resourceName], detail.allocatable[resourceName]),
+		}
+	}
+	return breakdown
+}
+
+func recordScoreBreakdown(cycleState *framework.CycleState, nodeName string, breakdown *extension.LoadAwareScoreBreakdown) {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.breakdowns[nodeName] = breakdown
+}
+
+func getScoreBreakdown(cycleState *framework.CycleState, nodeName string) *extension.LoadAwareScoreBreakdown {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.breakdowns[nodeName]
+}
+
+// PreBind records the score breakdown of the node in the annotation of the Pod if RecordScoreBreakdown is enabled,
+// logs the load profile of the node if LogBindLoadProfile is enabled, and records the scoring decision
+// if ScoringDecisionRecordDir is set.
+// Failing to record the breakdown does not fail the binding because the breakdown is only for the analysis.
+func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	args := p.getArgs()
+	if args.LogBindLoadProfile {
+		p.logBindLoadProfile(args, pod, nodeName)
+	}
+	if args.ScoringDecisionRecordDir != "" {
+		p.recordScoringDecision(args.LoadAwareSchedulingArgs, cycleState, pod)
+	}
+	if !args.RecordScoreBreakdown {
+		return nil
+	}
+	breakdown := getScoreBreakdown(cycleState, nodeName)
+	if breakdown == nil {
+		return nil
+	}
+
+	newPod := pod.DeepCopy()
+	if err := extension.SetLoadAwareScoreBreakdown(newPod, breakdown); err != nil {
+		klog.V(4).ErrorS(err, "Failed to marshal LoadAwareScheduling score breakdown", "pod", klog.KObj(pod), "node", nodeName)
+		return nil
+	}
+	err := util.RetryOnConflictOrTooManyRequests(func() error {
+		_, err := util.NewPatch().WithClientset(p.handle.ClientSet()).AddAnnotations(newPod.Annotations).PatchPod(ctx, pod)
+		return err
+	})
+	if err != nil {
+		klog.V(4).ErrorS(err, "Failed to patch pod for LoadAwareScheduling score breakdown", "pod", klog.KObj(pod), "node", nodeName)
+		return nil
+	}
+	return nil
+}
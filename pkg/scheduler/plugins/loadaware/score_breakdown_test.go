@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestPreBindScoreBreakdown(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("32"),
+				corev1.ResourceMemory: resource.MustParse("64Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: time.Now(),
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("16"),
+						corev1.ResourceMemory: resource.MustParse("16Gi"),
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                 string
+		recordScoreBreakdown bool
+		skipScore            bool
+		want                 *extension.LoadAwareScoreBreakdown
+	}{
+		{
+			name: "not enabled",
+		},
+		{
+			name:                 "node not scored",
+			recordScoreBreakdown: true,
+			skipScore:            true,
+		},
+		{
+			name:                 "record the breakdown of weighted resources",
+			recordScoreBreakdown: true,
+			want: &extension.LoadAwareScoreBreakdown{
+				Score: 62,
+				Resources: map[corev1.ResourceName]extension.LoadAwareResourceScore{
+					corev1.ResourceCPU: {
+						Used:        16250,
+						Allocatable: 32000,
+						Score:       49,
+					},
+					corev1.ResourceMemory: {
+						Used:        17389584384,
+						Allocatable: 68719476736,
+						Score:       74,
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+				},
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				RecordScoreBreakdown: pointer.Bool(tt.recordScoreBreakdown),
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, []*corev1.Pod{pod})
+
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreScore(context.TODO(), cycleState, pod, []*corev1.Node{node}).IsSuccess())
+			if !tt.skipScore {
+				_, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+				assert.True(t, status.IsSuccess())
+			}
+			assert.True(t, p.PreBind(context.TODO(), cycleState, pod, node.Name).IsSuccess())
+
+			got, err := p.handle.ClientSet().CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			breakdown, err := extension.GetLoadAwareScoreBreakdown(got.Annotations)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, breakdown)
+			if tt.want != nil {
+				var raw map[string]map[string]interface{}
+				data := got.Annotations[extension.AnnotationLoadAwareScoreBreakdown]
+				assert.NoError(t, json.Unmarshal([]byte(data), &struct {
+					Resources *map[string]map[string]interface{} `json:"resources"`
+				}{Resources: &raw}))
+				assert.Len(t, raw, 2, "only the weighted resources are recorded")
+				for _, resourceScore := range raw {
+					assert.Len(t, resourceScore, 3)
+					assert.Contains(t, resourceScore, "used")
+					assert.Contains(t, resourceScore, "allocatable")
+					assert.Contains(t, resourceScore, "score")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+const (
+	scoreStateKey = "Score" + Name
+)
+
+// scoreState records the reasons of the nodes scored 0 by Score, which run in parallel,
+// the score breakdowns of the nodes if RecordScoreBreakdown is enabled,
+// and the scores, the nodes and the NodeMetrics read by Score if ScoringDecisionRecordDir is set,
+// which are recorded in PreBind without reading the snapshot or the NodeMetrics out of the scheduling cycle.
+type scoreState struct {
+	lock        sync.Mutex
+	reasons     map[string]Reason
+	breakdowns  map[string]*extension.LoadAwareScoreBreakdown
+	scores      map[string]int64
+	nodes       map[string]*corev1.Node
+	nodeMetrics map[string]*slov1alpha1.NodeMetric
+	// details are the details behind the scores of the nodes, which are only recorded if it is not nil.
+	details map[string]*nodeScoreDetail
+}
+
+func newScoreState() *scoreState {
+	return &scoreState{
+		reasons:     map[string]Reason{},
+		breakdowns:  map[string]*extension.LoadAwareScoreBreakdown{},
+		scores:      map[string]int64{},
+		nodes:       map[string]*corev1.Node{},
+		nodeMetrics: map[string]*slov1alpha1.NodeMetric{},
+	}
+}
+
+func (s *scoreState) Clone() framework.StateData {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	reasons := make(map[string]Reason, len(s.reasons))
+	for nodeName, reason := range s.reasons {
+		reasons[nodeName] = reason
+	}
+	breakdowns := make(map[string]*extension.LoadAwareScoreBreakdown, len(s.breakdowns))
+	for nodeName, breakdown := range s.breakdowns {
+		breakdowns[nodeName] = breakdown
+	}
+	scores := make(map[string]int64, len(s.scores))
+	for nodeName, score := range s.scores {
+		scores[nodeName] = score
+	}
+	nodes := make(map[string]*corev1.Node, len(s.nodes))
+	for nodeName, node := range s.nodes {
+		nodes[nodeName] = node
+	}
+	nodeMetrics := make(map[string]*slov1alpha1.NodeMetric, len(s.nodeMetrics))
+	for nodeName, nodeMetric := range s.nodeMetrics {
+		nodeMetrics[nodeName] = nodeMetric
+	}
+	var details map[string]*nodeScoreDetail
+	if s.details != nil {
+		details = make(map[string]*nodeScoreDetail, len(s.details))
+		for nodeName, detail := range s.details {
+			details[nodeName] = detail
+		}
+	}
+	return &scoreState{reasons: reasons, breakdowns: breakdowns, scores: scores, nodes: nodes, nodeMetrics: nodeMetrics, details: details}
+}
+
+func getScoreState(cycleState *framework.CycleState) *scoreState {
+	v, err := cycleState.Read(scoreStateKey)
+	if err != nil {
+		return nil
+	}
+	s, _ := v.(*scoreState)
+	return s
+}
+
+func recordScoreZeroReason(cycleState *framework.CycleState, nodeName string, reason Reason) {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.reasons[nodeName] = reason
+}
+
+func recordScoreDetail(cycleState *framework.CycleState, nodeName string, detail *nodeScoreDetail) {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.details != nil {
+		s.details[nodeName] = detail
+	}
+}
+
+// GetScoreZeroReasons returns the reasons of the nodes scored 0 by Score in the scheduling cycle, keyed by node name.
+// The nodes scored 0 by the utilization without a specific reason are not included.
+// It returns nil if PreScore is not enabled.
+func GetScoreZeroReasons(cycleState *framework.CycleState) map[string]Reason {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return nil
+	}
+	return s.Clone().(*scoreState).reasons
+}
+
+// CountReasonCodes returns the number of nodes of each ReasonCode, e.g. to report how many nodes are scored 0
+// due to expired NodeMetric.
+func CountReasonCodes(reasons map[string]Reason) map[ReasonCode]int {
+	counts := make(map[ReasonCode]int)
+	for _, reason := range reasons {
+		counts[reason.Code]++
+	}
+	return counts
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware/estimator"
+)
+
+type failedEstimator struct {
+	estimator.Estimator
+}
+
+func (e *failedEstimator) Estimate(pod *corev1.Pod) (map[corev1.ResourceName]int64, error) {
+	return nil, errors.New("estimate failed")
+}
+
+func newScoreReasonsTestNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("96"),
+				corev1.ResourceMemory: resource.MustParse("512Gi"),
+			},
+		},
+	}
+}
+
+func newScoreReasonsTestNodeMetric(name string, updateTime time.Time, cpuUsage, memoryUsage string) *slov1alpha1.NodeMetric {
+	return &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{
+				Time: updateTime,
+			},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpuUsage),
+						corev1.ResourceMemory: resource.MustParse(memoryUsage),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestScoreZeroReasons(t *testing.T) {
+	nodes := []*corev1.Node{
+		newScoreReasonsTestNode("test-node-normal"),
+		newScoreReasonsTestNode("test-node-missing-metric"),
+		newScoreReasonsTestNode("test-node-expired-metric"),
+		newScoreReasonsTestNode("test-node-cpu-exhausted"),
+		newScoreReasonsTestNode("test-node-overflow"),
+	}
+	nodeMetrics := []*slov1alpha1.NodeMetric{
+		newScoreReasonsTestNodeMetric("test-node-normal", time.Now(), "10", "10Gi"),
+		newScoreReasonsTestNodeMetric("test-node-expired-metric", time.Now().Add(-180*time.Second), "10", "10Gi"),
+		newScoreReasonsTestNodeMetric("test-node-cpu-exhausted", time.Now(), "96", "10Gi"),
+		newScoreReasonsTestNodeMetric("test-node-overflow", time.Now(), "96", "512Gi"),
+	}
+	tests := []struct {
+		name               string
+		args               *v1beta2.LoadAwareSchedulingArgs
+		failedEstimate     bool
+		nodeNames          []string
+		wantReasons        map[string]Reason
+		wantReasonCodeNums map[ReasonCode]int
+	}{
+		{
+			name: "nodes scored 0 for the reasons",
+			args: &v1beta2.LoadAwareSchedulingArgs{
+				CriticalResources: []corev1.ResourceName{corev1.ResourceCPU},
+			},
+			nodeNames: []string{"test-node-normal", "test-node-missing-metric", "test-node-expired-metric", "test-node-cpu-exhausted", "test-node-deleted"},
+			wantReasons: map[string]Reason{
+				"test-node-missing-metric": {Code: ReasonCodeNodeMetricNotFound},
+				"test-node-expired-metric": {Code: ReasonCodeNodeMetricExpired},
+				"test-node-cpu-exhausted":  {Code: ReasonCodeCriticalResourceExhausted, ResourceName: corev1.ResourceCPU},
+				"test-node-deleted":        {Code: ReasonCodeNodeNotFound},
+			},
+			wantReasonCodeNums: map[ReasonCode]int{
+				ReasonCodeNodeMetricNotFound:        1,
+				ReasonCodeNodeMetricExpired:         1,
+				ReasonCodeCriticalResourceExhausted: 1,
+				ReasonCodeNodeNotFound:              1,
+			},
+		},
+		{
+			name:      "node scored 0 for estimated usage exceeding allocatable",
+			args:      &v1beta2.LoadAwareSchedulingArgs{},
+			nodeNames: []string{"test-node-normal", "test-node-cpu-exhausted", "test-node-overflow"},
+			wantReasons: map[string]Reason{
+				"test-node-overflow": {Code: ReasonCodeEstimatedUsageExceedAllocatable},
+			},
+			wantReasonCodeNums: map[ReasonCode]int{
+				ReasonCodeEstimatedUsageExceedAllocatable: 1,
+			},
+		},
+		{
+			name: "nodes not in top k nodes score neutral without reasons",
+			args: &v1beta2.LoadAwareSchedulingArgs{
+				ScoreTopKNodes: 1,
+			},
+			nodeNames:          []string{"test-node-normal", "test-node-cpu-exhausted", "test-node-overflow"},
+			wantReasons:        map[string]Reason{},
+			wantReasonCodeNums: map[ReasonCode]int{},
+		},
+		{
+			name:           "estimate failed",
+			args:           &v1beta2.LoadAwareSchedulingArgs{},
+			failedEstimate: true,
+			nodeNames:      []string{"test-node-normal"},
+			wantReasons: map[string]Reason{
+				"test-node-normal": {Code: ReasonCodeEstimateFailed},
+			},
+			wantReasonCodeNums: map[ReasonCode]int{
+				ReasonCodeEstimateFailed: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newPluginForTest(t, tt.args, nodes, nodeMetrics, nil)
+			if tt.failedEstimate {
+				args := p.getArgs()
+				p.args.Store(&loadAwareArgs{LoadAwareSchedulingArgs: args.LoadAwareSchedulingArgs, estimator: &failedEstimator{Estimator: args.estimator}})
+			}
+			state := framework.NewCycleState()
+			var scoredNodes []*corev1.Node
+			for _, node := range nodes {
+				for _, nodeName := range tt.nodeNames {
+					if node.Name == nodeName {
+						scoredNodes = append(scoredNodes, node)
+					}
+				}
+			}
+			// the requested of the nodes in the snapshot are all 0, so the top k nodes are selected by name.
+			status := p.PreScore(context.TODO(), state, &corev1.Pod{}, scoredNodes)
+			assert.True(t, status.IsSuccess())
+			for _, nodeName := range tt.nodeNames {
+				_, status := p.Score(context.TODO(), state, &corev1.Pod{}, nodeName)
+				assert.True(t, status.IsSuccess())
+			}
+			reasons := GetScoreZeroReasons(state)
+			assert.Equal(t, tt.wantReasons, reasons)
+			assert.Equal(t, tt.wantReasonCodeNums, CountReasonCodes(reasons))
+		})
+	}
+}
+
+func TestScoreZeroReasonsWithoutPreScore(t *testing.T) {
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, nil, nil, nil)
+	state := framework.NewCycleState()
+	score, status := p.Score(context.TODO(), state, &corev1.Pod{}, "test-node-deleted")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(0), score)
+	assert.Nil(t, GetScoreZeroReasons(state))
+}
@@ -0,0 +1,491 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	configlisters "github.com/koordinator-sh/koordinator/pkg/client/listers/config/v1alpha1"
+	slolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware/estimator"
+)
+
+// ScoringDecision is the inputs and the scores of scoring a Pod, which is recorded if ScoringDecisionRecordDir is set
+// and replayed by ReplayScoringDecision to find the nodes scored differently by the changes of the scoring.
+type ScoringDecision struct {
+	// RecordTime is when the decision is recorded, and the replay keeps the ages of the NodeMetrics
+	// and the assigned Pods at the time.
+	RecordTime metav1.MicroTime `json:"recordTime"`
+	Pod        *corev1.Pod      `json:"pod"`
+	// Nodes are the nodes scored by the plugin.
+	Nodes []*corev1.Node `json:"nodes"`
+	// NodeMetrics are the NodeMetrics of the nodes, missing for the nodes without NodeMetric.
+	NodeMetrics []*slov1alpha1.NodeMetric `json:"nodeMetrics,omitempty"`
+	// Pods are the Pods assigned to the nodes in the plugin and the Pods reported in the NodeMetrics.
+	Pods []RecordedPod `json:"pods,omitempty"`
+	// Scores are the scores of the nodes returned by Score, keyed by node name.
+	Scores map[string]int64 `json:"scores"`
+}
+
+// RecordedPod is a Pod read by the scoring of the recorded decision.
+type RecordedPod struct {
+	Pod *corev1.Pod `json:"pod"`
+	// NodeName and AssignTime are where and when the Pod is assigned in the plugin,
+	// and they are empty if the Pod is only reported in the NodeMetrics.
+	NodeName   string            `json:"nodeName,omitempty"`
+	AssignTime *metav1.MicroTime `json:"assignTime,omitempty"`
+}
+
+// ScoreDiff is a node scored differently in the replay from the recorded decision.
+type ScoreDiff struct {
+	NodeName      string `json:"nodeName"`
+	RecordedScore int64  `json:"recordedScore"`
+	ReplayedScore int64  `json:"replayedScore"`
+}
+
+func recordDecisionScore(cycleState *framework.CycleState, node *corev1.Node, score int64) {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.scores[node.Name] = score
+	s.nodes[node.Name] = node
+}
+
+func recordDecisionNodeMetric(cycleState *framework.CycleState, nodeMetric *slov1alpha1.NodeMetric) {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.nodeMetrics[nodeMetric.Name] = nodeMetric
+}
+
+// maxScoringDecisionFiles is the number of the latest scoring decisions kept in ScoringDecisionRecordDir.
+const maxScoringDecisionFiles = 1000
+
+// recordScoringDecision writes the scoring decision of the Pod to a file in ScoringDecisionRecordDir in background,
+// which is built of the scores, the nodes and the NodeMetrics captured by Score.
+// Failing to record the decision does not fail the binding because the decision is only for the regression testing.
+func (p *Plugin) recordScoringDecision(args *config.LoadAwareSchedulingArgs, cycleState *framework.CycleState, pod *corev1.Pod) {
+	s := getScoreState(cycleState)
+	if s == nil {
+		return
+	}
+	captured := s.Clone().(*scoreState)
+	if len(captured.scores) == 0 {
+		return
+	}
+	recordTime := metav1.NowMicro()
+	// the Pod is shared with the scheduling cycle, so the fields read by the scoring are copied before going background.
+	recordedPod := copyPodForDecision(pod)
+	go func() {
+		decision := p.newScoringDecision(recordedPod, recordTime, captured)
+		if err := p.scoringDecisionWriter.write(args.ScoringDecisionRecordDir, decision); err != nil {
+			klog.V(4).ErrorS(err, "Failed to record LoadAwareScheduling scoring decision", "pod", klog.KObj(recordedPod))
+		}
+	}()
+}
+
+// newScoringDecision builds the decision of the captured scoring, which only reads the thread-safe caches
+// besides the captured state, so that it is built out of the scheduling cycle.
+func (p *Plugin) newScoringDecision(pod *corev1.Pod, recordTime metav1.MicroTime, captured *scoreState) *ScoringDecision {
+	decision := &ScoringDecision{
+		RecordTime: recordTime,
+		Pod:        pod,
+		Scores:     captured.scores,
+	}
+	nodeNames := make([]string, 0, len(captured.nodes))
+	for nodeName := range captured.nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	recordedPods := sets.NewString()
+	for _, nodeName := range nodeNames {
+		decision.Nodes = append(decision.Nodes, copyNodeForDecision(captured.nodes[nodeName]))
+
+		shard := p.podAssignCache.shardOf(nodeName)
+		shard.lock.RLock()
+		for uid, assignInfo := range shard.podInfoItems[nodeName] {
+			// the Pod itself is assigned in Reserve after scoring.
+			if uid == pod.UID {
+				continue
+			}
+			recordedPods.Insert(getPodNamespacedName(assignInfo.pod.Namespace, assignInfo.pod.Name))
+			assignTime := metav1.NewMicroTime(assignInfo.timestamp)
+			decision.Pods = append(decision.Pods, RecordedPod{Pod: copyPodForDecision(assignInfo.pod), NodeName: nodeName, AssignTime: &assignTime})
+		}
+		shard.lock.RUnlock()
+
+		nodeMetric, ok := captured.nodeMetrics[nodeName]
+		if !ok {
+			continue
+		}
+		decision.NodeMetrics = append(decision.NodeMetrics, copyNodeMetricForDecision(nodeMetric))
+		for _, podMetric := range nodeMetric.Status.PodsMetric {
+			podName := getPodNamespacedName(podMetric.Namespace, podMetric.Name)
+			if recordedPods.Has(podName) {
+				continue
+			}
+			if reportedPod, err := p.podLister.Pods(podMetric.Namespace).Get(podMetric.Name); err == nil {
+				recordedPods.Insert(podName)
+				decision.Pods = append(decision.Pods, RecordedPod{Pod: copyPodForDecision(reportedPod)})
+			}
+		}
+	}
+	return decision
+}
+
+// copyPodForDecision copies the fields of the Pod read by the scoring, leaving out the rest, e.g. the managed fields.
+func copyPodForDecision(pod *corev1.Pod) *corev1.Pod {
+	copied := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         pod.Namespace,
+			Name:              pod.Name,
+			UID:               pod.UID,
+			CreationTimestamp: pod.CreationTimestamp,
+			Labels:            pod.Labels,
+			Annotations:       pod.Annotations,
+			OwnerReferences:   pod.OwnerReferences,
+		},
+		Spec: pod.Spec,
+		Status: corev1.PodStatus{
+			Phase: pod.Status.Phase,
+		},
+	}
+	return copied.DeepCopy()
+}
+
+// copyNodeForDecision copies the fields of the node read by the scoring.
+func copyNodeForDecision(node *corev1.Node) *corev1.Node {
+	copied := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        node.Name,
+			Labels:      node.Labels,
+			Annotations: node.Annotations,
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    node.Status.Capacity,
+			Allocatable: node.Status.Allocatable,
+		},
+	}
+	return copied.DeepCopy()
+}
+
+// copyNodeMetricForDecision copies the fields of the NodeMetric read by the scoring.
+func copyNodeMetricForDecision(nodeMetric *slov1alpha1.NodeMetric) *slov1alpha1.NodeMetric {
+	copied := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nodeMetric.Name,
+			Annotations: nodeMetric.Annotations,
+		},
+		Spec:   nodeMetric.Spec,
+		Status: nodeMetric.Status,
+	}
+	return copied.DeepCopy()
+}
+
+// scoringDecisionWriter writes the scoring decisions to the files in the record dir,
+// and removes the oldest files to keep at most maxFiles of them.
+type scoringDecisionWriter struct {
+	lock     sync.Mutex
+	maxFiles int
+	dir      string
+	// files are the names of the decision files in dir from the oldest.
+	files []string
+}
+
+func newScoringDecisionWriter(maxFiles int) *scoringDecisionWriter {
+	return &scoringDecisionWriter{maxFiles: maxFiles}
+}
+
+func (w *scoringDecisionWriter) write(dir string, decision *ScoringDecision) error {
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	// the files left in the dir, e.g. by the previous runs, are counted when the dir is written the first time.
+	if w.dir != dir {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		files, err := listScoringDecisionFiles(dir)
+		if err != nil {
+			return err
+		}
+		w.dir, w.files = dir, files
+	}
+	fileName := fmt.Sprintf("%s_%s_%s.json", decision.Pod.Namespace, decision.Pod.Name, decision.Pod.UID)
+	if err := os.WriteFile(filepath.Join(dir, fileName), data, 0644); err != nil {
+		return err
+	}
+	for i, name := range w.files {
+		if name == fileName {
+			w.files = append(w.files[:i], w.files[i+1:]...)
+			break
+		}
+	}
+	w.files = append(w.files, fileName)
+	for len(w.files) > w.maxFiles {
+		if err := os.Remove(filepath.Join(dir, w.files[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		w.files = w.files[1:]
+	}
+	return nil
+}
+
+// listScoringDecisionFiles returns the names of the decision files in the dir from the oldest.
+func listScoringDecisionFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	modTimes := map[string]time.Time{}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, entry.Name())
+		modTimes[entry.Name()] = info.ModTime()
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return modTimes[files[i]].Before(modTimes[files[j]])
+	})
+	return files, nil
+}
+
+// ReadScoringDecision reads the scoring decision recorded in the file.
+func ReadScoringDecision(path string) (*ScoringDecision, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decision := &ScoringDecision{}
+	if err := json.Unmarshal(data, decision); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// ReplayScoringDecision runs PreScore and Score of the current code with the args over the recorded decision,
+// and returns the nodes scored differently from the recording. The times in the decision are shifted by
+// the time elapsed since the recording, so that the NodeMetrics and the assigned Pods are as old as they were.
+func ReplayScoringDecision(ctx context.Context, args *config.LoadAwareSchedulingArgs, decision *ScoringDecision) ([]ScoreDiff, error) {
+	if err := validation.ValidateLoadAwareSchedulingArgs(args); err != nil {
+		return nil, err
+	}
+	decision = shiftScoringDecision(decision, time.Since(decision.RecordTime.Time))
+	p, err := newReplayPlugin(args, decision)
+	if err != nil {
+		return nil, err
+	}
+
+	cycleState := framework.NewCycleState()
+	if status := p.PreScore(ctx, cycleState, decision.Pod, decision.Nodes); !status.IsSuccess() {
+		return nil, status.AsError()
+	}
+	var diffs []ScoreDiff
+	for _, node := range decision.Nodes {
+		recordedScore, ok := decision.Scores[node.Name]
+		if !ok {
+			continue
+		}
+		score, status := p.Score(ctx, cycleState, decision.Pod, node.Name)
+		if !status.IsSuccess() {
+			return nil, status.AsError()
+		}
+		if score != recordedScore {
+			diffs = append(diffs, ScoreDiff{NodeName: node.Name, RecordedScore: recordedScore, ReplayedScore: score})
+		}
+	}
+	return diffs, nil
+}
+
+func shiftScoringDecision(decision *ScoringDecision, elapsed time.Duration) *ScoringDecision {
+	shifted := *decision
+	shifted.NodeMetrics = make([]*slov1alpha1.NodeMetric, 0, len(decision.NodeMetrics))
+	for _, nodeMetric := range decision.NodeMetrics {
+		nodeMetric = nodeMetric.DeepCopy()
+		if nodeMetric.Status.UpdateTime != nil {
+			nodeMetric.Status.UpdateTime = &metav1.Time{Time: nodeMetric.Status.UpdateTime.Add(elapsed)}
+		}
+		shifted.NodeMetrics = append(shifted.NodeMetrics, nodeMetric)
+	}
+	shifted.Pods = make([]RecordedPod, 0, len(decision.Pods))
+	for _, recordedPod := range decision.Pods {
+		if recordedPod.AssignTime != nil {
+			assignTime := metav1.NewMicroTime(recordedPod.AssignTime.Add(elapsed))
+			recordedPod.AssignTime = &assignTime
+		}
+		shifted.Pods = append(shifted.Pods, recordedPod)
+	}
+	return &shifted
+}
+
+// newReplayPlugin builds the plugin reading the recorded decision rather than the cluster.
+func newReplayPlugin(args *config.LoadAwareSchedulingArgs, decision *ScoringDecision) (*Plugin, error) {
+	var assignedPods []*corev1.Pod
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, recordedPod := range decision.Pods {
+		if err := podIndexer.Add(recordedPod.Pod); err != nil {
+			return nil, err
+		}
+		if recordedPod.NodeName != "" {
+			pod := recordedPod.Pod.DeepCopy()
+			pod.Spec.NodeName = recordedPod.NodeName
+			assignedPods = append(assignedPods, pod)
+		}
+	}
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, node := range decision.Nodes {
+		if err := nodeIndexer.Add(node); err != nil {
+			return nil, err
+		}
+	}
+	nodeMetricIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, nodeMetric := range decision.NodeMetrics {
+		if err := nodeMetricIndexer.Add(nodeMetric); err != nil {
+			return nil, err
+		}
+	}
+	handle, err := frameworkruntime.NewFramework(nil, nil,
+		frameworkruntime.WithSnapshotSharedLister(newReplaySnapshot(decision.Nodes, assignedPods)))
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveArgs := withoutNonPoolableResourceWeights(args)
+	estimator, err := estimator.NewEstimator(effectiveArgs, handle)
+	if err != nil {
+		return nil, err
+	}
+	assignCache := newPodAssignCache()
+	assignCache.setEstimator(estimator)
+	for _, recordedPod := range decision.Pods {
+		if recordedPod.NodeName == "" || recordedPod.AssignTime == nil {
+			continue
+		}
+		shard := assignCache.shardOf(recordedPod.NodeName)
+		m := shard.podInfoItems[recordedPod.NodeName]
+		if m == nil {
+			m = make(map[types.UID]*podAssignInfo)
+			shard.podInfoItems[recordedPod.NodeName] = m
+		}
+		assignInfo := &podAssignInfo{
+			timestamp: recordedPod.AssignTime.Time,
+			pod:       recordedPod.Pod,
+		}
+		assignInfo.estimate(shard.estimator)
+		m[recordedPod.Pod.UID] = assignInfo
+	}
+
+	p := &Plugin{
+		handle:                     handle,
+		podLister:                  corev1listers.NewPodLister(podIndexer),
+		nodeMetricLister:           slolisters.NewNodeMetricLister(nodeMetricIndexer),
+		nodeLister:                 corev1listers.NewNodeLister(nodeIndexer),
+		usageThresholdPolicyLister: configlisters.NewClusterUsageThresholdPolicyLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		podAssignCache:             assignCache,
+		dynamicResourceWeights:     newDynamicResourceWeights(),
+		staticArgs:                 args,
+	}
+	p.args.Store(&loadAwareArgs{LoadAwareSchedulingArgs: effectiveArgs, estimator: estimator})
+	return p, nil
+}
+
+var (
+	_ framework.SharedLister   = &replaySnapshot{}
+	_ framework.NodeInfoLister = &replaySnapshot{}
+)
+
+// replaySnapshot is the snapshot of the recorded nodes and the Pods assigned to them.
+type replaySnapshot struct {
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+func newReplaySnapshot(nodes []*corev1.Node, pods []*corev1.Pod) *replaySnapshot {
+	s := &replaySnapshot{nodeInfoMap: make(map[string]*framework.NodeInfo, len(nodes))}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		s.nodeInfos = append(s.nodeInfos, nodeInfo)
+		s.nodeInfoMap[node.Name] = nodeInfo
+	}
+	for _, pod := range pods {
+		if nodeInfo, ok := s.nodeInfoMap[pod.Spec.NodeName]; ok {
+			nodeInfo.AddPod(pod)
+		}
+	}
+	return s
+}
+
+func (s *replaySnapshot) NodeInfos() framework.NodeInfoLister {
+	return s
+}
+
+func (s *replaySnapshot) List() ([]*framework.NodeInfo, error) {
+	return s.nodeInfos, nil
+}
+
+func (s *replaySnapshot) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (s *replaySnapshot) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (s *replaySnapshot) Get(nodeName string) (*framework.NodeInfo, error) {
+	nodeInfo, ok := s.nodeInfoMap[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("nodeinfo not found for node name %q", nodeName)
+	}
+	return nodeInfo, nil
+}
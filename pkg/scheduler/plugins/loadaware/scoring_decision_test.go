@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestRecordAndReplayScoringDecision(t *testing.T) {
+	var nodes []*corev1.Node
+	var nodeMetrics []*slov1alpha1.NodeMetric
+	for i, usage := range []string{"30", "60"} {
+		nodeName := []string{"test-node-1", "test-node-2"}[i]
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100"),
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				},
+			},
+		})
+		nodeMetrics = append(nodeMetrics, &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Spec: slov1alpha1.NodeMetricSpec{
+				CollectPolicy: &slov1alpha1.NodeMetricCollectPolicy{
+					ReportIntervalSeconds: pointer.Int64(60),
+				},
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now().Add(-10 * time.Second),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(usage),
+							corev1.ResourceMemory: resource.MustParse("40Gi"),
+						},
+					},
+				},
+			},
+		})
+	}
+	assignedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "assigned-pod",
+			UID:       "assigned-pod",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("16Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	recordDir := t.TempDir()
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		ScoringDecisionRecordDir: recordDir,
+	}, nodes, nodeMetrics, []*corev1.Pod{assignedPod})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod",
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl"},
+			},
+		},
+	}
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreScore(context.TODO(), cycleState, pod, nodes).IsSuccess())
+	wantScores := map[string]int64{}
+	for _, node := range nodes {
+		score, status := p.Score(context.TODO(), cycleState, pod, node.Name)
+		assert.True(t, status.IsSuccess())
+		wantScores[node.Name] = score
+	}
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node-1").IsSuccess())
+	assert.True(t, p.PreBind(context.TODO(), cycleState, pod, "test-node-1").IsSuccess())
+
+	// the decision is written in background.
+	var decision *ScoringDecision
+	assert.Eventually(t, func() bool {
+		var err error
+		decision, err = ReadScoringDecision(filepath.Join(recordDir, "default_test-pod_test-pod.json"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "test-pod", decision.Pod.Name)
+	assert.Empty(t, decision.Pod.ManagedFields)
+	assert.Equal(t, wantScores, decision.Scores)
+	assert.Len(t, decision.Nodes, 2)
+	assert.Len(t, decision.NodeMetrics, 2)
+	assert.Len(t, decision.Pods, 1)
+	assert.Equal(t, "assigned-pod", decision.Pods[0].Pod.Name)
+	assert.Equal(t, "test-node-1", decision.Pods[0].NodeName)
+
+	diffs, err := ReplayScoringDecision(context.TODO(), p.staticArgs, decision)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	changedArgs := p.staticArgs.DeepCopy()
+	changedArgs.ResourceWeights = map[corev1.ResourceName]int64{
+		corev1.ResourceCPU: 1,
+	}
+	diffs, err = ReplayScoringDecision(context.TODO(), changedArgs, decision)
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 2)
+	for _, diff := range diffs {
+		assert.Equal(t, wantScores[diff.NodeName], diff.RecordedScore)
+		assert.NotEqual(t, diff.RecordedScore, diff.ReplayedScore)
+	}
+}
+
+func TestScoringDecisionWriter(t *testing.T) {
+	recordDir := t.TempDir()
+	// the file left by the previous run is the oldest.
+	assert.NoError(t, os.WriteFile(filepath.Join(recordDir, "default_left-pod_left-pod.json"), []byte("{}"), 0644))
+
+	w := newScoringDecisionWriter(2)
+	for _, name := range []string{"pod-1", "pod-2", "pod-1"} {
+		decision := &ScoringDecision{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      name,
+					UID:       types.UID(name),
+				},
+			},
+		}
+		assert.NoError(t, w.write(recordDir, decision))
+	}
+	entries, err := os.ReadDir(recordDir)
+	assert.NoError(t, err)
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	assert.Equal(t, []string{"default_pod-1_pod-1.json", "default_pod-2_pod-2.json"}, files)
+	assert.Equal(t, []string{"default_pod-2_pod-2.json", "default_pod-1_pod-1.json"}, w.files)
+
+	assert.NoError(t, w.write(recordDir, &ScoringDecision{
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "pod-3",
+				UID:       "pod-3",
+			},
+		},
+	}))
+	_, err = os.Stat(filepath.Join(recordDir, "default_pod-2_pod-2.json"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, []string{"default_pod-1_pod-1.json", "default_pod-3_pod-3.json"}, w.files)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// maxTrackedUnschedulablePods bounds the Pods tracked by unschedulableAttempts,
+// and the Pods beyond it are not relaxed until the tracked ones are scheduled or deleted.
+const maxTrackedUnschedulablePods = 10000
+
+// unschedulableAttempts counts the scheduling attempts of the pending Pods that are unschedulable
+// because the nodes exceed the usage thresholds, by the UID of the Pods.
+type unschedulableAttempts struct {
+	lock     sync.RWMutex
+	attempts map[types.UID]int64
+}
+
+func newUnschedulableAttempts() *unschedulableAttempts {
+	return &unschedulableAttempts{
+		attempts: map[types.UID]int64{},
+	}
+}
+
+func (a *unschedulableAttempts) increase(uid types.UID) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, ok := a.attempts[uid]; !ok && len(a.attempts) >= maxTrackedUnschedulablePods {
+		return
+	}
+	a.attempts[uid]++
+}
+
+func (a *unschedulableAttempts) get(uid types.UID) int64 {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.attempts[uid]
+}
+
+func (a *unschedulableAttempts) forget(uid types.UID) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	delete(a.attempts, uid)
+}
+
+// registerUnschedulableAttemptsEventHandler forgets the attempts of the Pods deleted while pending.
+func registerUnschedulableAttemptsEventHandler(sharedInformerFactory informers.SharedInformerFactory, attempts *unschedulableAttempts) {
+	podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: attempts.onPodDelete,
+	})
+}
+
+func (a *unschedulableAttempts) onPodDelete(obj interface{}) {
+	var pod *corev1.Pod
+	switch t := obj.(type) {
+	case *corev1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		pod, ok = t.Obj.(*corev1.Pod)
+		if !ok {
+			return
+		}
+	default:
+		return
+	}
+	a.forget(pod.UID)
+}
+
+// thresholdRelaxation returns the percentage points the usage thresholds are relaxed for the Pod.
+func (p *Plugin) thresholdRelaxation(args *loadAwareArgs, pod *corev1.Pod) int64 {
+	if args.ThresholdRelaxationPercentPerAttempt <= 0 {
+		return 0
+	}
+	relaxation := p.unschedulableAttempts.get(pod.UID) * args.ThresholdRelaxationPercentPerAttempt
+	if relaxation > args.MaxThresholdRelaxationPercent {
+		return args.MaxThresholdRelaxationPercent
+	}
+	return relaxation
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestUnschedulableAttempts(t *testing.T) {
+	attempts := newUnschedulableAttempts()
+	attempts.increase("pod-1")
+	attempts.increase("pod-1")
+	assert.Equal(t, int64(2), attempts.get("pod-1"))
+	assert.Equal(t, int64(0), attempts.get("pod-2"))
+
+	attempts.onPodDelete(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "pod-1"}})
+	assert.Equal(t, int64(0), attempts.get("pod-1"))
+
+	for i := 0; i < maxTrackedUnschedulablePods; i++ {
+		attempts.increase(types.UID(strconv.Itoa(i)))
+	}
+	attempts.increase("pod-beyond-the-bound")
+	assert.Equal(t, int64(0), attempts.get("pod-beyond-the-bound"))
+	attempts.increase("0")
+	assert.Equal(t, int64(2), attempts.get("0"))
+}
+
+func TestFilterWithThresholdRelaxation(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}
+	newNodeMetric := func(cpuUsage string) *slov1alpha1.NodeMetric {
+		return &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: node.Name,
+			},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &metav1.Time{
+					Time: time.Now(),
+				},
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpuUsage),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name                string
+		cpuUsage            string
+		mandatoryThresholds map[corev1.ResourceName]int64
+		relaxationPercent   int64
+		// wantFailedAttempts is the number of the failed attempts before the Pod is scheduled,
+		// and -1 means the Pod is never scheduled.
+		wantFailedAttempts int
+	}{
+		{
+			name:               "never scheduled without relaxation",
+			cpuUsage:           "70",
+			wantFailedAttempts: -1,
+		},
+		{
+			name:               "scheduled after the thresholds are relaxed enough",
+			cpuUsage:           "70",
+			relaxationPercent:  5,
+			wantFailedAttempts: 2,
+		},
+		{
+			name:               "never scheduled beyond the bound of the relaxation",
+			cpuUsage:           "90",
+			relaxationPercent:  5,
+			wantFailedAttempts: -1,
+		},
+		{
+			name:     "mandatory thresholds are never relaxed",
+			cpuUsage: "70",
+			mandatoryThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU: 65,
+			},
+			relaxationPercent:  5,
+			wantFailedAttempts: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, snapshot := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+				UsageThresholds: map[corev1.ResourceName]int64{
+					corev1.ResourceCPU: 65,
+				},
+				MandatoryThresholds:                  tt.mandatoryThresholds,
+				ThresholdRelaxationPercentPerAttempt: tt.relaxationPercent,
+				MaxThresholdRelaxationPercent:        20,
+			}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{newNodeMetric(tt.cpuUsage)}, nil)
+			nodeInfo, err := snapshot.Get(node.Name)
+			assert.NoError(t, err)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+					UID:       "test-pod",
+				},
+			}
+			failedAttempts := -1
+			for attempt := 0; attempt < 10; attempt++ {
+				cycleState := framework.NewCycleState()
+				assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+				if status := p.Filter(context.TODO(), cycleState, pod, nodeInfo); status.IsSuccess() {
+					failedAttempts = attempt
+					assert.True(t, p.Reserve(context.TODO(), cycleState, pod, node.Name).IsSuccess())
+					break
+				}
+				_, status := p.PostFilter(context.TODO(), cycleState, pod, nil)
+				assert.Equal(t, framework.Unschedulable, status.Code())
+			}
+			assert.Equal(t, tt.wantFailedAttempts, failedAttempts)
+			// the attempts are forgotten once the Pod is scheduled.
+			if failedAttempts >= 0 {
+				assert.Equal(t, int64(0), p.unschedulableAttempts.get(pod.UID))
+			}
+		})
+	}
+}
+
+func TestReserveForgetsAttemptsAfterRelaxationDisabled(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}
+	p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{
+		ThresholdRelaxationPercentPerAttempt: 5,
+		MaxThresholdRelaxationPercent:        20,
+	}, []*corev1.Node{node}, nil, nil)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod",
+		},
+	}
+	p.recordUnschedulableAttempt(pod)
+	assert.Equal(t, int64(1), p.unschedulableAttempts.get(pod.UID))
+
+	// the relaxation is disabled by the dynamic args before the Pod is scheduled.
+	args := *p.getArgs().LoadAwareSchedulingArgs
+	args.ThresholdRelaxationPercentPerAttempt = 0
+	p.args.Store(&loadAwareArgs{LoadAwareSchedulingArgs: &args, estimator: p.getArgs().estimator})
+
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, node.Name).IsSuccess())
+	assert.Equal(t, int64(0), p.unschedulableAttempts.get(pod.UID))
+}
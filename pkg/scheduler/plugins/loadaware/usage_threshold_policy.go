@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	configv1alpha1 "github.com/koordinator-sh/koordinator/apis/config/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+// generateUsageThresholdsFilterProfile resolves the usage thresholds of the node in the order of
+// the node annotation, the ClusterUsageThresholdPolicy matching the node and the args.
+func (p *Plugin) generateUsageThresholdsFilterProfile(node *corev1.Node, args *config.LoadAwareSchedulingArgs) *usageThresholdsFilterProfile {
+	if policy := p.matchUsageThresholdPolicy(node); policy != nil {
+		args = applyUsageThresholdPolicy(args, policy)
+	}
+	return EffectiveUsageThresholds(node, args)
+}
+
+// matchUsageThresholdPolicy returns the policy with the smallest name among the policies matching the node.
+func (p *Plugin) matchUsageThresholdPolicy(node *corev1.Node) *configv1alpha1.ClusterUsageThresholdPolicy {
+	policies, err := p.usageThresholdPolicyLister.List(labels.Everything())
+	if err != nil {
+		klog.V(5).ErrorS(err, "failed to list ClusterUsageThresholdPolicy")
+		return nil
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+	for _, policy := range policies {
+		if policy.Spec.NodeSelector == nil {
+			return policy
+		}
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NodeSelector)
+		if err != nil {
+			klog.V(5).ErrorS(err, "failed to parse nodeSelector of ClusterUsageThresholdPolicy", "policy", policy.Name)
+			continue
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// applyUsageThresholdPolicy returns a copy of the args with the thresholds set in the policy.
+func applyUsageThresholdPolicy(args *config.LoadAwareSchedulingArgs, policy *configv1alpha1.ClusterUsageThresholdPolicy) *config.LoadAwareSchedulingArgs {
+	policyArgs := *args
+	if len(policy.Spec.UsageThresholds) > 0 {
+		policyArgs.UsageThresholds = policy.Spec.UsageThresholds
+	}
+	if len(policy.Spec.ProdUsageThresholds) > 0 {
+		policyArgs.ProdUsageThresholds = policy.Spec.ProdUsageThresholds
+	}
+	if aggregatedUsage := policy.Spec.AggregatedUsage; aggregatedUsage != nil &&
+		len(aggregatedUsage.UsageThresholds) > 0 && aggregatedUsage.UsageAggregationType != "" {
+		aggregated := config.LoadAwareSchedulingAggregatedArgs{}
+		if args.Aggregated != nil {
+			aggregated = *args.Aggregated
+		}
+		aggregated.UsageThresholds = aggregatedUsage.UsageThresholds
+		aggregated.UsageAggregationType = aggregatedUsage.UsageAggregationType
+		aggregated.UsageAggregatedDuration = metav1.Duration{}
+		if aggregatedUsage.UsageAggregatedDuration != nil {
+			aggregated.UsageAggregatedDuration = *aggregatedUsage.UsageAggregatedDuration
+		}
+		policyArgs.Aggregated = &aggregated
+	}
+	return &policyArgs
+}
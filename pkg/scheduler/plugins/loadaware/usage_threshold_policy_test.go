@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	configv1alpha1 "github.com/koordinator-sh/koordinator/apis/config/v1alpha1"
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	configlisters "github.com/koordinator-sh/koordinator/pkg/client/listers/config/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func newTestUsageThresholdPolicy(name string, nodeSelector *metav1.LabelSelector, cpuThreshold int64) *configv1alpha1.ClusterUsageThresholdPolicy {
+	return &configv1alpha1.ClusterUsageThresholdPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: configv1alpha1.ClusterUsageThresholdPolicySpec{
+			NodeSelector: nodeSelector,
+			UsageThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU: cpuThreshold,
+			},
+		},
+	}
+}
+
+func TestFilterWithUsageThresholdPolicy(t *testing.T) {
+	poolSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"pool": "online"},
+	}
+	tests := []struct {
+		name                  string
+		nodeLabels            map[string]string
+		customUsageThresholds map[corev1.ResourceName]int64
+		policies              []*configv1alpha1.ClusterUsageThresholdPolicy
+		wantStatus            *framework.Status
+	}{
+		{
+			name:       "no policy uses args",
+			wantStatus: newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+		},
+		{
+			name:       "matched policy overrides args",
+			nodeLabels: map[string]string{"pool": "online"},
+			policies: []*configv1alpha1.ClusterUsageThresholdPolicy{
+				newTestUsageThresholdPolicy("online", poolSelector, 80),
+			},
+		},
+		{
+			name:     "policy without nodeSelector matches all nodes",
+			policies: []*configv1alpha1.ClusterUsageThresholdPolicy{newTestUsageThresholdPolicy("all", nil, 80)},
+		},
+		{
+			name:       "unmatched policy falls back to args",
+			nodeLabels: map[string]string{"pool": "offline"},
+			policies: []*configv1alpha1.ClusterUsageThresholdPolicy{
+				newTestUsageThresholdPolicy("online", poolSelector, 80),
+			},
+			wantStatus: newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+		},
+		{
+			name:       "node annotation overrides policy",
+			nodeLabels: map[string]string{"pool": "online"},
+			customUsageThresholds: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU: 70,
+			},
+			policies: []*configv1alpha1.ClusterUsageThresholdPolicy{
+				newTestUsageThresholdPolicy("online", poolSelector, 80),
+			},
+			wantStatus: newUnschedulableStatus(Reason{Code: ReasonCodeUsageExceedThreshold, ResourceName: corev1.ResourceCPU}),
+		},
+		{
+			name:       "policy with the smallest name applies",
+			nodeLabels: map[string]string{"pool": "online"},
+			policies: []*configv1alpha1.ClusterUsageThresholdPolicy{
+				newTestUsageThresholdPolicy("b-online", poolSelector, 70),
+				newTestUsageThresholdPolicy("a-online", poolSelector, 80),
+			},
+		},
+		{
+			name:       "policy with invalid nodeSelector is skipped",
+			nodeLabels: map[string]string{"pool": "online"},
+			policies: []*configv1alpha1.ClusterUsageThresholdPolicy{
+				newTestUsageThresholdPolicy("a-invalid", &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "pool", Operator: "Unknown"},
+					},
+				}, 70),
+				newTestUsageThresholdPolicy("b-online", poolSelector, 80),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-node-1",
+					Labels:      tt.nodeLabels,
+					Annotations: map[string]string{},
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("96"),
+						corev1.ResourceMemory: resource.MustParse("512Gi"),
+					},
+				},
+			}
+			if len(tt.customUsageThresholds) > 0 {
+				data, err := json.Marshal(&extension.CustomUsageThresholds{UsageThresholds: tt.customUsageThresholds})
+				assert.NoError(t, err)
+				node.Annotations[extension.AnnotationCustomUsageThresholds] = string(data)
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{
+						Time: time.Now(),
+					},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{
+						NodeUsage: slov1alpha1.ResourceMap{
+							ResourceList: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("72"),
+								corev1.ResourceMemory: resource.MustParse("10Gi"),
+							},
+						},
+					},
+				},
+			}
+			p, _ := newPluginForTest(t, &v1beta2.LoadAwareSchedulingArgs{}, []*corev1.Node{node}, []*slov1alpha1.NodeMetric{nodeMetric}, nil)
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, policy := range tt.policies {
+				assert.NoError(t, indexer.Add(policy))
+			}
+			p.usageThresholdPolicyLister = configlisters.NewClusterUsageThresholdPolicyLister(indexer)
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
@@ -58,6 +58,7 @@ func (h *PodValidatingHandler) clusterColocationProfileValidatingPod(ctx context
 	allErrs = append(allErrs, forbidSpecialQoSClassAndPriorityClass(newPod, extension.QoSBE, extension.PriorityNone, extension.PriorityProd)...)
 	allErrs = append(allErrs, forbidSpecialQoSClassAndPriorityClass(newPod, extension.QoSLSR, extension.PriorityNone, extension.PriorityMid, extension.PriorityBatch, extension.PriorityFree)...)
 	allErrs = append(allErrs, validateResources(newPod)...)
+	allErrs = append(allErrs, validateBatchIgnoreInitContainers(newPod)...)
 	err := allErrs.ToAggregate()
 	allowed := true
 	reason := ""
@@ -83,6 +84,14 @@ func validateRequiredQoSClass(pod *corev1.Pod) field.ErrorList {
 	return field.ErrorList{field.Required(field.NewPath("labels", extension.LabelPodQoS), "must specify koordinator QoS BE with koordinator colocation resources")}
 }
 
+func validateBatchIgnoreInitContainers(pod *corev1.Pod) field.ErrorList {
+	if _, err := extension.IsBatchInitContainersIgnored(pod); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("annotations", extension.AnnotationBatchIgnoreInitContainers),
+			pod.Annotations[extension.AnnotationBatchIgnoreInitContainers], "must be a boolean")}
+	}
+	return nil
+}
+
 func validateImmutableQoSClass(oldPod, newPod *corev1.Pod) field.ErrorList {
 	oldQoSClass := extension.GetPodQoSClass(oldPod)
 	newQoSClass := extension.GetPodQoSClass(newPod)
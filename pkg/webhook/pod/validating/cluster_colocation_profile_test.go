@@ -791,6 +791,31 @@ func TestClusterColocationProfileValidatingPod(t *testing.T) {
 			},
 			wantAllowed: true,
 		},
+		{
+			name:      "validate valid batch-ignore-init-containers",
+			operation: admissionv1.Create,
+			newPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						extension.AnnotationBatchIgnoreInitContainers: "true",
+					},
+				},
+			},
+			wantAllowed: true,
+		},
+		{
+			name:      "validate invalid batch-ignore-init-containers",
+			operation: admissionv1.Create,
+			newPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						extension.AnnotationBatchIgnoreInitContainers: "yes",
+					},
+				},
+			},
+			wantAllowed: false,
+			wantReason:  `annotations.scheduling.koordinator.sh/batch-ignore-init-containers: Invalid value: "yes": must be a boolean`,
+		},
 	}
 
 	for _, tt := range tests {